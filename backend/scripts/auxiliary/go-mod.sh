@@ -4,6 +4,8 @@ CC_DIR=$PWD
 
 CC_NAMES="lawyer_cc registryoffice_cc blro_cc land_cc transfer_cc"
 
+# The shared packages under lib are vendored into each chaincode through its replace directive
+
 for CC in $CC_NAMES; do
     echo "Installing Go dependencies in "$CC
     cd $CC
@@ -1,9 +1,15 @@
 #!/bin/bash
 CHAINCODE=$1
 
+# Init arguments, e.g. '{"Args":["init","<role => {MSP, CAs} mapping as JSON>"]}' to deploy under a domain other than lran.com
+INIT_ARGS='{"Args":[]}'
+if [ -n "$2" ]; then
+    INIT_ARGS=$2
+fi
+
 #export ORDERER_CA=/opt/gopath/src/github.com/hyperledger/fabric/peer/crypto/ordererOrganizations/lran.com/orderers/orderer.lran.com/msp/tlscacerts/tlsca.lran.com-cert.pem
 
 #peer chaincode instantiate -o orderer.lran.com:7050 --tls true --cafile $ORDERER_CA -C mainchannel -n $CHAINCODE -v 1.0 -c '{"Args":[]}' >&log.txt
-peer chaincode instantiate -o orderer.lran.com:7050 --tls --cafile /opt/gopath/src/github.com/hyperledger/fabric/peer/crypto/ordererOrganizations/lran.com/orderers/orderer.lran.com/msp/tlscacerts/tlsca.lran.com-cert.pem -v 1.0 -c '{"Args":[]}' -C mainchannel -n $CHAINCODE
+peer chaincode instantiate -o orderer.lran.com:7050 --tls --cafile /opt/gopath/src/github.com/hyperledger/fabric/peer/crypto/ordererOrganizations/lran.com/orderers/orderer.lran.com/msp/tlscacerts/tlsca.lran.com-cert.pem -v 1.0 -c "$INIT_ARGS" -C mainchannel -n $CHAINCODE
 
 #cat log.txt
@@ -0,0 +1,165 @@
+// Package blro is the blro_cc chaincode, which holds the Profiles of BLROs and the cases they work on.
+package blro
+
+import (
+	"example.org/lib/cases"
+	"example.org/lib/contract"
+	"example.org/lib/envelope"
+	"example.org/lib/events"
+	"example.org/lib/identity"
+	"example.org/lib/registry"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Chaincode is the definition of the chaincode structure.
+type Chaincode struct {
+	contract.Contract
+	contract.Mapping
+	contract.Ledger
+}
+
+// New routes the transactions of blro_cc
+func New() (*contract.Chaincode, error) {
+	return contract.New("blro_cc", &Chaincode{Ledger: blros.Ledger()})
+}
+
+// Definition of the BLRO structure
+// State and District are the jurisdiction the BLRO's certificate was enrolled for.
+// Rank is junior until an admin ranks the BLRO senior, who alone can review appeals.
+type BLRO struct {
+	registry.Professional
+	Description string `json:"Description"`
+	State       string `json:"State"`
+	District    string `json:"District"`
+	Rank        string `json:"Rank"`
+}
+
+// Registry of BLROs, stored with Key => blro-ID
+var blros = registry.New(registry.Config{
+	Name:   "BLRO",
+	Prefix: "blro",
+	Role:   "blro",
+	New: func() registry.Profile {
+		return &BLRO{}
+	},
+	Fill: func(stub shim.ChaincodeStubInterface, p registry.Profile, fields []string) error {
+		// BLROs belong to the district their certificate was enrolled for, within its state
+		State, District, _, err := identity.GetTxCreatorJurisdiction(stub, "state", "district")
+		if err != nil {
+			return err
+		}
+
+		b := p.(*BLRO)
+		b.Description = fields[0]
+		b.State = State
+		b.District = District
+		b.Rank = "junior"
+		return nil
+	},
+})
+
+// Payload of createBLRO
+type CreateBLROArgs struct {
+	ID          string `json:"ID" validate:"required,key"`
+	Name        string `json:"Name" validate:"required"`
+	Description string `json:"Description"`
+}
+
+// Payload of setBLRORank
+type RankArgs struct {
+	ID   string `json:"ID" validate:"required,key"`
+	Rank string `json:"Rank" validate:"required,oneof=junior senior"`
+}
+
+// Roles allowed to call the transactions of blro_cc beyond the registry's
+var access = map[string][]string{
+	"setBLRORank": {"admin"},
+}
+
+// Init stores the role => identity mapping the chaincode authenticates against
+func (cc *Chaincode) Init(ctx *contract.TransactionContext) error {
+	return identity.InitMapping(ctx.GetStub())
+}
+
+// BeforeTransaction authenticates the caller against the roles and chaincodes allowed to call the transaction
+func (cc *Chaincode) BeforeTransaction(ctx *contract.TransactionContext) error {
+	err := blros.Authorize(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.Authorize(access)
+}
+
+// Function to create new BLRO (C of CRUD)
+func (cc *Chaincode) CreateBLRO(ctx *contract.TransactionContext, args CreateBLROArgs) error {
+	return blros.Create(ctx, args.ID, args.Name, args.Description)
+}
+
+// Function to read a BLRO (R of CRUD)
+func (cc *Chaincode) ReadBLRO(ctx *contract.TransactionContext, args contract.IDArgs) (*BLRO, error) {
+	profile, err := blros.Get(ctx.GetStub(), args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return profile.(*BLRO), nil
+}
+
+// Function to bind a BLRO to a renewed certificate (U of CRUD)
+func (cc *Chaincode) RekeyBLRO(ctx *contract.TransactionContext, args registry.RekeyArgs) error {
+	return blros.Rekey(ctx, args)
+}
+
+// Function to record the licence of a BLRO (U of CRUD)
+func (cc *Chaincode) LicenseBLRO(ctx *contract.TransactionContext, args registry.LicenceArgs) error {
+	return blros.License(ctx, args)
+}
+
+// Function to suspend, reinstate or revoke a BLRO (U of CRUD)
+func (cc *Chaincode) SetBLROStatus(ctx *contract.TransactionContext, args registry.StatusArgs) error {
+	return blros.SetStatus(ctx, args)
+}
+
+// Function to rank a BLRO junior or senior, by an admin (U of CRUD)
+func (cc *Chaincode) SetBLRORank(ctx *contract.TransactionContext, args RankArgs) error {
+	profile, err := blros.Get(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	}
+	blroToUpdate := profile.(*BLRO)
+	if blroToUpdate.Status == "revoked" {
+		return envelope.InvalidState("BLRO licence has been revoked!", "ID", args.ID)
+	}
+
+	// Update BLRO.Rank => Rank
+	blroToUpdate.Rank = args.Rank
+
+	err = blros.Put(ctx.GetStub(), blroToUpdate)
+	if err != nil {
+		return err
+	}
+	Date, err := registry.TxTime(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	return ctx.Emit(events.ProfileRanked{ProfileID: args.ID, Role: "blro", Rank: args.Rank, Date: Date})
+}
+
+// Function to add a case to a BLRO (U of CRUD)
+func (cc *Chaincode) AddCase(ctx *contract.TransactionContext, args cases.Args) error {
+	return blros.AddCase(ctx, args)
+}
+
+// Function to complete a case of a BLRO (U of CRUD)
+func (cc *Chaincode) CompleteCase(ctx *contract.TransactionContext, args cases.Args) error {
+	return blros.CompleteCase(ctx, args)
+}
+
+// Function to remove a case from a BLRO on reassignment (U of CRUD)
+func (cc *Chaincode) RemoveCase(ctx *contract.TransactionContext, args cases.Args) error {
+	return blros.RemoveCase(ctx, args)
+}
+
+// Function to apply the changes to the cases of a BLRO in one write (U of CRUD)
+func (cc *Chaincode) UpdateCases(ctx *contract.TransactionContext, args cases.UpdateArgs) error {
+	return blros.UpdateCases(ctx, args)
+}
@@ -0,0 +1,107 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package blro
+
+import (
+	"encoding/json"
+	"testing"
+
+	"example.org/lib/identitytest"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+func TestInit(t *testing.T) {
+	stub := identitytest.Chaincode(t, "blro_cc", New)
+	res := stub.MockInit("1", [][]byte{[]byte("initFunc")})
+	if res.Status != shim.OK {
+		t.Error("Init failed", res.Status, res.Message)
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	stub := identitytest.Chaincode(t, "blro_cc", New)
+	res := stub.MockInit("1", [][]byte{[]byte("initFunc")})
+	if res.Status != shim.OK {
+		t.Error("Init failed", res.Status, res.Message)
+	}
+	res = stub.MockInvoke("2", [][]byte{[]byte("invokeFunc")})
+	expected := `{"code":"INVALID_ARGUMENT","message":"Received unknown function invocation!","details":{"Function":"invokeFunc"}}`
+	if res.Status == shim.OK || res.Message != expected {
+		t.Error("unknown function expected", expected, "got", res.Status, res.Message)
+	}
+}
+
+func TestBLROStatusByAdmin(t *testing.T) {
+	stub, ids := identitytest.Instantiate(t, "blro_cc", New)
+	officer, err := ids.Enroll("BLROMSP", "B1", map[string]string{"role": "blro", "profileID": "B1", "state": "KA", "district": "BLR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := ids.Enroll("BLROMSP", "admin", map[string]string{"role": "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := stub.MockInvokeAs(officer, "1", [][]byte{[]byte("createBLRO"), []byte(`{"ID":"B1","Name":"BLRO","Description":"Bengaluru Urban"}`)})
+	if res.Status != shim.OK {
+		t.Fatal("createBLRO failed", res.Message)
+	}
+	res = stub.MockInvokeAs(admin, "2", [][]byte{[]byte("licenseBLRO"), []byte(`{"ID":"B1","LicenceNumber":"KA/BLRO/1","IssuingBody":"Revenue Department","ValidFrom":0,"ValidUntil":4102444800}`)})
+	if res.Status != shim.OK {
+		t.Fatal("licenseBLRO failed", res.Message)
+	}
+
+	// The BLRO cannot lift a suspension of their own licence
+	suspend := []byte(`{"ID":"B1","Status":"suspended"}`)
+	res = stub.MockInvokeAs(admin, "3", [][]byte{[]byte("setBLROStatus"), suspend})
+	if res.Status != shim.OK {
+		t.Fatal("setBLROStatus failed", res.Message)
+	}
+	res = stub.MockInvokeAs(officer, "4", [][]byte{[]byte("setBLROStatus"), []byte(`{"ID":"B1","Status":"active"}`)})
+	if res.Status == shim.OK {
+		t.Error("setBLROStatus by the BLRO succeeded")
+	}
+
+	read := BLRO{}
+	if err := json.Unmarshal(stub.State["blro-B1"], &read); err != nil || read.Status != "suspended" || read.District != "BLR" {
+		t.Error("unexpected BLRO", string(stub.State["blro-B1"]))
+	}
+}
+
+func TestBLRORankByAdmin(t *testing.T) {
+	stub, ids := identitytest.Instantiate(t, "blro_cc", New)
+	officer, err := ids.Enroll("BLROMSP", "B1", map[string]string{"role": "blro", "profileID": "B1", "state": "KA", "district": "BLR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := ids.Enroll("BLROMSP", "admin", map[string]string{"role": "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := stub.MockInvokeAs(officer, "1", [][]byte{[]byte("createBLRO"), []byte(`{"ID":"B1","Name":"BLRO","Description":"Bengaluru Urban"}`)})
+	if res.Status != shim.OK {
+		t.Fatal("createBLRO failed", res.Message)
+	}
+	read := BLRO{}
+	if err := json.Unmarshal(stub.State["blro-B1"], &read); err != nil || read.Rank != "junior" {
+		t.Error("new BLRO not junior", string(stub.State["blro-B1"]))
+	}
+
+	// BLROs cannot promote themselves
+	senior := []byte(`{"ID":"B1","Rank":"senior"}`)
+	if res := stub.MockInvokeAs(officer, "2", [][]byte{[]byte("setBLRORank"), senior}); res.Status == shim.OK {
+		t.Error("setBLRORank by the BLRO succeeded")
+	}
+	if res := stub.MockInvokeAs(admin, "3", [][]byte{[]byte("setBLRORank"), []byte(`{"ID":"B1","Rank":"chief"}`)}); res.Status == shim.OK {
+		t.Error("setBLRORank to an unknown Rank succeeded")
+	}
+	if res := stub.MockInvokeAs(admin, "4", [][]byte{[]byte("setBLRORank"), senior}); res.Status != shim.OK {
+		t.Fatal("setBLRORank failed", res.Message)
+	}
+	if err := json.Unmarshal(stub.State["blro-B1"], &read); err != nil || read.Rank != "senior" {
+		t.Error("BLRO not ranked senior", string(stub.State["blro-B1"]))
+	}
+}
@@ -1,19 +1,10 @@
-module example.org/chaincode
+module example.org/land_cc
 
 go 1.13
 
 require (
-	github.com/fsouza/go-dockerclient v1.6.0 // indirect
-	github.com/grpc-ecosystem/go-grpc-middleware v1.1.0 // indirect
-	github.com/hyperledger/fabric v1.4.4
-	github.com/hyperledger/fabric-amcl v0.0.0-20190902191507-f66264322317 // indirect
-	github.com/miekg/pkcs11 v1.0.3 // indirect
-	github.com/op/go-logging v0.0.0-20160315200505-970db520ece7 // indirect
-	github.com/spf13/viper v1.5.0 // indirect
-	github.com/sykesm/zap-logfmt v0.0.3 // indirect
-	go.uber.org/zap v1.13.0 // indirect
-	golang.org/x/crypto v0.0.0-20191117063200-497ca9f6d64f // indirect
-	golang.org/x/net v0.0.0-20191116160921-f9c825593386 // indirect
-	google.golang.org/grpc v1.25.1 // indirect
-	gopkg.in/yaml.v2 v2.2.5 // indirect
+	example.org/lib v0.0.0
+	github.com/hyperledger/fabric-chaincode-go v0.0.0-20200424173110-d7076418f212
 )
+
+replace example.org/lib => ../lib
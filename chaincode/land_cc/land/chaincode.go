@@ -0,0 +1,297 @@
+// Package land is the land_cc chaincode, which registers Land and records its changes of ownership.
+package land
+
+import (
+	"encoding/json"
+
+	"example.org/lib/contract"
+	"example.org/lib/envelope"
+	"example.org/lib/events"
+	"example.org/lib/identity"
+	"example.org/lib/query"
+	"example.org/lib/registry"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Chaincode is the definition of the chaincode structure.
+type Chaincode struct {
+	contract.Contract
+	contract.Mapping
+	contract.Ledger
+}
+
+// New routes the transactions of land_cc
+func New() (*contract.Chaincode, error) {
+	return contract.New("land_cc", &Chaincode{Ledger: contract.Ledger{Records: map[string]interface{}{"land-": Land{}}}})
+}
+
+// Defintion of transfer record
+type Transfer struct {
+	PreviousOwner     string `json:"PreviousOwner"`
+	CurrentOwner      string `json:"CurrentOwner"`
+	TransferDate      int    `json:"TransferDate"`
+	TransferRequestID string `json:"TransferRequest"`
+	BLRO              string `json:"BLRO"`
+}
+
+// Definition of the Land structure
+// State, District and OfficeID place the Land in the jurisdiction of a sub-registrar office.
+// SurveyNumber, Area and Geometry are those of the cadastre the Land was onboarded from, if any.
+type Land struct {
+	ID           string     `json:"ID"`
+	Address      string     `json:"Address"`
+	Owner        string     `json:"Owner"`
+	History      []Transfer `json:"History"`
+	Type         string     `json:"Type"`
+	State        string     `json:"State"`
+	District     string     `json:"District"`
+	OfficeID     string     `json:"OfficeID"`
+	SurveyNumber string     `json:"SurveyNumber,omitempty"`
+	Area         float64    `json:"Area,omitempty"`
+	Geometry     string     `json:"Geometry,omitempty"`
+}
+
+// Payload of createLand, Date being the Unix time the Land was registered,
+// Area its surveyed area in square metres and Geometry its boundary, e.g. as WKT
+type CreateLandArgs struct {
+	ID           string  `json:"ID" validate:"required,key"`
+	Address      string  `json:"Address" validate:"required"`
+	Owner        string  `json:"Owner" validate:"required,key"`
+	Date         int     `json:"Date" validate:"required,min=1"`
+	State        string  `json:"State" validate:"required"`
+	District     string  `json:"District" validate:"required"`
+	OfficeID     string  `json:"OfficeID" validate:"required,key"`
+	SurveyNumber string  `json:"SurveyNumber"`
+	Area         float64 `json:"Area" validate:"min=0"`
+	Geometry     string  `json:"Geometry"`
+}
+
+// Payload of createLands, each Land a createLand payload validated on its own
+type CreateLandsArgs struct {
+	Lands []json.RawMessage `json:"Lands" validate:"required,max=500"`
+}
+
+// Outcome of a Land of createLands, Error being the envelope it was rejected with, if it was
+type LandResult struct {
+	Row   int             `json:"Row"`
+	ID    string          `json:"ID"`
+	Error json.RawMessage `json:"Error,omitempty"`
+}
+
+// Response of createLands, with the Result of every Land in the order given
+type CreateLandsResult struct {
+	Created int          `json:"Created"`
+	Failed  int          `json:"Failed"`
+	Results []LandResult `json:"Results"`
+}
+
+// Payload of transferLand, as sent by transfer_cc when a TransferRequest is approved
+type TransferLandArgs struct {
+	ID                string `json:"ID" validate:"required,key"`
+	CurrentOwner      string `json:"CurrentOwner" validate:"required,key"`
+	TransferDate      int    `json:"TransferDate" validate:"required,min=1"`
+	TransferRequestID string `json:"TransferRequestID" validate:"required,key"`
+}
+
+// Payload of setLandJurisdiction
+type JurisdictionArgs struct {
+	ID       string `json:"ID" validate:"required,key"`
+	State    string `json:"State" validate:"required"`
+	District string `json:"District" validate:"required"`
+	OfficeID string `json:"OfficeID" validate:"required,key"`
+}
+
+// Payload of getLands
+type OwnerArgs struct {
+	Owner string `json:"Owner" validate:"required,key"`
+}
+
+// Roles allowed to call each transaction, checked before it runs
+var access = map[string][]string{
+	"createLand":          {"blro"},
+	"createLands":         {"blro"},
+	"setLandJurisdiction": {"admin"},
+}
+
+// Chaincodes allowed to call each transaction, checked before it runs.
+// Land changes hands when transfer_cc approves a TransferRequest, authorizing the approving BLRO itself.
+var callers = map[string][]string{
+	"transferLand": {"transfer_cc"},
+}
+
+// Init stores the role => identity mapping the chaincode authenticates against
+func (cc *Chaincode) Init(ctx *contract.TransactionContext) error {
+	return identity.InitMapping(ctx.GetStub())
+}
+
+// BeforeTransaction authenticates the caller against the roles and chaincodes allowed to call the transaction
+func (cc *Chaincode) BeforeTransaction(ctx *contract.TransactionContext) error {
+	err := ctx.AuthorizeCaller(callers)
+	if err != nil {
+		return err
+	}
+	return ctx.Authorize(access)
+}
+
+// Function to create new land (C of CRUD)
+func (cc *Chaincode) CreateLand(ctx *contract.TransactionContext, args CreateLandArgs) error {
+	return createLand(ctx, args)
+}
+
+// Function to create a batch of lands, e.g. onboarded from a cadastre (C of CRUD)
+// A Land that is invalid, outside the BLRO's district or already registered is reported in its
+// Result and skipped, rather than failing the batch, so that a batch sent again only reports
+// the Lands it already created as conflicts.
+func (cc *Chaincode) CreateLands(ctx *contract.TransactionContext, args CreateLandsArgs) (*CreateLandsResult, error) {
+	result := &CreateLandsResult{Results: []LandResult{}}
+	// The writes of a transaction are not read back until it commits, so IDs repeated within the batch are checked here
+	created := map[string]bool{}
+	for row, landJSON := range args.Lands {
+		landArgs := CreateLandArgs{}
+		err := contract.Decode(landJSON, &landArgs)
+		if err == nil && created[landArgs.ID] {
+			err = envelope.Conflict("Land Already Exists!", "ID", landArgs.ID)
+		} else if err == nil {
+			err = createLand(ctx, landArgs)
+		}
+
+		if err != nil && envelope.CodeOf(err) == envelope.CodeInternal {
+			return nil, err
+		} else if err != nil {
+			result.Failed++
+			result.Results = append(result.Results, LandResult{row, landArgs.ID, json.RawMessage(envelope.Wrap(err).Error())})
+			continue
+		}
+		created[landArgs.ID] = true
+		result.Created++
+		result.Results = append(result.Results, LandResult{Row: row, ID: landArgs.ID})
+	}
+	return result, nil
+}
+
+// Function to read an land (R of CRUD)
+func (cc *Chaincode) ReadLand(ctx *contract.TransactionContext, args contract.IDArgs) (*Land, error) {
+	return getLand(ctx.GetStub(), args.ID)
+}
+
+// Function to update an land's owner, by transfer_cc approving a TransferRequest (U of CRUD)
+func (cc *Chaincode) TransferLand(ctx *contract.TransactionContext, args TransferLandArgs) error {
+	landToTransfer, err := getLand(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	}
+
+	// Append Transfer History
+	initialHistory := Transfer{landToTransfer.Owner, args.CurrentOwner, args.TransferDate, args.TransferRequestID, ctx.Creator}
+	landToTransfer.History = append(landToTransfer.History, initialHistory)
+
+	// Update land.Owner => CurrentOwner
+	landToTransfer.Owner = args.CurrentOwner
+
+	return putLand(ctx.GetStub(), landToTransfer)
+}
+
+// Function to place a land registered before jurisdictions in one, by an admin (U of CRUD)
+func (cc *Chaincode) SetLandJurisdiction(ctx *contract.TransactionContext, args JurisdictionArgs) error {
+	landToUpdate, err := getLand(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	}
+
+	// Update land.State, land.District, land.OfficeID
+	landToUpdate.State = args.State
+	landToUpdate.District = args.District
+	landToUpdate.OfficeID = args.OfficeID
+
+	err = putLand(ctx.GetStub(), landToUpdate)
+	if err != nil {
+		return err
+	}
+	Date, err := registry.TxTime(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	return ctx.Emit(events.LandJurisdictionSet{LandID: args.ID, State: args.State, District: args.District, OfficeID: args.OfficeID, Date: Date})
+}
+
+// Function to list the lands of an owner, matched exactly (R of CRUD)
+func (cc *Chaincode) GetLands(ctx *contract.TransactionContext, args OwnerArgs) (json.RawMessage, error) {
+	// Owner is marshalled into the selector as a value, so it cannot change the query
+	search, err := json.Marshal(map[string]interface{}{"selector": map[string]string{"Type": "LAND", "Owner": args.Owner}})
+	if err != nil {
+		return nil, err
+	}
+
+	return query.Results(ctx.GetStub(), string(search))
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Create Land as createLand and each Land of createLands do
+func createLand(ctx *contract.TransactionContext, args CreateLandArgs) error {
+	// BLROs only register Land within their own district
+	if !identity.AuthorizeDistrict(ctx.GetStub(), args.State, args.District) {
+		return ctx.AccessDenied("State", args.State, "District", args.District)
+	}
+
+	// Check if Land exists with Key => land-ID
+	landAsBytes, err := ctx.GetStub().GetState("land-" + args.ID)
+	if err != nil {
+		return envelope.Internal("Failed to check if Land exists!")
+	} else if landAsBytes != nil {
+		return envelope.Conflict("Land Already Exists!", "ID", args.ID)
+	}
+
+	// Generate Initial Transfer Record
+	var History []Transfer
+	initialHistory := Transfer{"BLRO", args.Owner, args.Date, "Land Created By BLRO", ctx.Creator}
+	History = append(History, initialHistory)
+
+	// Generate Land from params provided
+	land := &Land{args.ID, args.Address, args.Owner, History, "LAND", args.State, args.District, args.OfficeID, args.SurveyNumber, args.Area, args.Geometry}
+
+	// Put State of newly generated Land with Key => land-ID
+	err = putLand(ctx.GetStub(), land)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Emit(events.LandCreated{
+		LandID:   args.ID,
+		Owner:    args.Owner,
+		State:    args.State,
+		District: args.District,
+		OfficeID: args.OfficeID,
+		BLRO:     ctx.Creator,
+		Date:     args.Date,
+	})
+}
+
+// Get Land with ID
+func getLand(stub shim.ChaincodeStubInterface, ID string) (*Land, error) {
+	// Get State of Land with Key => land-ID
+	landAsBytes, err := stub.GetState("land-" + ID)
+	if err != nil {
+		return nil, envelope.Internal("Failed to get state for " + ID)
+	} else if landAsBytes == nil {
+		return nil, envelope.NotFound("Land does not exist!", "ID", ID)
+	}
+
+	// Create new Land Variable
+	landToRead := &Land{}
+	err = json.Unmarshal(landAsBytes, landToRead) //unmarshal it aka JSON.parse()
+	return landToRead, err
+}
+
+// Put State of Land with Key => land-ID
+func putLand(stub shim.ChaincodeStubInterface, l *Land) error {
+	// Convert to Byte[]
+	landJSONasBytes, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	return stub.PutState("land-"+l.ID, landJSONasBytes)
+}
@@ -0,0 +1,249 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package land
+
+import (
+	"encoding/json"
+	"testing"
+
+	"example.org/lib/envelope"
+	"example.org/lib/events"
+	"example.org/lib/identity"
+	"example.org/lib/identitytest"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+func TestInit(t *testing.T) {
+	stub := identitytest.Chaincode(t, "land_cc", New)
+	res := stub.MockInit("1", [][]byte{[]byte("initFunc")})
+	if res.Status != shim.OK {
+		t.Error("Init failed", res.Status, res.Message)
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	stub := identitytest.Chaincode(t, "land_cc", New)
+	res := stub.MockInit("1", [][]byte{[]byte("initFunc")})
+	if res.Status != shim.OK {
+		t.Error("Init failed", res.Status, res.Message)
+	}
+	res = stub.MockInvoke("2", [][]byte{[]byte("invokeFunc")})
+	expected := `{"code":"INVALID_ARGUMENT","message":"Received unknown function invocation!","details":{"Function":"invokeFunc"}}`
+	if res.Status == shim.OK || res.Message != expected {
+		t.Error("unknown function expected", expected, "got", res.Status, res.Message)
+	}
+}
+
+func TestInitIdentityMapping(t *testing.T) {
+	stub := identitytest.Chaincode(t, "land_cc", New)
+	res := stub.MockInit("1", [][]byte{[]byte("init")})
+	if res.Status != shim.OK {
+		t.Fatal("Init failed", res.Status, res.Message)
+	}
+	if !identity.Authenticate(stub, "blro", "BLROMSP", "ca.blro.lran.com") {
+		t.Error("default mapping does not authenticate BLRO")
+	}
+
+	mapping := `{"citizen":{"MSP":"CitizenMSP","CAs":["ca.citizen.example.com"]},` +
+		`"lawyer":{"MSP":"LawyerMSP","CAs":["ca.lawyer.example.com"]},` +
+		`"registryofficer":{"MSP":"RegistryOfficeMSP","CAs":["ca.registryoffice.example.com"]},` +
+		`"blro":{"MSP":"BLROMSP","CAs":["ca.blro.example.com","ca2.blro.example.com"]},` +
+		`"admin":{"MSP":"BLROMSP","CAs":["ca.blro.example.com"]}}`
+	res = stub.MockInit("2", [][]byte{[]byte("init"), []byte(mapping)})
+	if res.Status != shim.OK {
+		t.Fatal("Init with mapping failed", res.Status, res.Message)
+	}
+	if identity.Authenticate(stub, "blro", "BLROMSP", "ca.blro.lran.com") || !identity.Authenticate(stub, "blro", "BLROMSP", "ca2.blro.example.com") {
+		t.Error("stored mapping not used to authenticate BLRO")
+	}
+
+	// An upgrade without a mapping keeps the stored one
+	res = stub.MockInit("3", [][]byte{[]byte("init")})
+	if res.Status != shim.OK {
+		t.Fatal("Init failed", res.Status, res.Message)
+	}
+	res = stub.MockInvoke("4", [][]byte{[]byte("readIdentityMapping")})
+	if res.Status != shim.OK {
+		t.Fatal("readIdentityMapping failed", res.Message)
+	}
+	read := map[string]identity.Identity{}
+	if err := json.Unmarshal(res.Payload, &read); err != nil {
+		t.Fatal(err)
+	}
+	if read["citizen"].CAs[0] != "ca.citizen.example.com" {
+		t.Error("upgrade replaced the stored mapping", read)
+	}
+
+	res = stub.MockInit("5", [][]byte{[]byte("init"), []byte(`{"citizen":{"MSP":"CitizenMSP","CAs":["ca.citizen.example.com"]}}`)})
+	if res.Status == shim.OK {
+		t.Error("Init accepted a mapping without every role")
+	}
+
+	res = stub.MockInvoke("6", [][]byte{[]byte("updateIdentityMapping"), []byte(mapping)})
+	if res.Status == shim.OK {
+		t.Error("updateIdentityMapping succeeded without an admin creator")
+	}
+}
+
+func TestReadLandPayload(t *testing.T) {
+	stub := identitytest.Chaincode(t, "land_cc", New)
+	stub.MockTransactionStart("1")
+	stub.PutState("land-L1", []byte(`{"ID":"L1","Owner":"C1","Type":"LAND"}`))
+	stub.MockTransactionEnd("1")
+
+	res := stub.MockInvoke("2", [][]byte{[]byte("readLand"), []byte(`{"ID":"L1"}`)})
+	if res.Status != shim.OK {
+		t.Fatal("readLand failed", res.Message)
+	}
+	read := Land{}
+	if err := json.Unmarshal(res.Payload, &read); err != nil || read.Owner != "C1" {
+		t.Error("unexpected land", string(res.Payload))
+	}
+
+	rejected := map[string]string{
+		`{"ID":"L1","Owner":"C1"}`: `{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"Owner":"is not a field of the payload"}}`,
+		`{"ID":"L 1"}`:             `{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"ID":"must not contain whitespace or control characters"}}`,
+		`{"ID":1}`:                 `{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"ID":"must be a string"}}`,
+		`L1`:                       `{"code":"INVALID_ARGUMENT","message":"Error: Argument 1 must be a JSON object!"}`,
+	}
+	for payload, expected := range rejected {
+		res = stub.MockInvoke("3", [][]byte{[]byte("readLand"), []byte(payload)})
+		if res.Status == shim.OK || res.Message != expected {
+			t.Error(payload, "expected", expected, "got", res.Message)
+		}
+	}
+}
+
+func TestGetLandsPayload(t *testing.T) {
+	stub := identitytest.Chaincode(t, "land_cc", New)
+	rejected := map[string]string{
+		`{"Owner":""}`:         `{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"Owner":"is required"}}`,
+		`{"Owner":"C1 C2"}`:    `{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"Owner":"must not contain whitespace or control characters"}}`,
+		`{"Owner":"C1\u0000"}`: `{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"Owner":"must not contain whitespace or control characters"}}`,
+	}
+	for payload, expected := range rejected {
+		res := stub.MockInvoke("1", [][]byte{[]byte("getLands"), []byte(payload)})
+		if res.Status == shim.OK || res.Message != expected {
+			t.Error(payload, "expected", expected, "got", res.Message)
+		}
+	}
+}
+
+func TestCreateLandByDistrictBLRO(t *testing.T) {
+	stub, ids := identitytest.Instantiate(t, "land_cc", New)
+	enroll := func(CA string, MSP string, Name string, attributes map[string]string) *identitytest.Identity {
+		id, err := ids.EnrollBy(CA, MSP, Name, attributes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	blroAttributes := map[string]string{"role": "blro", "profileID": "B1", "state": "KA", "district": "BLR"}
+	blro := enroll("ca.blro.lran.com", "BLROMSP", "B1", blroAttributes)
+	payload := []byte(`{"ID":"L1","Address":"1 MG Road","Owner":"C1","Date":1580000000,"State":"KA","District":"BLR","OfficeID":"SRO1"}`)
+
+	res := stub.MockInvokeAs(blro, "1", [][]byte{[]byte("createLand"), payload})
+	if res.Status != shim.OK {
+		t.Fatal("createLand failed", res.Message)
+	}
+	read := Land{}
+	if err := json.Unmarshal(stub.State["land-L1"], &read); err != nil || read.Owner != "C1" || read.History[0].BLRO != "B1" {
+		t.Error("unexpected land", string(stub.State["land-L1"]))
+	}
+
+	// Only BLROs enrolled by the CA the mapping trusts, over the Land's district
+	refused := map[string]*identitytest.Identity{
+		"citizen":                  enroll("ca.citizen.lran.com", "CitizenMSP", "C1", map[string]string{"role": "citizen", "profileID": "C1"}),
+		"BLRO of an untrusted CA":  enroll("ca.rogue.example.com", "BLROMSP", "B1", blroAttributes),
+		"BLRO of another district": enroll("ca.blro.lran.com", "BLROMSP", "B2", map[string]string{"role": "blro", "profileID": "B2", "state": "KA", "district": "MYS"}),
+	}
+	payload = []byte(`{"ID":"L2","Address":"2 MG Road","Owner":"C1","Date":1580000000,"State":"KA","District":"BLR","OfficeID":"SRO1"}`)
+	for name, id := range refused {
+		res = stub.MockInvokeAs(id, "2", [][]byte{[]byte("createLand"), payload})
+		if res.Status == shim.OK || envelope.CodeOf(envelope.Parse(res.Message)) != envelope.CodeAccessDenied {
+			t.Error("createLand by", name, "not refused", res.Message)
+		}
+	}
+	if stub.State["land-L2"] != nil {
+		t.Error("refused createLand stored the land")
+	}
+
+	// Land only changes hands through transfer_cc
+	transfer := []byte(`{"ID":"L1","CurrentOwner":"C2","TransferDate":1580000001,"TransferRequestID":"T1"}`)
+	res = stub.MockInvokeAs(blro, "3", [][]byte{[]byte("transferLand"), transfer})
+	if res.Status == shim.OK {
+		t.Error("transferLand proposed directly by a BLRO succeeded")
+	}
+}
+
+func TestCreateLands(t *testing.T) {
+	stub, ids := identitytest.Instantiate(t, "land_cc", New)
+	blro, err := ids.EnrollBy("ca.blro.lran.com", "BLROMSP", "B1", map[string]string{"role": "blro", "profileID": "B1", "state": "KA", "district": "BLR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte(`{"Lands":[
+		{"ID":"L1","Address":"1 MG Road","Owner":"C1","Date":1580000000,"State":"KA","District":"BLR","OfficeID":"SRO1","SurveyNumber":"12/3","Area":240.5,"Geometry":"POLYGON((0 0,0 1,1 1,0 0))"},
+		{"ID":"L2","Address":"2 MG Road","Owner":"C1","Date":1580000000,"State":"KA","District":"BLR","OfficeID":"SRO1","Area":-1},
+		{"ID":"L3","Address":"3 MG Road","Owner":"C2","Date":1580000000,"State":"KA","District":"MYS","OfficeID":"SRO1"},
+		{"ID":"L1","Address":"1 MG Road","Owner":"C1","Date":1580000000,"State":"KA","District":"BLR","OfficeID":"SRO1"},
+		{"ID":"L4","Address":"4 MG Road","Owner":"C2","Date":1580000000,"State":"KA","District":"BLR","OfficeID":"SRO1","Colour":"red"},
+		{"ID":"L5","Address":"5 MG Road","Owner":"C2","Date":1580000000,"State":"KA","District":"BLR","OfficeID":"SRO1"}
+	]}`)
+
+	// Rows are created or rejected on their own, the batch committing either way
+	res := stub.MockInvokeAs(blro, "1", [][]byte{[]byte("createLands"), payload})
+	if res.Status != shim.OK {
+		t.Fatal("createLands failed", res.Message)
+	}
+	var result CreateLandsResult
+	if err := json.Unmarshal(res.Payload, &result); err != nil {
+		t.Fatal(err)
+	}
+	codes := []string{"", envelope.CodeInvalidArgument, envelope.CodeAccessDenied, envelope.CodeConflict, envelope.CodeInvalidArgument, ""}
+	if result.Created != 2 || result.Failed != 4 || len(result.Results) != len(codes) {
+		t.Fatal("unexpected result", string(res.Payload))
+	}
+	for i, code := range codes {
+		r := result.Results[i]
+		if r.Row != i || (code == "" && r.Error != nil) || (code != "" && envelope.Parse(string(r.Error)).Code != code) {
+			t.Error("row", i, "expected", code, "got", r.Row, string(r.Error))
+		}
+	}
+	read := Land{}
+	if err := json.Unmarshal(stub.State["land-L1"], &read); err != nil || read.SurveyNumber != "12/3" || read.Area != 240.5 || read.History[0].BLRO != "B1" {
+		t.Error("unexpected land", string(stub.State["land-L1"]))
+	}
+	if stub.State["land-L2"] != nil || stub.State["land-L3"] != nil || stub.State["land-L4"] != nil || stub.State["land-L5"] == nil {
+		t.Error("rejected rows stored or valid rows not", stub.State)
+	}
+
+	// The Lands created are announced together, in the one event of the transaction
+	if stub.Event == nil {
+		t.Fatal("createLands set no event")
+	}
+	_, created, err := events.Parse(stub.Event.Payload)
+	if err != nil || len(created) != 2 || created[1].(*events.LandCreated).LandID != "L5" || created[1].(*events.LandCreated).BLRO != "B1" {
+		t.Error("unexpected events", string(stub.Event.Payload), err)
+	}
+
+	// Sent again, the batch only reports the Lands it created as conflicts
+	res = stub.MockInvokeAs(blro, "2", [][]byte{[]byte("createLands"), payload})
+	if err := json.Unmarshal(res.Payload, &result); err != nil || result.Created != 0 || envelope.Parse(string(result.Results[5].Error)).Code != envelope.CodeConflict {
+		t.Error("resent createLands", res.Message, string(res.Payload))
+	}
+
+	// Only BLROs create Lands, and a batch is bounded
+	citizen, err := ids.EnrollBy("ca.citizen.lran.com", "CitizenMSP", "C1", map[string]string{"role": "citizen", "profileID": "C1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := stub.MockInvokeAs(citizen, "3", [][]byte{[]byte("createLands"), payload}); res.Status == shim.OK {
+		t.Error("createLands by a citizen succeeded")
+	}
+	if res := stub.MockInvokeAs(blro, "4", [][]byte{[]byte("createLands"), []byte(`{"Lands":[]}`)}); res.Status == shim.OK {
+		t.Error("empty createLands succeeded")
+	}
+}
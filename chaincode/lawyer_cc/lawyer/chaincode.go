@@ -0,0 +1,108 @@
+// Package lawyer is the lawyer_cc chaincode, which holds the Profiles of Lawyers and the cases they work on.
+package lawyer
+
+import (
+	"example.org/lib/cases"
+	"example.org/lib/contract"
+	"example.org/lib/identity"
+	"example.org/lib/registry"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Chaincode is the definition of the chaincode structure.
+type Chaincode struct {
+	contract.Contract
+	contract.Mapping
+	contract.Ledger
+}
+
+// New routes the transactions of lawyer_cc
+func New() (*contract.Chaincode, error) {
+	return contract.New("lawyer_cc", &Chaincode{Ledger: lawyers.Ledger()})
+}
+
+// Definition of the Lawyer structure
+type Lawyer struct {
+	registry.Professional
+	CitizenID string `json:"CitizenID"`
+}
+
+// Registry of Lawyers, stored with Key => lawyer-ID
+var lawyers = registry.New(registry.Config{
+	Name:   "Lawyer",
+	Prefix: "lawyer",
+	Role:   "lawyer",
+	New: func() registry.Profile {
+		return &Lawyer{}
+	},
+	Fill: func(stub shim.ChaincodeStubInterface, p registry.Profile, fields []string) error {
+		p.(*Lawyer).CitizenID = fields[0]
+		return nil
+	},
+})
+
+// Payload of createLawyer
+type CreateLawyerArgs struct {
+	ID        string `json:"ID" validate:"required,key"`
+	Name      string `json:"Name" validate:"required"`
+	CitizenID string `json:"CitizenID" validate:"required,key"`
+}
+
+// Init stores the role => identity mapping the chaincode authenticates against
+func (cc *Chaincode) Init(ctx *contract.TransactionContext) error {
+	return identity.InitMapping(ctx.GetStub())
+}
+
+// BeforeTransaction authenticates the caller against the roles and chaincodes allowed to call the transaction
+func (cc *Chaincode) BeforeTransaction(ctx *contract.TransactionContext) error {
+	return lawyers.Authorize(ctx)
+}
+
+// Function to create new lawyer (C of CRUD)
+func (cc *Chaincode) CreateLawyer(ctx *contract.TransactionContext, args CreateLawyerArgs) error {
+	return lawyers.Create(ctx, args.ID, args.Name, args.CitizenID)
+}
+
+// Function to read a lawyer (R of CRUD)
+func (cc *Chaincode) ReadLawyer(ctx *contract.TransactionContext, args contract.IDArgs) (*Lawyer, error) {
+	profile, err := lawyers.Get(ctx.GetStub(), args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return profile.(*Lawyer), nil
+}
+
+// Function to bind a lawyer to a renewed certificate (U of CRUD)
+func (cc *Chaincode) RekeyLawyer(ctx *contract.TransactionContext, args registry.RekeyArgs) error {
+	return lawyers.Rekey(ctx, args)
+}
+
+// Function to record the licence of a lawyer (U of CRUD)
+func (cc *Chaincode) LicenseLawyer(ctx *contract.TransactionContext, args registry.LicenceArgs) error {
+	return lawyers.License(ctx, args)
+}
+
+// Function to suspend, reinstate or revoke a lawyer (U of CRUD)
+func (cc *Chaincode) SetLawyerStatus(ctx *contract.TransactionContext, args registry.StatusArgs) error {
+	return lawyers.SetStatus(ctx, args)
+}
+
+// Function to add a case to a lawyer (U of CRUD)
+func (cc *Chaincode) AddCase(ctx *contract.TransactionContext, args cases.Args) error {
+	return lawyers.AddCase(ctx, args)
+}
+
+// Function to complete a case of a lawyer (U of CRUD)
+func (cc *Chaincode) CompleteCase(ctx *contract.TransactionContext, args cases.Args) error {
+	return lawyers.CompleteCase(ctx, args)
+}
+
+// Function to remove a case from a lawyer on reassignment (U of CRUD)
+func (cc *Chaincode) RemoveCase(ctx *contract.TransactionContext, args cases.Args) error {
+	return lawyers.RemoveCase(ctx, args)
+}
+
+// Function to apply the changes to the cases of a lawyer in one write (U of CRUD)
+func (cc *Chaincode) UpdateCases(ctx *contract.TransactionContext, args cases.UpdateArgs) error {
+	return lawyers.UpdateCases(ctx, args)
+}
@@ -0,0 +1,109 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package lawyer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"example.org/lib/identity"
+	"example.org/lib/identitytest"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+func TestInit(t *testing.T) {
+	stub := identitytest.Chaincode(t, "lawyer_cc", New)
+	res := stub.MockInit("1", [][]byte{[]byte("initFunc")})
+	if res.Status != shim.OK {
+		t.Error("Init failed", res.Status, res.Message)
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	stub := identitytest.Chaincode(t, "lawyer_cc", New)
+	res := stub.MockInit("1", [][]byte{[]byte("initFunc")})
+	if res.Status != shim.OK {
+		t.Error("Init failed", res.Status, res.Message)
+	}
+	res = stub.MockInvoke("2", [][]byte{[]byte("invokeFunc")})
+	expected := `{"code":"INVALID_ARGUMENT","message":"Received unknown function invocation!","details":{"Function":"invokeFunc"}}`
+	if res.Status == shim.OK || res.Message != expected {
+		t.Error("unknown function expected", expected, "got", res.Status, res.Message)
+	}
+}
+
+func TestRekeyRequiresBoundCertificate(t *testing.T) {
+	stub := identitytest.Chaincode(t, "lawyer_cc", New)
+	res := stub.MockInit("1", [][]byte{[]byte("initFunc")})
+	if res.Status != shim.OK {
+		t.Fatal("Init failed", res.Status, res.Message)
+	}
+
+	lawyer, err := identitytest.NewFactory().Enroll("LawyerMSP", "lawyer1", map[string]string{"role": "lawyer", "profileID": "L1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.State["lawyer-L1"] = []byte(`{"ID":"L1","Name":"Lawyer","KeyHash":"bound"}`)
+
+	rekey, _ := json.Marshal(map[string]string{"ID": "L1", "Certificate": lawyer.PEM()})
+	res = stub.MockInvoke("2", [][]byte{[]byte("rekeyLawyer"), rekey})
+	if res.Status == shim.OK {
+		t.Error("rekeyLawyer succeeded without the bound certificate")
+	}
+}
+
+func TestLawyerLifecycle(t *testing.T) {
+	stub, ids := identitytest.Instantiate(t, "lawyer_cc", New)
+	enroll := func(MSP string, Name string, attributes map[string]string) *identitytest.Identity {
+		id, err := ids.Enroll(MSP, Name, attributes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	lawyer1 := enroll("LawyerMSP", "L1", map[string]string{"role": "lawyer", "profileID": "L1"})
+	lawyer2 := enroll("LawyerMSP", "L2", map[string]string{"role": "lawyer", "profileID": "L2"})
+	admin := enroll("BLROMSP", "admin", map[string]string{"role": "admin"})
+	invoke := func(id *identitytest.Identity, fcn string, payload string) (int32, string) {
+		res := stub.MockInvokeAs(id, "1", [][]byte{[]byte(fcn), []byte(payload)})
+		return res.Status, res.Message
+	}
+
+	// Profiles are created by the certificate enrolled for them, and bound to its key
+	if status, message := invoke(lawyer2, "createLawyer", `{"ID":"L1","Name":"Lawyer","CitizenID":"C9"}`); status == shim.OK {
+		t.Error("createLawyer of L1 by L2 succeeded")
+	} else if message != `{"code":"ACCESS_DENIED","message":"Access Denied!","details":{"ProfileID":"L1"}}` {
+		t.Error("unexpected refusal", message)
+	}
+	if status, message := invoke(lawyer1, "createLawyer", `{"ID":"L1","Name":"Lawyer","CitizenID":"C9"}`); status != shim.OK {
+		t.Fatal("createLawyer failed", message)
+	}
+	read := Lawyer{}
+	if err := json.Unmarshal(stub.State["lawyer-L1"], &read); err != nil || read.Status != "pending" || read.KeyHash != identity.KeyHash(lawyer1.Certificate) {
+		t.Error("unexpected lawyer", string(stub.State["lawyer-L1"]))
+	}
+
+	// Only an admin licenses
+	licence := `{"ID":"L1","LicenceNumber":"KAR/1/2020","IssuingBody":"Bar Council","ValidFrom":0,"ValidUntil":4102444800}`
+	if status, _ := invoke(lawyer1, "licenseLawyer", licence); status == shim.OK {
+		t.Error("licenseLawyer by the lawyer succeeded")
+	}
+	if status, message := invoke(admin, "licenseLawyer", licence); status != shim.OK {
+		t.Fatal("licenseLawyer failed", message)
+	}
+
+	// The bound certificate moves the Profile to a renewed one, which L2 cannot
+	renewed := enroll("LawyerMSP", "L1", map[string]string{"role": "lawyer", "profileID": "L1"})
+	rekey, _ := json.Marshal(map[string]string{"ID": "L1", "Certificate": renewed.PEM()})
+	if status, _ := invoke(lawyer2, "rekeyLawyer", string(rekey)); status == shim.OK {
+		t.Error("rekeyLawyer by L2 succeeded")
+	}
+	if status, message := invoke(lawyer1, "rekeyLawyer", string(rekey)); status != shim.OK {
+		t.Fatal("rekeyLawyer failed", message)
+	}
+	if err := json.Unmarshal(stub.State["lawyer-L1"], &read); err != nil || read.Status != "active" || read.KeyHash != identity.KeyHash(renewed.Certificate) {
+		t.Error("lawyer not rekeyed", string(stub.State["lawyer-L1"]))
+	}
+}
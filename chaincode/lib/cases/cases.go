@@ -0,0 +1,162 @@
+// Package cases tracks the TransferRequests assigned to professionals: the lists kept on their
+// Profiles, and the calls transfer_cc makes to the chaincodes holding those Profiles.
+package cases
+
+import (
+	"example.org/lib/contract"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Cases are the TransferRequests a professional works on and has worked on
+type Cases struct {
+	CompletedCases []string `json:"CompletedCases"`
+	ActiveCases    []string `json:"ActiveCases"`
+}
+
+// Trackers are the chaincodes tracking cases on Profiles. They authorize the Tx Creator of the transaction
+// moving a case on themselves, so only they can call addCase, completeCase, removeCase and updateCases.
+var Trackers = []string{"transfer_cc"}
+
+// Callers gives the chaincodes allowed to call the transactions updating Cases, for a BeforeTransaction hook
+func Callers() map[string][]string {
+	return map[string][]string{
+		"addCase":      Trackers,
+		"completeCase": Trackers,
+		"removeCase":   Trackers,
+		"updateCases":  Trackers,
+	}
+}
+
+// Args is the payload of addCase, completeCase and removeCase: the case CaseID of the professional with ID
+type Args struct {
+	ID     string `json:"ID" validate:"required,key"`
+	CaseID string `json:"CaseID" validate:"required,key"`
+}
+
+// Change of the case CaseID on a Profile: add, complete or remove it
+type Change struct {
+	Op     string `json:"Op" validate:"required,oneof=add complete remove"`
+	CaseID string `json:"CaseID" validate:"required,key"`
+}
+
+// UpdateArgs is the payload of updateCases: the Changes to the cases of the professional with ID, applied in order
+type UpdateArgs struct {
+	ID      string   `json:"ID" validate:"required,key"`
+	Changes []Change `json:"Changes" validate:"required,min=1"`
+}
+
+// Profile State
+// +++++++++++++
+
+// Add a new active case
+func (c *Cases) Add(CaseID string) {
+	c.ActiveCases = append(c.ActiveCases, CaseID)
+}
+
+// Complete a case, add to CompletedCases, remove from ActiveCases, reporting if it was active
+func (c *Cases) Complete(CaseID string) bool {
+	if !c.Remove(CaseID) {
+		return false
+	}
+	c.CompletedCases = append(c.CompletedCases, CaseID)
+	return true
+}
+
+// Remove an active case on reassignment, without completing it, reporting if it was active
+func (c *Cases) Remove(CaseID string) bool {
+	for i, v := range c.ActiveCases {
+		if v == CaseID {
+			c.ActiveCases = append(c.ActiveCases[:i], c.ActiveCases[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Apply a Change, reporting if the case it completes or removes was active
+func (c *Cases) Apply(change Change) bool {
+	switch change.Op {
+	case "add":
+		c.Add(change.CaseID)
+		return true
+	case "complete":
+		return c.Complete(change.CaseID)
+	case "remove":
+		return c.Remove(change.CaseID)
+	}
+	return false
+}
+
+// Tracking Chaincodes
+// +++++++++++++++++++
+
+// Add CaseID to the Profile of professional held in chaincode
+func Add(stub shim.ChaincodeStubInterface, chaincode string, professional string, CaseID string) error {
+	return invoke(stub, chaincode, "addCase", professional, CaseID)
+}
+
+// Complete CaseID on the Profile of professional held in chaincode
+func Complete(stub shim.ChaincodeStubInterface, chaincode string, professional string, CaseID string) error {
+	return invoke(stub, chaincode, "completeCase", professional, CaseID)
+}
+
+// Remove CaseID from the Profile of professional held in chaincode
+func Remove(stub shim.ChaincodeStubInterface, chaincode string, professional string, CaseID string) error {
+	return invoke(stub, chaincode, "removeCase", professional, CaseID)
+}
+
+// Invoke fcn of chaincode with the professional and case
+func invoke(stub shim.ChaincodeStubInterface, chaincode string, fcn string, professional string, CaseID string) error {
+	_, err := contract.Invoke(stub, chaincode, fcn, Args{professional, CaseID})
+	return err
+}
+
+// Changes collects the case changes of a transaction by chaincode and professional. A chaincode reads the
+// Profiles it is invoked on from committed state, so each Profile is written once, by Apply, with all its changes.
+type Changes struct {
+	updates []update
+}
+
+// Changes to the cases of a professional held in chaincode
+type update struct {
+	chaincode string
+	args      UpdateArgs
+}
+
+// Add CaseID to the Profile of professional held in chaincode
+func (c *Changes) Add(chaincode string, professional string, CaseID string) {
+	c.record(chaincode, professional, Change{"add", CaseID})
+}
+
+// Complete CaseID on the Profile of professional held in chaincode
+func (c *Changes) Complete(chaincode string, professional string, CaseID string) {
+	c.record(chaincode, professional, Change{"complete", CaseID})
+}
+
+// Remove CaseID from the Profile of professional held in chaincode
+func (c *Changes) Remove(chaincode string, professional string, CaseID string) {
+	c.record(chaincode, professional, Change{"remove", CaseID})
+}
+
+// Apply the changes collected, calling updateCases once per professional, in the order they were first changed
+func (c *Changes) Apply(stub shim.ChaincodeStubInterface) error {
+	for _, u := range c.updates {
+		_, err := contract.Invoke(stub, u.chaincode, "updateCases", u.args)
+		if err != nil {
+			return err
+		}
+	}
+	c.updates = nil
+	return nil
+}
+
+// Append change to those of professional held in chaincode
+func (c *Changes) record(chaincode string, professional string, change Change) {
+	for i, u := range c.updates {
+		if u.chaincode == chaincode && u.args.ID == professional {
+			c.updates[i].args.Changes = append(u.args.Changes, change)
+			return
+		}
+	}
+	c.updates = append(c.updates, update{chaincode, UpdateArgs{professional, []Change{change}}})
+}
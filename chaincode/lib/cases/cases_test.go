@@ -0,0 +1,59 @@
+package cases
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCases(t *testing.T) {
+	c := Cases{}
+	c.Add("T1")
+	c.Add("T2")
+	c.Add("T3")
+
+	if !c.Complete("T2") || c.Complete("T2") {
+		t.Error("T2 should be completed exactly once")
+	}
+	if !reflect.DeepEqual(c.ActiveCases, []string{"T1", "T3"}) || !reflect.DeepEqual(c.CompletedCases, []string{"T2"}) {
+		t.Error("unexpected cases after completing T2", c)
+	}
+
+	if !c.Remove("T1") || c.Remove("T1") {
+		t.Error("T1 should be removed exactly once")
+	}
+	if !reflect.DeepEqual(c.ActiveCases, []string{"T3"}) || !reflect.DeepEqual(c.CompletedCases, []string{"T2"}) {
+		t.Error("unexpected cases after removing T1", c)
+	}
+}
+
+func TestApply(t *testing.T) {
+	c := Cases{}
+	for _, change := range []Change{{"add", "T1"}, {"add", "T2"}, {"complete", "T1"}, {"remove", "T2"}} {
+		if !c.Apply(change) {
+			t.Error("change not applied", change)
+		}
+	}
+	if len(c.ActiveCases) != 0 || !reflect.DeepEqual(c.CompletedCases, []string{"T1"}) {
+		t.Error("unexpected cases after applying changes", c)
+	}
+	if c.Apply(Change{"complete", "T2"}) || c.Apply(Change{"reopen", "T1"}) {
+		t.Error("applied a change to a case that was not active")
+	}
+}
+
+func TestChanges(t *testing.T) {
+	c := Changes{}
+	c.Complete("blro_cc", "B1", "T1")
+	c.Complete("registryoffice_cc", "R1", "T1")
+	c.Complete("registryoffice_cc", "R1", "T2")
+	c.Add("registryoffice_cc", "R2", "T2")
+
+	expected := []update{
+		{"blro_cc", UpdateArgs{"B1", []Change{{"complete", "T1"}}}},
+		{"registryoffice_cc", UpdateArgs{"R1", []Change{{"complete", "T1"}, {"complete", "T2"}}}},
+		{"registryoffice_cc", UpdateArgs{"R2", []Change{{"add", "T2"}}}},
+	}
+	if !reflect.DeepEqual(c.updates, expected) {
+		t.Error("changes not collected per professional", c.updates)
+	}
+}
@@ -0,0 +1,76 @@
+package contract
+
+import (
+	"example.org/lib/envelope"
+	"example.org/lib/identity"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TransactionContext is passed to every transaction of a contract, giving its stub and client identity
+// as fabric-contract-api-go contexts do
+type TransactionContext struct {
+	contractapi.TransactionContext
+	// Function the transaction was called as
+	Function string
+	// MSP, certificate issuer CN and certificate subject CN of the Tx Creator, empty if they cannot be read
+	MSP     string
+	CA      string
+	Creator string
+	// Chaincode the Tx Creator proposed the transaction to, the one calling this chaincode if it is another
+	Chaincode string
+	// Events emitted so far, set as the chaincode event once the transaction succeeds
+	events []EventRecord
+}
+
+// Identify the function the transaction was called as, and its creator
+func (ctx *TransactionContext) start() {
+	stub := ctx.GetStub()
+	fcn, _ := stub.GetFunctionAndParameters()
+	ctx.Function = functionName(fcn)
+	ctx.MSP, ctx.CA, ctx.Creator, _ = identity.GetTxCreatorInfo(stub)
+	ctx.Chaincode, _ = identity.GetProposalChaincode(stub)
+}
+
+// HasRole checks the Tx Creator is a member of role, enrolled with the role attribute
+func (ctx *TransactionContext) HasRole(role string) bool {
+	return identity.HasRole(ctx.GetStub(), role, ctx.MSP, ctx.CA)
+}
+
+// Authorize lets the Tx Creator call the transaction if they hold one of the roles access gives for it.
+// Transactions access does not list authorize their callers themselves.
+func (ctx *TransactionContext) Authorize(access map[string][]string) error {
+	roles, ok := access[ctx.Function]
+	if !ok {
+		return nil
+	}
+	for _, role := range roles {
+		if ctx.HasRole(role) {
+			return nil
+		}
+	}
+	return ctx.AccessDenied()
+}
+
+// AuthorizeCaller lets only the chaincodes callers gives for the transaction call it, through InvokeChaincode.
+// Those chaincodes authorize the Tx Creator themselves, so a Tx Creator proposing the transaction to this
+// chaincode directly is refused. Transactions callers does not list can be proposed to this chaincode.
+func (ctx *TransactionContext) AuthorizeCaller(callers map[string][]string) error {
+	chaincodes, ok := callers[ctx.Function]
+	if !ok {
+		return nil
+	}
+	for _, chaincode := range chaincodes {
+		if ctx.Chaincode == chaincode {
+			return nil
+		}
+	}
+	return ctx.AccessDenied("Chaincode", ctx.Chaincode)
+}
+
+// AccessDenied rejects the Tx Creator, reporting payload or else their MSP and CA
+func (ctx *TransactionContext) AccessDenied(payload ...string) error {
+	if len(payload) == 0 {
+		payload = []string{"MSP", ctx.MSP, "CA", ctx.CA}
+	}
+	return envelope.AccessDenied(payload...)
+}
@@ -0,0 +1,363 @@
+// Package contract sets up the contracts of the chaincodes on fabric-contract-api-go, which routes each
+// transaction to the method of the same name, converts its arguments and marshals what it returns, and serves
+// the metadata of every transaction. The hooks New gives a contract authenticate callers before any
+// transaction runs, reject payloads breaking the validate tags of their fields, and set the Events a
+// transaction emitted through its context as its chaincode event, in one EventEnvelope, once it succeeds.
+//
+// Transactions taking a single struct take their arguments as one JSON object, the payload,
+// decoded into the struct and validated against the validate tags of its fields.
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"example.org/lib/envelope"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-contract-api-go/metadata"
+	"github.com/hyperledger/fabric-contract-api-go/serializer"
+	sc "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// MetadataFunction is the function fabric-contract-api-go serves the metadata of a chaincode with
+const MetadataFunction = contractapi.SystemContractName + ":GetMetadata"
+
+// Channel the chaincodes are instantiated on, and call each other on
+const Channel = "mainchannel"
+
+// Initializer is a contract setting up the ledger when the chaincode is instantiated or upgraded
+type Initializer interface {
+	Init(ctx *TransactionContext) error
+}
+
+// BeforeTransaction is a contract checking every transaction before it runs
+type BeforeTransaction interface {
+	BeforeTransaction(ctx *TransactionContext) error
+}
+
+// Contract is embedded by the contract of each chaincode, so New can give it its hooks
+type Contract struct {
+	contractapi.Contract
+}
+
+// GetIgnoredFunctions leaves the hooks of a contract out of its transactions
+func (c *Contract) GetIgnoredFunctions() []string {
+	return []string{"Init", "BeforeTransaction"}
+}
+
+// Contract embedded by a contract
+func (c *Contract) base() *Contract {
+	return c
+}
+
+// ContractInterface is a contract embedding Contract
+type ContractInterface interface {
+	contractapi.ContractInterface
+	base() *Contract
+}
+
+// Chaincode runs a contract on fabric-contract-api-go
+type Chaincode struct {
+	Name         string
+	contract     ContractInterface
+	chaincode    *contractapi.ContractChaincode
+	transactions map[string]*transaction
+}
+
+// Transaction of a contract, a method taking a *TransactionContext first
+type transaction struct {
+	Name   string
+	params []reflect.Type
+	// Set when the only parameter is a struct, taken as a JSON payload
+	payload bool
+}
+
+var contextType = reflect.TypeOf((*TransactionContext)(nil))
+
+// New runs contract as the chaincode called name. Every exported method of contract is a transaction,
+// called by the name of the method with its first letter lowered, e.g. CreateLand => createLand, taking a
+// *TransactionContext first. Parameters can be strings, integers, booleans, floats or anything decoded from JSON,
+// or a single payload struct, and methods return an error, or a value and an error.
+func New(name string, contract ContractInterface) (*Chaincode, error) {
+	cc := &Chaincode{Name: name, contract: contract, transactions: map[string]*transaction{}}
+
+	ignored := map[string]bool{}
+	for _, method := range contractMethods() {
+		ignored[method] = true
+	}
+	for _, method := range contract.base().GetIgnoredFunctions() {
+		ignored[method] = true
+	}
+
+	t := reflect.TypeOf(contract)
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		if ignored[m.Name] {
+			continue
+		} else if m.Type.NumIn() < 2 || m.Type.In(1) != contextType {
+			return nil, fmt.Errorf("%s must take a *TransactionContext first", m.Name)
+		}
+
+		tx := &transaction{Name: lowerFirst(m.Name)}
+		for j := 2; j < m.Type.NumIn(); j++ {
+			tx.params = append(tx.params, m.Type.In(j))
+		}
+
+		// A single struct is the payload of the transaction, so its validate tags must be usable
+		if len(tx.params) == 1 && payloadType(tx.params[0]) {
+			tx.payload = true
+			err := checkRules(tx.params[0], map[reflect.Type]bool{})
+			if err != nil {
+				return nil, fmt.Errorf("%s takes an invalid payload: %s", m.Name, err.Error())
+			}
+		}
+		cc.transactions[tx.Name] = tx
+	}
+
+	base := contract.base()
+	base.Name = name
+	base.TransactionContextHandler = new(TransactionContext)
+	base.BeforeTransaction = cc.beforeTransaction
+	base.AfterTransaction = cc.afterTransaction
+
+	chaincode, err := contractapi.NewChaincode(contract)
+	if err != nil {
+		return nil, err
+	}
+	chaincode.TransactionSerializer = &payloadSerializer{}
+	cc.chaincode = chaincode
+	return cc, nil
+}
+
+// Init is called when the chaincode is instantiated by the blockchain network.
+func (cc *Chaincode) Init(stub shim.ChaincodeStubInterface) sc.Response {
+	if c, ok := cc.contract.(Initializer); ok {
+		ctx := new(TransactionContext)
+		ctx.SetStub(stub)
+		ci, _ := cid.New(stub)
+		ctx.SetClientIdentity(ci)
+		ctx.start()
+
+		err := c.Init(ctx)
+		if err != nil {
+			return shim.Error(envelope.Wrap(err).Error())
+		}
+	}
+	return shim.Success(nil)
+}
+
+// Invoke is called as a result of an application request to run the chaincode.
+func (cc *Chaincode) Invoke(stub shim.ChaincodeStubInterface) sc.Response {
+	fcn, params := stub.GetFunctionAndParameters()
+
+	// An omitted payload is an empty object, so the fields it requires are reported
+	if tx, ok := cc.transactions[functionName(fcn)]; ok && tx.payload && len(params) == 0 {
+		stub = &payloadStub{stub, append(stub.GetArgs(), []byte("{}"))}
+	}
+
+	// Errors without an envelope, e.g. from the shim, are internal
+	response := cc.chaincode.Invoke(stub)
+	if response.Status != shim.OK {
+		response.Message = envelope.Parse(response.Message).Error()
+	}
+	return response
+}
+
+// Transactions of the contract, by name
+func (cc *Chaincode) Transactions() []string {
+	var names []string
+	for name := range cc.transactions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Invoke fcn of chaincode on Channel with payload, marshalled as its only argument, and give the response payload.
+// The envelope chaincode rejects the transaction with is returned with its code, naming the chaincode in its details.
+func Invoke(stub shim.ChaincodeStubInterface, chaincode string, fcn string, payload interface{}) ([]byte, error) {
+	payloadJSONasBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, envelope.Internal(err.Error())
+	}
+
+	args := [][]byte{[]byte(fcn), payloadJSONasBytes}
+	response := stub.InvokeChaincode(chaincode, args, Channel)
+	if response.Status != shim.OK {
+		inner := envelope.Parse(response.Message)
+		if inner.Detail("Chaincode") == "" {
+			inner.Details = append(inner.Details, "Chaincode", chaincode)
+		}
+		return nil, inner
+	}
+	return response.Payload, nil
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Authenticate the caller, then check the arguments of the transaction, before fabric-contract-api-go converts them.
+// Callers are authenticated before anything else is checked.
+func (cc *Chaincode) beforeTransaction(ctx *TransactionContext) error {
+	ctx.start()
+	tx, ok := cc.transactions[ctx.Function]
+	if !ok {
+		return envelope.InvalidArgument("Received unknown function invocation!", "Function", ctx.Function)
+	}
+
+	if hook, ok := cc.contract.(BeforeTransaction); ok {
+		err := hook.BeforeTransaction(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, params := ctx.GetStub().GetFunctionAndParameters()
+	return tx.check(params)
+}
+
+// Set the Events the transaction emitted as its chaincode event, once it succeeded
+func (cc *Chaincode) afterTransaction(ctx *TransactionContext) error {
+	return ctx.setEvents(cc.Name)
+}
+
+// Check the params of a transaction convert to the arguments of its method
+func (tx *transaction) check(params []string) error {
+	if tx.payload {
+		return checkPayload(params, tx.params[0])
+	}
+
+	// Check if sufficient Params passed
+	if len(params) != len(tx.params) {
+		return envelope.InvalidArgument("Incorrect number of arguments. Expecting " + strconv.Itoa(len(tx.params)))
+	}
+
+	for i, param := range params {
+		// Check if Params are non-empty
+		if len(param) <= 0 {
+			return envelope.InvalidArgument("Arguments must be a non-empty string")
+		}
+		err := convert(param, tx.params[i])
+		if err != nil {
+			return envelope.InvalidArgument(fmt.Sprintf("Error: Argument %d must be %s!", i+1, err.Error()))
+		}
+	}
+	return nil
+}
+
+// Check param converts to a value of type t, or describe what it should have been
+func convert(param string, t reflect.Type) error {
+	v := reflect.New(t).Interface()
+	switch t.Kind() {
+	case reflect.String:
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, err := strconv.ParseInt(param, 10, t.Bits()); err != nil {
+			return fmt.Errorf("an integer")
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, err := strconv.ParseUint(param, 10, t.Bits()); err != nil {
+			return fmt.Errorf("a positive integer")
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(param, t.Bits()); err != nil {
+			return fmt.Errorf("a number")
+		}
+	case reflect.Bool:
+		if _, err := strconv.ParseBool(param); err != nil {
+			return fmt.Errorf("true or false")
+		}
+	default:
+		if err := json.Unmarshal([]byte(param), v); err != nil {
+			return fmt.Errorf("a JSON %s", schemaOf(t, nil).Type)
+		}
+	}
+	return nil
+}
+
+// Names of the methods fabric-contract-api-go leaves out of the transactions of every contract
+func contractMethods() []string {
+	var names []string
+	for _, t := range []reflect.Type{
+		reflect.TypeOf((*contractapi.ContractInterface)(nil)).Elem(),
+		reflect.TypeOf((*contractapi.IgnoreContractInterface)(nil)).Elem(),
+		reflect.TypeOf((*contractapi.EvaluationContractInterface)(nil)).Elem(),
+	} {
+		for i := 0; i < t.NumMethod(); i++ {
+			names = append(names, t.Method(i).Name)
+		}
+	}
+	return names
+}
+
+// Check if values of type t are payloads, a struct or a pointer to one
+func payloadType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// Name of a method with its first letter lowered
+func lowerFirst(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+// Function a transaction was called as, without the contract fabric-contract-api-go lets callers prefix it with
+func functionName(fcn string) string {
+	if i := strings.LastIndex(fcn, ":"); i >= 0 {
+		fcn = fcn[i+1:]
+	}
+	if fcn == "" {
+		return fcn
+	}
+	return lowerFirst(fcn)
+}
+
+// payloadStub is the stub of a transaction its payload was omitted from, giving an empty object instead
+type payloadStub struct {
+	shim.ChaincodeStubInterface
+	args [][]byte
+}
+
+func (s *payloadStub) GetArgs() [][]byte {
+	return s.args
+}
+
+func (s *payloadStub) GetStringArgs() []string {
+	var args []string
+	for _, arg := range s.args {
+		args = append(args, string(arg))
+	}
+	return args
+}
+
+func (s *payloadStub) GetFunctionAndParameters() (string, []string) {
+	args := s.GetStringArgs()
+	return args[0], args[1:]
+}
+
+// payloadSerializer converts arguments and return values as the JSON serializer of fabric-contract-api-go,
+// without the schema of their metadata: payloads are validated against their validate tags before the
+// transaction runs, and records are returned as they are stored
+type payloadSerializer struct {
+	serializer.JSONSerializer
+}
+
+func (s *payloadSerializer) FromString(param string, t reflect.Type, _ *metadata.ParameterMetadata, components *metadata.ComponentMetadata) (reflect.Value, error) {
+	return s.JSONSerializer.FromString(param, t, nil, components)
+}
+
+func (s *payloadSerializer) ToString(result reflect.Value, t reflect.Type, _ *metadata.ReturnMetadata, components *metadata.ComponentMetadata) (string, error) {
+	return s.JSONSerializer.ToString(result, t, nil, components)
+}
@@ -0,0 +1,255 @@
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"example.org/lib/envelope"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/metadata"
+)
+
+type record struct {
+	ID     string   `json:"ID" validate:"required,key"`
+	Count  int      `json:"Count" validate:"min=1,max=10"`
+	Tags   []string `json:"Tags"`
+	Status string   `json:"Status,omitempty" validate:"oneof=open closed"`
+	Parts  []part   `json:"Parts,omitempty"`
+}
+
+type part struct {
+	Name string `json:"Name" validate:"required"`
+}
+
+// Contract exercising the conversions the router makes
+type testContract struct {
+	Contract
+	Mapping
+	hooked []string
+}
+
+func (c *testContract) Init(ctx *TransactionContext) error {
+	return ctx.GetStub().PutState("init", []byte(ctx.Function))
+}
+
+func (c *testContract) BeforeTransaction(ctx *TransactionContext) error {
+	c.hooked = append(c.hooked, ctx.Function)
+	return ctx.Authorize(map[string][]string{"restricted": {"admin"}})
+}
+
+func (c *testContract) CreateRecord(ctx *TransactionContext, r record) error {
+	recordJSONasBytes, _ := json.Marshal(r)
+	return ctx.GetStub().PutState(r.ID, recordJSONasBytes)
+}
+
+func (c *testContract) ReadRecord(ctx *TransactionContext, args IDArgs) (*record, error) {
+	recordAsBytes, _ := ctx.GetStub().GetState(args.ID)
+	if recordAsBytes == nil {
+		return nil, envelope.NotFound("Record does not exist!", "ID", args.ID)
+	}
+	r := &record{}
+	err := json.Unmarshal(recordAsBytes, r)
+	return r, err
+}
+
+func (c *testContract) Echo(ctx *TransactionContext, Flag bool, Word string) (string, error) {
+	if Flag {
+		return Word, nil
+	}
+	return "", nil
+}
+
+func (c *testContract) Restricted(ctx *TransactionContext) error {
+	return nil
+}
+
+// Read a record through the test_cc chaincode on Channel
+func (c *testContract) Relay(ctx *TransactionContext, args IDArgs) (json.RawMessage, error) {
+	return Invoke(ctx.GetStub(), "test_cc", "readRecord", args)
+}
+
+func (c *testContract) Fail(ctx *TransactionContext) error {
+	return errors.New("Failed to read the ledger")
+}
+
+func invoke(stub *shimtest.MockStub, args ...string) (int32, string, []byte) {
+	var byteArgs [][]byte
+	for _, a := range args {
+		byteArgs = append(byteArgs, []byte(a))
+	}
+	res := stub.MockInvoke("1", byteArgs)
+	return res.Status, res.Message, res.Payload
+}
+
+func TestRouting(t *testing.T) {
+	c := &testContract{}
+	cc, err := New("test_cc", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := shimtest.NewMockStub("test", cc)
+
+	res := stub.MockInit("1", [][]byte{[]byte("init")})
+	if res.Status != shim.OK || string(stub.State["init"]) != "init" {
+		t.Error("Init hook not run", res.Message)
+	}
+
+	if status, message, _ := invoke(stub, "createRecord", `{"ID":"R1","Count":2,"Tags":["a","b"]}`); status != shim.OK {
+		t.Fatal("createRecord failed", message)
+	}
+	status, message, payload := invoke(stub, "readRecord", `{"ID":"R1"}`)
+	if status != shim.OK {
+		t.Fatal("readRecord failed", message)
+	}
+	if string(payload) != `{"ID":"R1","Count":2,"Tags":["a","b"]}` {
+		t.Error("unexpected record", string(payload))
+	}
+
+	if _, _, payload := invoke(stub, "echo", "true", "hello"); string(payload) != "hello" {
+		t.Error("arguments not passed", string(payload))
+	}
+	if _, _, payload := invoke(stub, "Echo", "false", "hello"); string(payload) != "" {
+		t.Error("method name not routed", string(payload))
+	}
+
+	if len(c.hooked) != 4 || c.hooked[0] != "createRecord" {
+		t.Error("BeforeTransaction not run before every transaction", c.hooked)
+	}
+}
+
+func TestArguments(t *testing.T) {
+	cc, err := New("test_cc", &testContract{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := shimtest.NewMockStub("test", cc)
+
+	rejected := map[string][]string{
+		`{"code":"INVALID_ARGUMENT","message":"Incorrect number of arguments. Expecting 1"}`:                            {"createRecord", `{"ID":"R1"}`, `{"ID":"R2"}`},
+		`{"code":"INVALID_ARGUMENT","message":"Incorrect number of arguments. Expecting 2"}`:                            {"echo", "true"},
+		`{"code":"INVALID_ARGUMENT","message":"Arguments must be a non-empty string"}`:                                  {"echo", "", "hello"},
+		`{"code":"INVALID_ARGUMENT","message":"Error: Argument 1 must be true or false!"}`:                              {"echo", "yes", "hello"},
+		`{"code":"INVALID_ARGUMENT","message":"Error: Argument 1 must be a JSON object!"}`:                              {"createRecord", "R1"},
+		`{"code":"INVALID_ARGUMENT","message":"Received unknown function invocation!","details":{"Function":"helper"}}`: {"helper", "R1"},
+		`{"code":"INVALID_ARGUMENT","message":"Received unknown function invocation!","details":{"Function":"init"}}`:   {"init"},
+		`{"code":"NOT_FOUND","message":"Record does not exist!","details":{"ID":"R2"}}`:                                 {"readRecord", `{"ID":"R2"}`},
+
+		// Errors without an envelope are internal
+		`{"code":"INTERNAL","message":"Failed to read the ledger"}`: {"fail"},
+
+		// Payloads are rejected field by field
+		`{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"ID":"is required"}}`:                                                                      {"readRecord"},
+		`{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"Count":"must be an integer"}}`:                                                            {"createRecord", `{"ID":"R1","Count":"two"}`},
+		`{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"Colour":"is not a field of the payload"}}`:                                                {"createRecord", `{"ID":"R1","Colour":"red"}`},
+		`{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"ID":"must not contain whitespace or control characters","Count":"must be at most 10"}}`:   {"createRecord", `{"ID":"R 1","Count":11}`},
+		`{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"ID":"is required","Status":"must be one of open, closed","Parts[1].Name":"is required"}}`: {"createRecord", `{"Status":"done","Parts":[{"Name":"a"},{}]}`},
+	}
+	for expected, args := range rejected {
+		status, message, _ := invoke(stub, args...)
+		if status == shim.OK || message != expected {
+			t.Error(args, "expected", expected, "got", status, message)
+		}
+	}
+
+	// Transactions restricted to a role are refused before they run
+	status, message, _ := invoke(stub, "restricted")
+	if status == shim.OK || message != `{"code":"ACCESS_DENIED","message":"Access Denied!","details":{"MSP":"","CA":""}}` {
+		t.Error("restricted transaction not refused", status, message)
+	}
+	status, _, _ = invoke(stub, "updateIdentityMapping", "{}")
+	if status == shim.OK {
+		t.Error("updateIdentityMapping succeeded without an admin creator")
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	cc, err := New("test_cc", &testContract{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := shimtest.NewMockStub("test", cc)
+	callee := shimtest.NewMockStub("test_cc", cc)
+	stub.MockPeerChaincode("test_cc", callee, Channel)
+
+	if status, message, _ := invoke(callee, "createRecord", `{"ID":"R1","Count":2}`); status != shim.OK {
+		t.Fatal("createRecord failed", message)
+	}
+	status, message, payload := invoke(stub, "relay", `{"ID":"R1"}`)
+	if status != shim.OK || string(payload) != `{"ID":"R1","Count":2,"Tags":null}` {
+		t.Error("record not relayed", status, message, string(payload))
+	}
+
+	// Errors of the chaincode invoked keep their code, naming it
+	status, message, _ = invoke(stub, "relay", `{"ID":"R2"}`)
+	if status == shim.OK || message != `{"code":"NOT_FOUND","message":"Record does not exist!","details":{"ID":"R2","Chaincode":"test_cc"}}` {
+		t.Error("unexpected relayed error", status, message)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	cc, err := New("test_cc", &testContract{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := shimtest.NewMockStub("test", cc)
+
+	status, message, payload := invoke(stub, MetadataFunction)
+	if status != shim.OK {
+		t.Fatal("metadata not served", message)
+	}
+	chaincodeMetadata := metadata.ContractChaincodeMetadata{}
+	if err := json.Unmarshal(payload, &chaincodeMetadata); err != nil {
+		t.Fatal(err)
+	}
+
+	transactions := map[string]metadata.TransactionMetadata{}
+	for _, tx := range chaincodeMetadata.Contracts["test_cc"].Transactions {
+		transactions[tx.Name] = tx
+	}
+	if len(transactions) != 8 {
+		t.Error("unexpected transactions", chaincodeMetadata.Contracts["test_cc"].Transactions)
+	}
+
+	create, ok := transactions["CreateRecord"]
+	if !ok || len(create.Parameters) != 1 {
+		t.Fatal("unexpected createRecord metadata", create)
+	}
+	schema := chaincodeMetadata.Components.Schemas["record"]
+	if schema.Properties["Count"].Type[0] != "integer" || schema.Properties["Tags"].Items.Schema.Type[0] != "string" {
+		t.Error("unexpected createRecord payload", schema)
+	}
+	if _, ok := transactions["Echo"]; !ok {
+		t.Error("echo not described", transactions)
+	}
+}
+
+func TestUnsupportedContract(t *testing.T) {
+	if _, err := New("bad_cc", &struct {
+		Contract
+		*unsupported
+	}{}); err == nil {
+		t.Error("contract with an unsupported parameter accepted")
+	}
+	if _, err := New("bad_cc", &struct {
+		Contract
+		*misspelt
+	}{}); err == nil {
+		t.Error("contract with an unknown validate rule accepted")
+	}
+}
+
+type misspelt struct{}
+
+func (m *misspelt) Take(ctx *TransactionContext, r struct {
+	ID string `validate:"requried"`
+}) error {
+	return nil
+}
+
+type unsupported struct{}
+
+func (u *unsupported) Take(ctx *TransactionContext, f func()) error {
+	return nil
+}
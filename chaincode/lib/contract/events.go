@@ -0,0 +1,69 @@
+package contract
+
+import (
+	"encoding/json"
+
+	"example.org/lib/envelope"
+)
+
+// EventName is the name of the chaincode event a transaction sets when it emits Events.
+// Fabric keeps a single event per transaction, and only that of the chaincode the transaction was
+// proposed to, so the Events of a transaction are set together, by that chaincode, once it succeeds.
+const EventName = "events"
+
+// EventsVersion is the version of the EventEnvelope layout
+const EventsVersion = 1
+
+// Event is a change of state a transaction emits, its type naming it, e.g. LandCreated
+type Event interface {
+	EventType() string
+}
+
+// EventEnvelope holds the Events a transaction emitted, in the order it emitted them
+type EventEnvelope struct {
+	Version   int           `json:"Version" validate:"required"`
+	Chaincode string        `json:"Chaincode" validate:"required"`
+	Function  string        `json:"Function" validate:"required"`
+	TxID      string        `json:"TxID" validate:"required"`
+	Events    []EventRecord `json:"Events" validate:"required"`
+}
+
+// EventRecord is an Event of an EventEnvelope, its Payload being the Event as JSON
+type EventRecord struct {
+	Type    string          `json:"Type" validate:"required"`
+	Payload json.RawMessage `json:"Payload" validate:"required"`
+}
+
+// Emit e once the transaction succeeds, along with the Events emitted before it
+func (ctx *TransactionContext) Emit(e Event) error {
+	// The chaincode builds its Events itself, so an invalid one is a fault of the chaincode
+	err := Validate(e)
+	if err != nil {
+		return envelope.Internal("Event "+e.EventType()+" is invalid!", envelope.Wrap(err).Details...)
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return envelope.Internal(err.Error())
+	}
+	ctx.events = append(ctx.events, EventRecord{e.EventType(), payload})
+	return nil
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Set the Events the transaction emitted, if any, as the chaincode event of chaincode
+func (ctx *TransactionContext) setEvents(chaincode string) error {
+	if len(ctx.events) == 0 {
+		return nil
+	}
+
+	events := EventEnvelope{EventsVersion, chaincode, ctx.Function, ctx.GetStub().GetTxID(), ctx.events}
+	eventsJSONasBytes, err := json.Marshal(events)
+	if err != nil {
+		return envelope.Internal(err.Error())
+	}
+	return ctx.GetStub().SetEvent(EventName, eventsJSONasBytes)
+}
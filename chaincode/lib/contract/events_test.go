@@ -0,0 +1,91 @@
+package contract
+
+import (
+	"encoding/json"
+	"testing"
+
+	"example.org/lib/envelope"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+)
+
+type noted struct {
+	ID string `json:"ID" validate:"required"`
+}
+
+func (noted) EventType() string {
+	return "Noted"
+}
+
+// Contract emitting a Noted event per ID, before failing if asked to
+type eventContract struct {
+	Contract
+}
+
+type noteArgs struct {
+	IDs  []string `json:"IDs"`
+	Fail bool     `json:"Fail"`
+}
+
+func (c *eventContract) Note(ctx *TransactionContext, args noteArgs) error {
+	for _, ID := range args.IDs {
+		err := ctx.Emit(noted{ID})
+		if err != nil {
+			return err
+		}
+	}
+	if args.Fail {
+		return envelope.InvalidState("Failed after emitting")
+	}
+	return nil
+}
+
+func TestEvents(t *testing.T) {
+	cc, err := New("event_cc", &eventContract{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := shimtest.NewMockStub("event_cc", cc)
+	event := func() *EventEnvelope {
+		select {
+		case e := <-stub.ChaincodeEventsChannel:
+			if e.EventName != EventName {
+				t.Error("unexpected event name", e.EventName)
+			}
+			events := &EventEnvelope{}
+			if err := json.Unmarshal(e.Payload, events); err != nil {
+				t.Fatal(err)
+			}
+			return events
+		default:
+			return nil
+		}
+	}
+
+	// The Events of a transaction are set together, in the order emitted
+	invoke(stub, "note", `{"IDs":["N1","N2"]}`)
+	events := event()
+	if events == nil || events.Version != EventsVersion || events.Chaincode != "event_cc" || events.Function != "note" || events.TxID != "1" || len(events.Events) != 2 {
+		t.Fatal("unexpected events", events)
+	}
+	if events.Events[1].Type != "Noted" || string(events.Events[1].Payload) != `{"ID":"N2"}` {
+		t.Error("unexpected event", events.Events[1])
+	}
+	if event() != nil {
+		t.Error("more than one event set by a transaction")
+	}
+
+	// Transactions emitting nothing, or failing, set no event
+	for _, payload := range []string{`{"IDs":[]}`, `{"IDs":["N3"],"Fail":true}`} {
+		invoke(stub, "note", payload)
+		if e := event(); e != nil {
+			t.Error("event set by", payload, e)
+		}
+	}
+
+	// Invalid Events are faults of the chaincode
+	status, message, _ := invoke(stub, "note", `{"IDs":[""]}`)
+	if status == shim.OK || envelope.CodeOf(envelope.Parse(message)) != envelope.CodeInternal || event() != nil {
+		t.Error("invalid event emitted", message)
+	}
+}
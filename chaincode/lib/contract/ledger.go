@@ -0,0 +1,207 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"example.org/lib/envelope"
+	"example.org/lib/identity"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	sc "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Ledger gives a contract embedding it the transactions dumping its whole ledger a page at a time,
+// and importing such a dump, only by an admin, to archive a network or seed a new one with its state
+type Ledger struct {
+	// CompositeKeys are the object types of the composite keys the contract stores, dumped after its simple keys
+	CompositeKeys []string
+	// Records are the records the contract stores, e.g. Land{}, by the prefix of their simple keys, e.g. "land-",
+	// or the object type of their composite keys. Composite keys of no record are index entries valued 0x00.
+	Records map[string]interface{}
+}
+
+// Entry of the ledger, its Value as stored
+type Entry struct {
+	Key   string `json:"Key" validate:"required"`
+	Value []byte `json:"Value" validate:"required"`
+}
+
+// Payload of dumpState
+// Bookmark continues from the page before, and is empty for the first one.
+type DumpArgs struct {
+	PageSize int32  `json:"PageSize" validate:"required,min=1,max=1000"`
+	Bookmark string `json:"Bookmark,omitempty"`
+}
+
+// LedgerPage is a page of dumpState, with the Bookmark of the next page, empty after the last one.
+// A page holds the keys of one kind at a time, simple keys or composite keys of an object type,
+// so it can hold fewer than PageSize Records, even none, before the last one.
+type LedgerPage struct {
+	Records  []Entry `json:"Records"`
+	Count    int     `json:"Count"`
+	Bookmark string  `json:"Bookmark"`
+}
+
+// Payload of importState
+type ImportArgs struct {
+	Records []Entry `json:"Records" validate:"required,max=1000"`
+}
+
+// DumpState reads a page of the ledger, simple keys first then the composite keys of each object type, only by an admin.
+// Paginated queries cannot be part of a transaction that writes, so a dump is to be evaluated, not submitted. (R of CRUD)
+func (l *Ledger) DumpState(ctx *TransactionContext, args DumpArgs) (LedgerPage, error) {
+	page := LedgerPage{Records: []Entry{}}
+	if !ctx.HasRole("admin") {
+		return page, ctx.AccessDenied()
+	}
+
+	// Bookmarks are the kind of keys being dumped, 0 for simple keys or 1 + the index in CompositeKeys,
+	// then the bookmark within them
+	kind, bookmark := 0, ""
+	if args.Bookmark != "" {
+		parts := strings.SplitN(args.Bookmark, "/", 2)
+		parsed, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) != 2 || parsed < 0 || parsed > len(l.CompositeKeys) {
+			return page, InvalidArguments("Bookmark", "is not a bookmark of dumpState")
+		}
+		kind, bookmark = parsed, parts[1]
+	}
+
+	objectType := ""
+	if kind > 0 {
+		objectType = l.CompositeKeys[kind-1]
+	}
+	next, err := l.page(ctx, &page, objectType, args.PageSize, bookmark)
+	if err != nil {
+		return page, err
+	}
+
+	// The kind is done once a page comes short, then the next one starts
+	if next != "" && page.Count == int(args.PageSize) {
+		page.Bookmark = strconv.Itoa(kind) + "/" + next
+	} else if kind < len(l.CompositeKeys) {
+		page.Bookmark = strconv.Itoa(kind+1) + "/"
+	}
+	return page, nil
+}
+
+// ImportState puts Records from a dump of the ledger, only by an admin, overwriting the keys they hold
+// so an interrupted import can be run again. Every Record must be one the contract stores, under its key.
+// The identity mapping is left as the network being seeded was instantiated with. (C of CRUD)
+func (l *Ledger) ImportState(ctx *TransactionContext, args ImportArgs) error {
+	if !ctx.HasRole("admin") {
+		return ctx.AccessDenied()
+	}
+
+	for i, entry := range args.Records {
+		if entry.Key == identity.MappingKey {
+			continue
+		}
+		err := l.check(ctx.GetStub(), entry)
+		if err != nil {
+			return InvalidArguments("Records["+strconv.Itoa(i)+"]", err.Error())
+		}
+	}
+
+	for _, entry := range args.Records {
+		if entry.Key == identity.MappingKey {
+			continue
+		}
+		err := ctx.GetStub().PutState(entry.Key, entry.Value)
+		if err != nil {
+			return envelope.Internal("Failed to put state for " + entry.Key)
+		}
+	}
+	return nil
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Add to page the Records of a page of the simple keys, or of the composite keys of objectType if set,
+// giving the bookmark the query ended with
+func (l *Ledger) page(ctx *TransactionContext, page *LedgerPage, objectType string, pageSize int32, bookmark string) (string, error) {
+	var resultsIterator shim.StateQueryIteratorInterface
+	var metadata *sc.QueryResponseMetadata
+	var err error
+	if objectType == "" {
+		resultsIterator, metadata, err = ctx.GetStub().GetStateByRangeWithPagination("", "", pageSize, bookmark)
+	} else {
+		resultsIterator, metadata, err = ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(objectType, []string{}, pageSize, bookmark)
+	}
+	if err != nil {
+		return "", err
+	} else if resultsIterator == nil || metadata == nil {
+		return "", envelope.Internal("Paginated queries are not supported!")
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return "", err
+		}
+		page.Records = append(page.Records, Entry{queryResponse.Key, queryResponse.Value})
+	}
+	page.Count = len(page.Records)
+	return metadata.Bookmark, nil
+}
+
+// Check entry holds a record the contract stores under a key of its own
+func (l *Ledger) check(stub shim.ChaincodeStubInterface, entry Entry) error {
+	// Composite keys must be of the object types the contract stores, simple keys of the prefix of a record
+	name, ID := "", ""
+	if strings.HasPrefix(entry.Key, "\x00") {
+		objectType, attributes, err := stub.SplitCompositeKey(entry.Key)
+		if err != nil || !contains(l.CompositeKeys, objectType) {
+			return errors.New("is not a key of the chaincode")
+		}
+		if _, ok := l.Records[objectType]; !ok {
+			if !bytes.Equal(entry.Value, []byte{0x00}) {
+				return errors.New("is not an index entry")
+			}
+			return nil
+		}
+		name, ID = objectType, attributes[len(attributes)-1]
+	} else {
+		for prefix := range l.Records {
+			if strings.HasPrefix(entry.Key, prefix) && len(prefix) > len(name) {
+				name, ID = prefix, strings.TrimPrefix(entry.Key, prefix)
+			}
+		}
+		if name == "" {
+			return errors.New("is not a key of the chaincode")
+		}
+	}
+
+	// The Value must decode as the record, without fields it does not have, and hold the ID of its key
+	t := reflect.TypeOf(l.Records[name])
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	record := reflect.New(t)
+	decoder := json.NewDecoder(bytes.NewReader(entry.Value))
+	decoder.DisallowUnknownFields()
+	if !bytes.HasPrefix(bytes.TrimSpace(entry.Value), []byte("{")) || decoder.Decode(record.Interface()) != nil || decoder.More() {
+		return errors.New("is not a " + t.Name())
+	}
+	if field := record.Elem().FieldByName("ID"); field.IsValid() && field.Kind() == reflect.String && field.String() != ID {
+		return errors.New("is not the " + t.Name() + " of its key")
+	}
+	return nil
+}
+
+// Check if s is one of values
+func contains(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package contract_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"example.org/lib/contract"
+	"example.org/lib/identitytest"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Contract storing records under simple keys and notes under composite keys
+type ledgerContract struct {
+	contract.Contract
+	contract.Ledger
+}
+
+// Record stored under a simple key
+type record struct {
+	ID string `json:"ID"`
+}
+
+type noteArgs struct {
+	ID string `json:"ID" validate:"required,key"`
+}
+
+func (c *ledgerContract) PutRecord(ctx *contract.TransactionContext, args noteArgs) error {
+	return ctx.GetStub().PutState("record-"+args.ID, []byte(`{"ID":"`+args.ID+`"}`))
+}
+
+func (c *ledgerContract) PutNote(ctx *contract.TransactionContext, args noteArgs) error {
+	key, err := ctx.GetStub().CreateCompositeKey("note", []string{args.ID})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, []byte{0x00})
+}
+
+func newLedgerStub(t *testing.T) *identitytest.Stub {
+	cc, err := contract.New("ledger_cc", &ledgerContract{Ledger: contract.Ledger{CompositeKeys: []string{"note"}, Records: map[string]interface{}{"record-": record{}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return identitytest.NewStub("ledger_cc", cc)
+}
+
+func TestDumpAndImportState(t *testing.T) {
+	stub := newLedgerStub(t)
+	ids := identitytest.NewFactory()
+	admin, err := ids.Enroll("BLROMSP", "admin", map[string]string{"role": "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	citizen, err := ids.Enroll("CitizenMSP", "C1", map[string]string{"role": "citizen", "profileID": "C1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ID := range []string{"1", "2", "3"} {
+		stub.MockInvokeAs(admin, "put", [][]byte{[]byte("putRecord"), []byte(`{"ID":"` + ID + `"}`)})
+		stub.MockInvokeAs(admin, "put", [][]byte{[]byte("putNote"), []byte(`{"ID":"` + ID + `"}`)})
+	}
+
+	// Only an admin dumps
+	res := stub.MockInvokeAs(citizen, "dump", [][]byte{[]byte("dumpState"), []byte(`{"PageSize":2}`)})
+	if res.Status == shim.OK {
+		t.Error("dumpState by a citizen succeeded")
+	}
+
+	// Pages of two, simple keys then notes, until the Bookmark runs out
+	var dump []contract.Entry
+	var counts []int
+	bookmark := ""
+	for i := 0; i == 0 || bookmark != ""; i++ {
+		args, _ := json.Marshal(contract.DumpArgs{PageSize: 2, Bookmark: bookmark})
+		res := stub.MockInvokeAs(admin, "dump", [][]byte{[]byte("dumpState"), args})
+		if res.Status != shim.OK || i > 10 {
+			t.Fatal("dumpState failed", res.Message)
+		}
+		var page contract.LedgerPage
+		if err := json.Unmarshal(res.Payload, &page); err != nil {
+			t.Fatal(err)
+		}
+		dump = append(dump, page.Records...)
+		counts = append(counts, page.Count)
+		bookmark = page.Bookmark
+	}
+	if len(dump) != 6 || dump[0].Key != "record-1" || dump[3].Key != "\x00note\x001\x00" || !equalCounts(counts, []int{2, 1, 2, 1}) {
+		t.Error("unexpected dump", counts, dump)
+	}
+
+	// The dump seeds a new ledger as it was, records of other keys or shapes refused
+	seeded := newLedgerStub(t)
+	foreign := map[string]contract.Entry{
+		"composite key of another object type": {Key: "\x00other\x001\x00", Value: []byte{0x00}},
+		"simple key of no record":              {Key: "other-1", Value: []byte(`{"ID":"1"}`)},
+		"record with unknown fields":           {Key: "record-4", Value: []byte(`{"ID":"4","Owner":"C1"}`)},
+		"record of another ID":                 {Key: "record-4", Value: []byte(`{"ID":"5"}`)},
+		"record that is not an object":         {Key: "record-4", Value: []byte(`null`)},
+		"index entry with a value":             {Key: "\x00note\x004\x00", Value: []byte(`{"ID":"4"}`)},
+	}
+	for name, entry := range foreign {
+		args, _ := json.Marshal(contract.ImportArgs{Records: append(dump, entry)})
+		res = seeded.MockInvokeAs(admin, "import", [][]byte{[]byte("importState"), args})
+		if res.Status == shim.OK || len(seeded.State) != 0 {
+			t.Error("importState of a", name, "succeeded", res.Message)
+		}
+	}
+	args, _ := json.Marshal(contract.ImportArgs{Records: dump})
+	if res := seeded.MockInvokeAs(citizen, "import", [][]byte{[]byte("importState"), args}); res.Status == shim.OK {
+		t.Error("importState by a citizen succeeded")
+	}
+	if res := seeded.MockInvokeAs(admin, "import", [][]byte{[]byte("importState"), args}); res.Status != shim.OK {
+		t.Fatal("importState failed", res.Message)
+	}
+	if len(seeded.State) != len(stub.State) {
+		t.Error("seeded ledger differs", seeded.State)
+	}
+	for key, value := range stub.State {
+		if string(seeded.State[key]) != string(value) {
+			t.Error("seeded", key, "as", string(seeded.State[key]))
+		}
+	}
+}
+
+func equalCounts(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
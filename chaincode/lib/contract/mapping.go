@@ -0,0 +1,23 @@
+package contract
+
+import (
+	"example.org/lib/identity"
+)
+
+// Mapping gives a contract embedding it the transactions on the role => identity mapping it authenticates against
+type Mapping struct{}
+
+// UpdateIdentityMapping replaces the role => identity mapping, only by an admin of the current mapping (U of CRUD)
+// Mapping is a JSON object of role => {MSP, CAs}
+func (m *Mapping) UpdateIdentityMapping(ctx *TransactionContext, Mapping string) error {
+	if !ctx.HasRole("admin") {
+		return ctx.AccessDenied()
+	}
+
+	return identity.PutMapping(ctx.GetStub(), Mapping)
+}
+
+// ReadIdentityMapping reads the role => identity mapping (R of CRUD)
+func (m *Mapping) ReadIdentityMapping(ctx *TransactionContext) (map[string]identity.Identity, error) {
+	return identity.GetMapping(ctx.GetStub())
+}
@@ -0,0 +1,106 @@
+package contract
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// Schema is the JSON schema of a value, e.g. of the payload of an Event
+type Schema struct {
+	Type                 string             `json:"type"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+}
+
+// SchemaOf gives the schema of v, e.g. of an Event, as transaction payloads are described
+func SchemaOf(v interface{}) *Schema {
+	return schemaOf(reflect.TypeOf(v), nil)
+}
+
+// Schema of values of type t, as encoding/json marshals them.
+// seen holds the structs being described, so recursive types end as plain objects.
+func schemaOf(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	if t == rawMessageType {
+		return &Schema{Type: "object"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem(), seen)
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: t.Kind().String()}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: t.Kind().String()}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), seen)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return &Schema{Type: "object"}
+		}
+		if seen == nil {
+			seen = map[reflect.Type]bool{}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		addProperties(s, t, seen)
+		return s
+	}
+	return &Schema{Type: "object"}
+}
+
+// Add the fields of struct t to s, promoting those of embedded structs as encoding/json does,
+// along with the required fields and allowed values their validate tags give
+func addProperties(s *Schema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+			addProperties(s, ft, seen)
+			continue
+		} else if f.PkgPath != "" {
+			continue
+		}
+
+		name := f.Name
+		if tag != "" {
+			name = tag
+		}
+		property := schemaOf(f.Type, seen)
+		for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+			ruleName, arg := splitRule(rule)
+			if ruleName == "required" {
+				s.Required = append(s.Required, name)
+			} else if ruleName == "oneof" {
+				property.Enum = strings.Fields(arg)
+			}
+		}
+		s.Properties[name] = property
+	}
+}
@@ -0,0 +1,268 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"example.org/lib/envelope"
+)
+
+// Rules a payload field can be given in its validate tag, separated by commas:
+//
+//	required      the field is set to a non-empty value
+//	key           a string usable in a ledger key, without whitespace or control characters
+//	min=N, max=N  bounds of a number, or of the length of a string or array
+//	oneof=a b c   a string is one of the values listed
+//	pem           a string holding a PEM block, e.g. a certificate
+//
+// Rules other than required are only checked on fields that are set.
+var rules = map[string]bool{"required": true, "key": true, "min": true, "max": true, "oneof": true, "pem": true}
+
+// IDArgs is the payload of transactions taking nothing but the ID of a record, e.g. readLand
+type IDArgs struct {
+	ID string `json:"ID" validate:"required,key"`
+}
+
+// InvalidArguments rejects a transaction, reporting what is wrong with each field as field, message pairs
+func InvalidArguments(fields ...string) error {
+	return envelope.InvalidArgument("Invalid Arguments!", fields...)
+}
+
+// Validate checks the fields of the struct v, or v points to, against their validate tags,
+// reporting every invalid field at once
+func Validate(v interface{}) error {
+	var invalid []string
+	validateStruct(reflect.Indirect(reflect.ValueOf(v)), "", &invalid)
+	if len(invalid) > 0 {
+		return InvalidArguments(invalid...)
+	}
+	return nil
+}
+
+// Decode a JSON object into the struct v points to and validate it, as a payload is, e.g. a row of a batch
+func Decode(objectJSON []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(objectJSON))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err == nil && decoder.More() {
+		err = errors.New("trailing data")
+	}
+	if err != nil {
+		return decodeError(err)
+	}
+	return Validate(v)
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Check params hold a single payload of type t, decoding and validating it.
+// An omitted payload is an empty object, so the fields it requires are reported.
+func checkPayload(params []string, t reflect.Type) error {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	payload := "{}"
+	if len(params) > 1 {
+		return envelope.InvalidArgument("Incorrect number of arguments. Expecting 1")
+	} else if len(params) == 1 {
+		payload = params[0]
+	}
+
+	return Decode([]byte(payload), reflect.New(t).Interface())
+}
+
+// Field-level error for a payload encoding/json could not decode
+func decodeError(err error) error {
+	if typeError, ok := err.(*json.UnmarshalTypeError); ok && typeError.Field != "" {
+		return InvalidArguments(typeError.Field, "must be "+describe(typeError.Type))
+	}
+	if message := err.Error(); strings.HasPrefix(message, "json: unknown field ") {
+		field, _ := strconv.Unquote(strings.TrimPrefix(message, "json: unknown field "))
+		return InvalidArguments(field, "is not a field of the payload")
+	}
+	return envelope.InvalidArgument("Error: Argument 1 must be a JSON object!")
+}
+
+// What a value of type t looks like in JSON, for error messages
+func describe(t reflect.Type) string {
+	switch schemaOf(t, nil).Type {
+	case "string":
+		return "a string"
+	case "integer":
+		return "an integer"
+	case "number":
+		return "a number"
+	case "boolean":
+		return "true or false"
+	case "array":
+		return "a JSON array"
+	}
+	return "a JSON object"
+}
+
+// Validate the fields of struct v, appending field, message pairs to invalid.
+// Fields are named as in JSON, prefixed with the path to the struct they belong to.
+func validateStruct(v reflect.Value, prefix string, invalid *[]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		if f.Anonymous && tag == "" && fv.Kind() == reflect.Struct {
+			validateStruct(fv, prefix, invalid)
+			continue
+		} else if f.PkgPath != "" {
+			continue
+		}
+
+		name := f.Name
+		if tag != "" {
+			name = tag
+		}
+		if message := check(fv, f.Tag.Get("validate")); message != "" {
+			*invalid = append(*invalid, prefix+name, message)
+			continue
+		}
+
+		// Structs within the payload, alone or in arrays, are validated field by field
+		fv = reflect.Indirect(fv)
+		switch {
+		case fv.Kind() == reflect.Struct:
+			validateStruct(fv, prefix+name+".", invalid)
+		case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Struct:
+			for j := 0; j < fv.Len(); j++ {
+				validateStruct(fv.Index(j), prefix+name+"["+strconv.Itoa(j)+"].", invalid)
+			}
+		}
+	}
+}
+
+// Check v against the rules of a validate tag, describing the first it breaks, empty if none
+func check(v reflect.Value, tag string) string {
+	if tag == "" {
+		return ""
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg := splitRule(rule)
+		if name == "required" {
+			if empty(v) {
+				return "is required"
+			}
+			continue
+		} else if empty(v) {
+			return ""
+		}
+
+		switch name {
+		case "key":
+			if strings.IndexFunc(v.String(), func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0 {
+				return "must not contain whitespace or control characters"
+			}
+		case "min", "max":
+			bound, _ := strconv.ParseFloat(arg, 64)
+			value, length := size(v)
+			if (name == "min" && value < bound) || (name == "max" && value > bound) {
+				comparison := map[string]string{"min": "at least", "max": "at most"}[name]
+				if length {
+					return "must have a length of " + comparison + " " + arg
+				}
+				return "must be " + comparison + " " + arg
+			}
+		case "oneof":
+			values := strings.Fields(arg)
+			found := false
+			for _, value := range values {
+				found = found || v.String() == value
+			}
+			if !found {
+				return "must be one of " + strings.Join(values, ", ")
+			}
+		case "pem":
+			if block, _ := pem.Decode([]byte(v.String())); block == nil {
+				return "must be PEM encoded"
+			}
+		}
+	}
+	return ""
+}
+
+// Check the validate tags of struct t, and of the structs it holds, only use known rules with valid arguments
+func checkRules(t reflect.Type, seen map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return nil
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if tag := f.Tag.Get("validate"); tag != "" {
+			for _, rule := range strings.Split(tag, ",") {
+				name, arg := splitRule(rule)
+				if !rules[name] {
+					return fmt.Errorf("%s.%s has an unknown validate rule %q", t, f.Name, rule)
+				} else if _, err := strconv.ParseFloat(arg, 64); (name == "min" || name == "max") && err != nil {
+					return fmt.Errorf("%s.%s has a %s rule without a number", t, f.Name, name)
+				}
+			}
+		}
+
+		err := checkRules(f.Type, seen)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Name and argument of a rule, e.g. min=1 => min, 1
+func splitRule(rule string) (string, string) {
+	parts := strings.SplitN(strings.TrimSpace(rule), "=", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// Check if v is unset: zero, or an empty string, array or map
+func empty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// Value min and max compare to: a number, or the length of a string or array, reporting which
+func size(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false
+	case reflect.String:
+		return float64(len([]rune(v.String()))), true
+	}
+	return float64(v.Len()), true
+}
@@ -0,0 +1,171 @@
+// Package envelope builds the JSON error envelope the chaincodes reject transactions with,
+// {"code": code, "message": message, "details": {key: value, ...}}, which the middleware parses.
+// Codes are stable, so callers act on them rather than on messages.
+package envelope
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Codes of the errors transactions are rejected with
+const (
+	// CodeNotFound rejects a transaction on a record that does not exist
+	CodeNotFound = "NOT_FOUND"
+	// CodeAccessDenied rejects a caller failing authentication or authorization
+	CodeAccessDenied = "ACCESS_DENIED"
+	// CodeInvalidArgument rejects arguments that are missing, malformed or out of range
+	CodeInvalidArgument = "INVALID_ARGUMENT"
+	// CodeConflict rejects the creation of a record that already exists, or a change it already has
+	CodeConflict = "CONFLICT"
+	// CodeInvalidState rejects a transaction the record it acts on is not in a state to take
+	CodeInvalidState = "INVALID_STATE"
+	// CodeInternal rejects a transaction failing on the ledger or on data it cannot read
+	CodeInternal = "INTERNAL"
+)
+
+// Error is the envelope of an error, with Details given as key, value pairs kept in order
+type Error struct {
+	Code    string
+	Message string
+	Details []string
+}
+
+// Error is the envelope as JSON
+func (e *Error) Error() string {
+	var buffer bytes.Buffer
+	buffer.WriteString("{\"code\":")
+	buffer.Write(quote(e.Code))
+	buffer.WriteString(",\"message\":")
+	buffer.Write(quote(e.Message))
+
+	if len(e.Details) > 1 {
+		buffer.WriteString(",\"details\":{")
+		for i := 0; i+1 < len(e.Details); i += 2 {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+			buffer.Write(quote(e.Details[i]))
+			buffer.WriteString(":")
+			buffer.Write(quote(e.Details[i+1]))
+		}
+		buffer.WriteString("}")
+	}
+	buffer.WriteString("}")
+	return buffer.String()
+}
+
+// Detail gives the value of the detail key, empty if missing
+func (e *Error) Detail(key string) string {
+	for i := 0; i+1 < len(e.Details); i += 2 {
+		if e.Details[i] == key {
+			return e.Details[i+1]
+		}
+	}
+	return ""
+}
+
+// New is the envelope of message with code, for helpers returning to a chaincode function
+func New(code string, message string, details ...string) error {
+	return &Error{code, message, details}
+}
+
+// NotFound rejects a transaction on a record that does not exist
+func NotFound(message string, details ...string) error {
+	return New(CodeNotFound, message, details...)
+}
+
+// AccessDenied rejects the transaction of a caller failing authentication or authorization
+func AccessDenied(details ...string) error {
+	return New(CodeAccessDenied, "Access Denied!", details...)
+}
+
+// InvalidArgument rejects the arguments of a transaction
+func InvalidArgument(message string, details ...string) error {
+	return New(CodeInvalidArgument, message, details...)
+}
+
+// Conflict rejects a transaction creating a record that already exists, or making a change it already has
+func Conflict(message string, details ...string) error {
+	return New(CodeConflict, message, details...)
+}
+
+// InvalidState rejects a transaction the record it acts on is not in a state to take
+func InvalidState(message string, details ...string) error {
+	return New(CodeInvalidState, message, details...)
+}
+
+// Internal rejects a transaction failing on the ledger or on data it cannot read
+func Internal(message string, details ...string) error {
+	return New(CodeInternal, message, details...)
+}
+
+// Wrap gives the envelope of err: err itself if it is one, the envelope it holds as its message,
+// or else an internal error with its message
+func Wrap(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return Parse(err.Error())
+}
+
+// Parse the envelope a chaincode rejected a transaction with, e.g. the message of an InvokeChaincode response.
+// Messages that are not envelopes, such as those of the peer itself, are internal errors.
+func Parse(message string) *Error {
+	var parsed struct {
+		Code    string          `json:"code"`
+		Message string          `json:"message"`
+		Details json.RawMessage `json:"details"`
+	}
+	err := json.Unmarshal([]byte(message), &parsed)
+	if err != nil || parsed.Code == "" {
+		return &Error{Code: CodeInternal, Message: message}
+	}
+
+	details, err := pairs(parsed.Details)
+	if err != nil {
+		return &Error{Code: CodeInternal, Message: message}
+	}
+	return &Error{parsed.Code, parsed.Message, details}
+}
+
+// CodeOf gives the code of the envelope of err
+func CodeOf(err error) string {
+	return Wrap(err).Code
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Key, value pairs of a JSON object of strings, in the order they are written
+func pairs(object json.RawMessage) ([]string, error) {
+	if len(object) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(object))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	var details []string
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value string
+		err = decoder.Decode(&value)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, key.(string), value)
+	}
+	return details, nil
+}
+
+// JSON string of s, escaped so callers can pass IDs and messages as they come
+func quote(s string) []byte {
+	quoted, _ := json.Marshal(s)
+	return quoted
+}
@@ -0,0 +1,59 @@
+package envelope
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	if e := NotFound("Lawyer does not exist!").Error(); e != `{"code":"NOT_FOUND","message":"Lawyer does not exist!"}` {
+		t.Error("unexpected envelope", e)
+	}
+
+	// Details keep the order they are given in
+	e := AccessDenied("MSP", "LawyerMSP", "CA", "ca.lawyer.lran.com").Error()
+	if e != `{"code":"ACCESS_DENIED","message":"Access Denied!","details":{"MSP":"LawyerMSP","CA":"ca.lawyer.lran.com"}}` {
+		t.Error("unexpected envelope", e)
+	}
+
+	// IDs passed by callers cannot break out of the envelope
+	var parsed struct {
+		Code    string
+		Details map[string]string
+	}
+	if err := json.Unmarshal([]byte(AccessDenied("ProfileID", `L1","MSP":"BLROMSP`).Error()), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Code != CodeAccessDenied || len(parsed.Details) != 1 || parsed.Details["ProfileID"] != `L1","MSP":"BLROMSP` {
+		t.Error("details not escaped", parsed.Details)
+	}
+}
+
+func TestParse(t *testing.T) {
+	// An envelope survives the round trip through a response message, details in order
+	e := Parse(InvalidArgument("Invalid Arguments!", "ID", "is required", "Date", "must be at least 1").Error())
+	if e.Code != CodeInvalidArgument || e.Message != "Invalid Arguments!" || len(e.Details) != 4 || e.Details[2] != "Date" {
+		t.Error("envelope not parsed", e)
+	}
+	if e.Detail("Date") != "must be at least 1" || e.Detail("Owner") != "" {
+		t.Error("unexpected details", e.Details)
+	}
+
+	// Messages of the peer itself are internal errors
+	for _, message := range []string{"chaincode registryoffice_cc not found", `{"Error":"Access Denied!"}`, `{"code":"CONFLICT","details":[1]}`} {
+		if e := Parse(message); e.Code != CodeInternal || e.Message != message {
+			t.Error("unexpected envelope for", message, e)
+		}
+	}
+
+	if code := CodeOf(Conflict("Land Already Exists!")); code != CodeConflict {
+		t.Error("unexpected code", code)
+	}
+	if code := CodeOf(errors.New(InvalidState("Transfer Request is already complete!").Error())); code != CodeInvalidState {
+		t.Error("code lost through the message", code)
+	}
+	if code := CodeOf(errors.New("Failed to marshal")); code != CodeInternal {
+		t.Error("unexpected code", code)
+	}
+}
@@ -0,0 +1,331 @@
+// Package events defines the Events the chaincodes emit as state changes, so that listeners follow
+// Land, TransferRequests, their threads and appeals, and the Profiles of professionals without polling.
+// A transaction sets every Event it emitted as a single chaincode event named contract.EventName,
+// holding a contract.EventEnvelope, e.g.
+//
+//	{"Version":1,"Chaincode":"transfer_cc","Function":"approveTransferRequest","TxID":"...","Events":[
+//		{"Type":"RequestApproved","Payload":{"TransferRequestID":"T1",...}},
+//		{"Type":"CaseCompleted","Payload":{"TransferRequestID":"T1","Chaincode":"blro_cc",...}},
+//		{"Type":"LandTransferred","Payload":{"LandID":"L1",...}}]}
+//
+// Changes transfer_cc makes through other chaincodes, to Land and cases, are emitted by transfer_cc,
+// as Fabric drops the events of chaincodes called within a transaction.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"example.org/lib/contract"
+)
+
+// LandCreated is emitted by land_cc when a BLRO registers Land
+type LandCreated struct {
+	LandID   string `json:"LandID" validate:"required"`
+	Owner    string `json:"Owner" validate:"required"`
+	State    string `json:"State" validate:"required"`
+	District string `json:"District" validate:"required"`
+	OfficeID string `json:"OfficeID" validate:"required"`
+	BLRO     string `json:"BLRO"`
+	Date     int    `json:"Date" validate:"required"`
+}
+
+// LandTransferred is emitted by transfer_cc when the approval of a TransferRequest moves Land to its new Owner
+type LandTransferred struct {
+	LandID            string `json:"LandID" validate:"required"`
+	PreviousOwner     string `json:"PreviousOwner" validate:"required"`
+	CurrentOwner      string `json:"CurrentOwner" validate:"required"`
+	TransferRequestID string `json:"TransferRequestID" validate:"required"`
+	Date              int    `json:"Date" validate:"required"`
+}
+
+// LandJurisdictionSet is emitted by land_cc when an admin places Land in the jurisdiction of another office
+type LandJurisdictionSet struct {
+	LandID   string `json:"LandID" validate:"required"`
+	State    string `json:"State" validate:"required"`
+	District string `json:"District" validate:"required"`
+	OfficeID string `json:"OfficeID" validate:"required"`
+	Date     int    `json:"Date" validate:"required"`
+}
+
+// TransferRequestCreated is emitted by transfer_cc when a citizen requests the transfer of their Land,
+// at the first Stage of its Workflow, assigned to Assignee
+type TransferRequestCreated struct {
+	TransferRequestID string `json:"TransferRequestID" validate:"required"`
+	LandID            string `json:"LandID" validate:"required"`
+	From              string `json:"From" validate:"required"`
+	To                string `json:"To" validate:"required"`
+	Requester         string `json:"Requester"`
+	Workflow          string `json:"Workflow" validate:"required"`
+	Stage             string `json:"Stage" validate:"required"`
+	Assignee          string `json:"Assignee" validate:"required"`
+	Date              int    `json:"Date" validate:"required"`
+}
+
+// StageChanged is emitted by transfer_cc when a TransferRequest moves from PreviousStage to Stage, or its
+// Status changes within a Stage: declined at its last Stage, reopened on Appeal, or closed when a decline is upheld
+type StageChanged struct {
+	TransferRequestID string `json:"TransferRequestID" validate:"required"`
+	PreviousStage     string `json:"PreviousStage" validate:"required"`
+	Stage             string `json:"Stage" validate:"required"`
+	Status            string `json:"Status" validate:"required,oneof=open declined closed"`
+	Assignee          string `json:"Assignee"`
+	Reason            string `json:"Reason,omitempty"`
+	Date              int    `json:"Date" validate:"required"`
+}
+
+// RequestApproved is emitted by transfer_cc when the last Stage of a TransferRequest approves it
+type RequestApproved struct {
+	TransferRequestID string `json:"TransferRequestID" validate:"required"`
+	LandID            string `json:"LandID" validate:"required"`
+	From              string `json:"From"`
+	To                string `json:"To" validate:"required"`
+	Approver          string `json:"Approver"`
+	Date              int    `json:"Date" validate:"required"`
+}
+
+// CaseAssigned is emitted by transfer_cc when a TransferRequest is added to the cases of the Professional
+// of a Stage, held in Chaincode
+type CaseAssigned struct {
+	TransferRequestID string `json:"TransferRequestID" validate:"required"`
+	Chaincode         string `json:"Chaincode" validate:"required"`
+	Professional      string `json:"Professional" validate:"required"`
+	Stage             string `json:"Stage" validate:"required"`
+	Date              int    `json:"Date" validate:"required"`
+}
+
+// CaseCompleted is emitted by transfer_cc when the case of a Professional is completed, with its TransferRequest
+type CaseCompleted struct {
+	TransferRequestID string `json:"TransferRequestID" validate:"required"`
+	Chaincode         string `json:"Chaincode" validate:"required"`
+	Professional      string `json:"Professional" validate:"required"`
+	Stage             string `json:"Stage" validate:"required"`
+	Date              int    `json:"Date" validate:"required"`
+}
+
+// CaseRemoved is emitted by transfer_cc when the case of a Professional is removed, as they are reassigned
+type CaseRemoved struct {
+	TransferRequestID string `json:"TransferRequestID" validate:"required"`
+	Chaincode         string `json:"Chaincode" validate:"required"`
+	Professional      string `json:"Professional" validate:"required"`
+	Stage             string `json:"Stage" validate:"required"`
+	Date              int    `json:"Date" validate:"required"`
+}
+
+// AppealFiled is emitted by transfer_cc when the Requester of a declined TransferRequest appeals the decline
+type AppealFiled struct {
+	AppealID          string `json:"AppealID" validate:"required"`
+	TransferRequestID string `json:"TransferRequestID" validate:"required"`
+	Appellant         string `json:"Appellant" validate:"required"`
+	Date              int    `json:"Date" validate:"required"`
+}
+
+// AppealReviewerAssigned is emitted by transfer_cc when a BLRO assigns a senior BLRO to review an Appeal
+type AppealReviewerAssigned struct {
+	AppealID          string `json:"AppealID" validate:"required"`
+	TransferRequestID string `json:"TransferRequestID" validate:"required"`
+	Reviewer          string `json:"Reviewer" validate:"required"`
+	AssignedBy        string `json:"AssignedBy"`
+	Date              int    `json:"Date" validate:"required"`
+}
+
+// AppealDecided is emitted by transfer_cc when the Reviewer upholds or overturns an Appeal,
+// or it is withdrawn as another TransferRequest transfers the Land
+type AppealDecided struct {
+	AppealID          string `json:"AppealID" validate:"required"`
+	TransferRequestID string `json:"TransferRequestID" validate:"required"`
+	Outcome           string `json:"Outcome" validate:"required,oneof=upheld overturned withdrawn"`
+	Reviewer          string `json:"Reviewer"`
+	Date              int    `json:"Date" validate:"required"`
+}
+
+// CommentPosted is emitted by transfer_cc when a party posts a Comment on a TransferRequest, replying to
+// the Comment ReplyTo if set, or asks for a Clarification blocking it
+type CommentPosted struct {
+	TransferRequestID string `json:"TransferRequestID" validate:"required"`
+	CommentID         string `json:"CommentID" validate:"required"`
+	Author            string `json:"Author" validate:"required"`
+	AuthorMSP         string `json:"AuthorMSP"`
+	ReplyTo           string `json:"ReplyTo,omitempty"`
+	Clarification     bool   `json:"Clarification"`
+	Document          string `json:"Document,omitempty"`
+	Date              int    `json:"Date" validate:"required"`
+}
+
+// ClarificationResolved is emitted by transfer_cc when the Author of a clarification resolves it
+type ClarificationResolved struct {
+	TransferRequestID string `json:"TransferRequestID" validate:"required"`
+	CommentID         string `json:"CommentID" validate:"required"`
+	Author            string `json:"Author" validate:"required"`
+	Date              int    `json:"Date" validate:"required"`
+}
+
+// ArtefactAdded is emitted by transfer_cc when the Assignee of a Stage attaches an artefact it requires
+type ArtefactAdded struct {
+	TransferRequestID string `json:"TransferRequestID" validate:"required"`
+	Stage             string `json:"Stage" validate:"required"`
+	Name              string `json:"Name" validate:"required"`
+	Reference         string `json:"Reference" validate:"required"`
+	Date              int    `json:"Date" validate:"required"`
+}
+
+// WorkflowCreated is emitted by transfer_cc when a BLRO creates a Workflow, with the names of its Stages
+type WorkflowCreated struct {
+	WorkflowID string   `json:"WorkflowID" validate:"required"`
+	Stages     []string `json:"Stages" validate:"required"`
+	Creator    string   `json:"Creator"`
+	Date       int      `json:"Date" validate:"required"`
+}
+
+// ProfileCreated is emitted by lawyer_cc, registryoffice_cc and blro_cc when a professional of Role creates
+// their Profile, pending until licensed
+type ProfileCreated struct {
+	ProfileID string `json:"ProfileID" validate:"required"`
+	Role      string `json:"Role" validate:"required"`
+	Name      string `json:"Name" validate:"required"`
+	Date      int    `json:"Date" validate:"required"`
+}
+
+// ProfileLicensed is emitted by the chaincode holding a Profile when an admin records or renews its licence
+type ProfileLicensed struct {
+	ProfileID     string `json:"ProfileID" validate:"required"`
+	Role          string `json:"Role" validate:"required"`
+	LicenceNumber string `json:"LicenceNumber" validate:"required"`
+	IssuingBody   string `json:"IssuingBody" validate:"required"`
+	ValidFrom     int    `json:"ValidFrom"`
+	ValidUntil    int    `json:"ValidUntil" validate:"required"`
+	Status        string `json:"Status" validate:"required"`
+	Date          int    `json:"Date" validate:"required"`
+}
+
+// ProfileStatusChanged is emitted by the chaincode holding a Profile when an admin suspends, reinstates or revokes it
+type ProfileStatusChanged struct {
+	ProfileID      string `json:"ProfileID" validate:"required"`
+	Role           string `json:"Role" validate:"required"`
+	PreviousStatus string `json:"PreviousStatus"`
+	Status         string `json:"Status" validate:"required,oneof=active suspended revoked"`
+	Date           int    `json:"Date" validate:"required"`
+}
+
+// ProfileRekeyed is emitted by the chaincode holding a Profile when it is bound to the certificate of KeyHash
+type ProfileRekeyed struct {
+	ProfileID string `json:"ProfileID" validate:"required"`
+	Role      string `json:"Role" validate:"required"`
+	KeyHash   string `json:"KeyHash" validate:"required"`
+	Date      int    `json:"Date" validate:"required"`
+}
+
+// ProfileRanked is emitted by blro_cc when an admin ranks a BLRO junior or senior
+type ProfileRanked struct {
+	ProfileID string `json:"ProfileID" validate:"required"`
+	Role      string `json:"Role" validate:"required"`
+	Rank      string `json:"Rank" validate:"required,oneof=junior senior"`
+	Date      int    `json:"Date" validate:"required"`
+}
+
+// EventType of each Event, as the Type of its EventRecord
+func (LandCreated) EventType() string            { return "LandCreated" }
+func (LandTransferred) EventType() string        { return "LandTransferred" }
+func (LandJurisdictionSet) EventType() string    { return "LandJurisdictionSet" }
+func (TransferRequestCreated) EventType() string { return "TransferRequestCreated" }
+func (StageChanged) EventType() string           { return "StageChanged" }
+func (RequestApproved) EventType() string        { return "RequestApproved" }
+func (CaseAssigned) EventType() string           { return "CaseAssigned" }
+func (CaseCompleted) EventType() string          { return "CaseCompleted" }
+func (CaseRemoved) EventType() string            { return "CaseRemoved" }
+func (AppealFiled) EventType() string            { return "AppealFiled" }
+func (AppealReviewerAssigned) EventType() string { return "AppealReviewerAssigned" }
+func (AppealDecided) EventType() string          { return "AppealDecided" }
+func (CommentPosted) EventType() string          { return "CommentPosted" }
+func (ClarificationResolved) EventType() string  { return "ClarificationResolved" }
+func (ArtefactAdded) EventType() string          { return "ArtefactAdded" }
+func (WorkflowCreated) EventType() string        { return "WorkflowCreated" }
+func (ProfileCreated) EventType() string         { return "ProfileCreated" }
+func (ProfileLicensed) EventType() string        { return "ProfileLicensed" }
+func (ProfileStatusChanged) EventType() string   { return "ProfileStatusChanged" }
+func (ProfileRekeyed) EventType() string         { return "ProfileRekeyed" }
+func (ProfileRanked) EventType() string          { return "ProfileRanked" }
+
+// Types of Events, each the zero value of its payload
+var Types = []contract.Event{
+	LandCreated{},
+	LandTransferred{},
+	LandJurisdictionSet{},
+	TransferRequestCreated{},
+	StageChanged{},
+	RequestApproved{},
+	CaseAssigned{},
+	CaseCompleted{},
+	CaseRemoved{},
+	AppealFiled{},
+	AppealReviewerAssigned{},
+	AppealDecided{},
+	CommentPosted{},
+	ClarificationResolved{},
+	ArtefactAdded{},
+	WorkflowCreated{},
+	ProfileCreated{},
+	ProfileLicensed{},
+	ProfileStatusChanged{},
+	ProfileRekeyed{},
+	ProfileRanked{},
+}
+
+// Schema of the EventEnvelope, and of the Payload of each type of Event
+type Schema struct {
+	Version  int                         `json:"version"`
+	Envelope *contract.Schema            `json:"envelope"`
+	Payloads map[string]*contract.Schema `json:"payloads"`
+}
+
+// Schemas describes the chaincode event of a transaction and the Events it holds, for listeners to check them against
+func Schemas() Schema {
+	s := Schema{contract.EventsVersion, contract.SchemaOf(contract.EventEnvelope{}), map[string]*contract.Schema{}}
+	for _, e := range Types {
+		s.Payloads[e.EventType()] = contract.SchemaOf(e)
+	}
+	return s
+}
+
+// Parse the payload of a chaincode event into its envelope, and its Events into their types as Decode does.
+// Events of types this package does not know, emitted by newer chaincodes, are left out.
+func Parse(payload []byte) (*contract.EventEnvelope, []contract.Event, error) {
+	events := &contract.EventEnvelope{}
+	err := json.Unmarshal(payload, events) //unmarshal it aka JSON.parse()
+	if err != nil {
+		return nil, nil, err
+	} else if events.Version != contract.EventsVersion {
+		return nil, nil, fmt.Errorf("events version %d is not supported, expecting %d", events.Version, contract.EventsVersion)
+	}
+
+	var typed []contract.Event
+	for _, r := range events.Events {
+		e, err := Decode(r)
+		if err != nil {
+			return nil, nil, err
+		} else if e != nil {
+			typed = append(typed, e)
+		}
+	}
+	return events, typed, nil
+}
+
+// Decode the Payload of r into a pointer to the Event of its type, e.g. *LandCreated, nil if the type is unknown
+func Decode(r contract.EventRecord) (contract.Event, error) {
+	var e contract.Event
+	for _, t := range Types {
+		if t.EventType() == r.Type {
+			e = reflect.New(reflect.TypeOf(t)).Interface().(contract.Event)
+		}
+	}
+	if e == nil {
+		return nil, nil
+	}
+
+	err := json.Unmarshal(r.Payload, e) //unmarshal it aka JSON.parse()
+	if err != nil {
+		return nil, fmt.Errorf("%s event: %v", r.Type, err)
+	}
+	return e, nil
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"example.org/lib/contract"
+)
+
+var update = flag.Bool("update", false, "rewrite schema.json from the Event types")
+
+// schema.json, for listeners outside Go, describes the Events as they are
+func TestSchemaFile(t *testing.T) {
+	schemaJSONasBytes, err := json.MarshalIndent(Schemas(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemaJSONasBytes = append(schemaJSONasBytes, '\n')
+	if *update {
+		if err := ioutil.WriteFile("schema.json", schemaJSONasBytes, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	written, err := ioutil.ReadFile("schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(written, schemaJSONasBytes) {
+		t.Error("schema.json is out of date, run go test ./events -run TestSchemaFile -update")
+	}
+
+	if len(Schemas().Payloads) != len(Types) || Schemas().Payloads["StageChanged"].Properties["Status"].Enum == nil {
+		t.Error("unexpected payload schemas", Schemas().Payloads)
+	}
+}
+
+func TestParse(t *testing.T) {
+	record := func(e contract.Event) contract.EventRecord {
+		payload, _ := json.Marshal(e)
+		return contract.EventRecord{Type: e.EventType(), Payload: payload}
+	}
+	approved := RequestApproved{TransferRequestID: "T1", LandID: "L1", From: "C1", To: "C2", Approver: "B1", Date: 3}
+	envelope := contract.EventEnvelope{Version: contract.EventsVersion, Chaincode: "transfer_cc", Function: "approveTransferRequest", TxID: "tx1", Events: []contract.EventRecord{
+		record(approved),
+		{Type: "LandSurveyed", Payload: json.RawMessage(`{"ID":"L1"}`)},
+		record(LandTransferred{LandID: "L1", PreviousOwner: "C1", CurrentOwner: "C2", TransferRequestID: "T1", Date: 3}),
+	}}
+	payload, _ := json.Marshal(envelope)
+
+	parsed, typed, err := Parse(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.TxID != "tx1" || len(parsed.Events) != 3 || len(typed) != 2 {
+		t.Fatal("unexpected events", parsed, typed)
+	}
+	if e, ok := typed[0].(*RequestApproved); !ok || *e != approved {
+		t.Error("unexpected event", typed[0])
+	}
+	if e, ok := typed[1].(*LandTransferred); !ok || e.CurrentOwner != "C2" {
+		t.Error("unexpected event", typed[1])
+	}
+
+	envelope.Version = 2
+	payload, _ = json.Marshal(envelope)
+	if _, _, err := Parse(payload); err == nil {
+		t.Error("events of another version parsed")
+	}
+}
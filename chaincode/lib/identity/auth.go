@@ -0,0 +1,57 @@
+package identity
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Authentication
+// ++++++++++++++
+
+// Authenticate => any role of the identity mapping
+func Authenticate(stub shim.ChaincodeStubInterface, role string, mspID string, certCN string) bool {
+	mapping, err := GetMapping(stub)
+	if err != nil {
+		return false
+	}
+
+	identity, ok := mapping[role]
+	if !ok || mspID != identity.MSP {
+		return false
+	}
+	for _, ca := range identity.CAs {
+		if certCN == ca {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole => authenticated as a member of role and enrolled with the role attribute
+func HasRole(stub shim.ChaincodeStubInterface, role string, mspID string, certCN string) bool {
+	return Authenticate(stub, role, mspID, certCN) && AuthorizeRole(stub, role)
+}
+
+// Authorization
+// +++++++++++++
+
+// AuthorizeRole => the role attribute embedded in the certificate at enrollment
+func AuthorizeRole(stub shim.ChaincodeStubInterface, role string) bool {
+	return cid.AssertAttributeValue(stub, "role", role) == nil
+}
+
+// AuthorizeProfile => the profileID attribute, naming the only profile the certificate may act for
+func AuthorizeProfile(stub shim.ChaincodeStubInterface, ID string) bool {
+	return cid.AssertAttributeValue(stub, "profileID", ID) == nil
+}
+
+// AuthorizeKeyHash => the certificate a Profile is bound to
+func AuthorizeKeyHash(stub shim.ChaincodeStubInterface, KeyHash string) bool {
+	creatorKeyHash, err := GetTxCreatorKeyHash(stub)
+	return err == nil && KeyHash != "" && creatorKeyHash == KeyHash
+}
+
+// AuthorizeDistrict => the state and district attributes embedded in the certificate at enrollment
+func AuthorizeDistrict(stub shim.ChaincodeStubInterface, State string, District string) bool {
+	return cid.AssertAttributeValue(stub, "state", State) == nil && cid.AssertAttributeValue(stub, "district", District) == nil
+}
@@ -0,0 +1,93 @@
+package identity
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+
+	"example.org/lib/envelope"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/attrmgr"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Tx Creator
+// ++++++++++
+
+// GetTxCreatorInfo gets the MSP, certificate issuer CN and certificate subject CN of the Tx Creator
+func GetTxCreatorInfo(stub shim.ChaincodeStubInterface) (string, string, string, error) {
+	var mspid string
+	var err error
+	var cert *x509.Certificate
+	mspid, err = cid.GetMSPID(stub)
+
+	if err != nil {
+		return "", "", "", fmt.Errorf("Error getting MSP identity: %v", err)
+	}
+
+	cert, err = cid.GetX509Certificate(stub)
+	if err != nil {
+		return "", "", "", fmt.Errorf("Error getting client certificate: %v", err)
+	} else if cert == nil {
+		return "", "", "", envelope.New(envelope.CodeAccessDenied, "Error: Tx Creator has no certificate!")
+	}
+
+	return mspid, cert.Issuer.CommonName, cert.Subject.CommonName, nil
+}
+
+// GetTxCreatorKeyHash gets the hash binding Profiles to the Tx Creator's certificate
+func GetTxCreatorKeyHash(stub shim.ChaincodeStubInterface) (string, error) {
+	cert, err := cid.GetX509Certificate(stub)
+	if err != nil {
+		return "", err
+	} else if cert == nil {
+		return "", envelope.New(envelope.CodeAccessDenied, "Error: Tx Creator has no certificate!")
+	}
+	return KeyHash(cert), nil
+}
+
+// GetTxCreatorJurisdiction gets the jurisdiction attributes of the Tx Creator's certificate, from the state down
+func GetTxCreatorJurisdiction(stub shim.ChaincodeStubInterface, attributes ...string) (string, string, string, error) {
+	values := make([]string, 3)
+	for i, attribute := range attributes {
+		value, found, err := cid.GetAttributeValue(stub, attribute)
+		if err != nil || !found {
+			return "", "", "", envelope.New(envelope.CodeAccessDenied, "Error: Certificate has no "+attribute+" attribute!")
+		}
+		values[i] = value
+	}
+	return values[0], values[1], values[2], nil
+}
+
+// Certificates
+// ++++++++++++
+
+// GetCertificateKeyHash gets the hash binding a Profile to a PEM certificate enrolled with profileID => ID
+func GetCertificateKeyHash(certificatePEM string, ID string) (string, error) {
+	block, _ := pem.Decode([]byte(certificatePEM))
+	if block == nil {
+		return "", envelope.InvalidArgument("Error: Invalid Certificate!")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", envelope.InvalidArgument("Error: Invalid Certificate!")
+	}
+
+	attributes, err := attrmgr.New().GetAttributesFromCert(cert)
+	if err != nil {
+		return "", envelope.InvalidArgument("Error: Invalid Certificate attributes!")
+	}
+	profileID, found, err := attributes.Value("profileID")
+	if err != nil || !found || profileID != ID {
+		return "", envelope.InvalidArgument("Error: Certificate is not enrolled for " + ID + "!")
+	}
+	return KeyHash(cert), nil
+}
+
+// KeyHash is the SHA-256 of the certificate's public key, so a Profile survives reissues of the same key
+func KeyHash(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return hex.EncodeToString(hash[:])
+}
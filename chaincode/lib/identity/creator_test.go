@@ -0,0 +1,41 @@
+package identity_test
+
+import (
+	"testing"
+
+	"example.org/lib/identity"
+	"example.org/lib/identitytest"
+)
+
+func TestCertificateKeyHash(t *testing.T) {
+	ids := identitytest.NewFactory()
+	attributes := map[string]string{"role": "lawyer", "profileID": "L1"}
+	lawyer, err := ids.Enroll("LawyerMSP", "lawyer1", attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := identity.GetCertificateKeyHash(lawyer.PEM(), "L1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != identity.KeyHash(lawyer.Certificate) || len(hash) != 64 {
+		t.Error("unexpected key hash", hash)
+	}
+
+	// A reissue of the same key keeps the binding
+	reissued, err := ids.Reenroll(lawyer, attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reissuedHash, _ := identity.GetCertificateKeyHash(reissued.PEM(), "L1"); reissuedHash != hash {
+		t.Error("reissued certificate bound to a different key hash")
+	}
+
+	if _, err := identity.GetCertificateKeyHash(lawyer.PEM(), "L2"); err == nil {
+		t.Error("certificate bound to a profile it was not enrolled for")
+	}
+	if _, err := identity.GetCertificateKeyHash("not a certificate", "L1"); err == nil {
+		t.Error("invalid certificate accepted")
+	}
+}
@@ -0,0 +1,89 @@
+// Package identity authenticates transaction creators against the role => identity mapping
+// stored on the ledger, and authorizes them by the attributes enrolled into their certificates.
+package identity
+
+import (
+	"encoding/json"
+
+	"example.org/lib/envelope"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Identity members of a role enroll with
+type Identity struct {
+	MSP string   `json:"MSP"`
+	CAs []string `json:"CAs"`
+}
+
+// MappingKey is the key the role => identity mapping is stored under
+const MappingKey = "identityMapping"
+
+// RequiredRoles are the roles every identity mapping must define
+var RequiredRoles = []string{"citizen", "lawyer", "registryofficer", "blro", "admin"}
+
+// DefaultMapping holds the identities of the network deployed under lran.com, used until Init stores a mapping
+var DefaultMapping = map[string]Identity{
+	"citizen":         {"CitizenMSP", []string{"ca.citizen.lran.com"}},
+	"lawyer":          {"LawyerMSP", []string{"ca.lawyer.lran.com"}},
+	"registryofficer": {"RegistryOfficeMSP", []string{"ca.registryoffice.lran.com"}},
+	"blro":            {"BLROMSP", []string{"ca.blro.lran.com"}},
+	"admin":           {"BLROMSP", []string{"ca.blro.lran.com"}},
+}
+
+// InitMapping stores the mapping passed to Init, or the default one if none is passed and none is stored yet.
+// An upgrade without arguments keeps the stored mapping.
+func InitMapping(stub shim.ChaincodeStubInterface) error {
+	_, params := stub.GetFunctionAndParameters()
+	if len(params) > 0 && len(params[0]) > 0 {
+		return PutMapping(stub, params[0])
+	}
+
+	mappingAsBytes, err := stub.GetState(MappingKey)
+	if err != nil {
+		return envelope.Internal("Failed to check if identity mapping exists!")
+	} else if mappingAsBytes != nil {
+		return nil
+	}
+
+	mappingJSONasBytes, err := json.Marshal(DefaultMapping)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(MappingKey, mappingJSONasBytes)
+}
+
+// GetMapping gets the role => identity mapping, falling back to the default one
+func GetMapping(stub shim.ChaincodeStubInterface) (map[string]Identity, error) {
+	mappingAsBytes, err := stub.GetState(MappingKey)
+	if err != nil {
+		return nil, envelope.Internal("Failed to get state for identity mapping")
+	} else if mappingAsBytes == nil {
+		return DefaultMapping, nil
+	}
+
+	var mapping map[string]Identity
+	err = json.Unmarshal(mappingAsBytes, &mapping) //unmarshal it aka JSON.parse()
+	return mapping, err
+}
+
+// PutMapping checks a role => identity mapping defines every role, then Puts State with Key => identityMapping
+func PutMapping(stub shim.ChaincodeStubInterface, mappingJSON string) error {
+	var mapping map[string]Identity
+	err := json.Unmarshal([]byte(mappingJSON), &mapping)
+	if err != nil {
+		return envelope.InvalidArgument("Error: Invalid identity mapping!")
+	}
+
+	for _, role := range RequiredRoles {
+		if mapping[role].MSP == "" || len(mapping[role].CAs) == 0 {
+			return envelope.InvalidArgument("Identity mapping must give an MSP and at least one CA for " + role + "!")
+		}
+	}
+
+	// Convert to Byte[]
+	mappingJSONasBytes, err := json.Marshal(mapping)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(MappingKey, mappingJSONasBytes)
+}
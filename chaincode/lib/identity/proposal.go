@@ -0,0 +1,49 @@
+package identity
+
+import (
+	"errors"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/common"
+	sc "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Proposal
+// ++++++++
+
+// GetProposalChaincode gets the chaincode the Tx Creator signed the proposal for.
+// Chaincodes called through InvokeChaincode see the proposal of the transaction calling them,
+// so a chaincode other than their own is the one calling them, on behalf of the Tx Creator.
+func GetProposalChaincode(stub shim.ChaincodeStubInterface) (string, error) {
+	signedProposal, err := stub.GetSignedProposal()
+	if err != nil {
+		return "", err
+	} else if signedProposal == nil {
+		return "", errors.New("Error: Transaction has no signed proposal!")
+	}
+
+	proposal := &sc.Proposal{}
+	err = proto.Unmarshal(signedProposal.ProposalBytes, proposal)
+	if err != nil {
+		return "", err
+	}
+	header := &common.Header{}
+	err = proto.Unmarshal(proposal.Header, header)
+	if err != nil {
+		return "", err
+	}
+	channelHeader := &common.ChannelHeader{}
+	err = proto.Unmarshal(header.ChannelHeader, channelHeader)
+	if err != nil {
+		return "", err
+	}
+	extension := &sc.ChaincodeHeaderExtension{}
+	err = proto.Unmarshal(channelHeader.Extension, extension)
+	if err != nil {
+		return "", err
+	} else if extension.ChaincodeId == nil {
+		return "", errors.New("Error: Proposal names no chaincode!")
+	}
+	return extension.ChaincodeId.Name, nil
+}
@@ -0,0 +1,165 @@
+// Package identitytest enrolls synthetic X.509 identities the way the CAs of the network would, and runs
+// chaincodes on a MockStub reporting them as the Tx Creator, so access-controlled transactions can be tested.
+//
+// shim.MockStub has no creator, so cid.GetMSPID and cid.GetX509Certificate fail on it;
+// Stub wraps it to give the creator, and the proposal, of each invocation.
+package identitytest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/attrmgr"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// CAs of the organizations of the network deployed under lran.com, by MSP, as the default identity mapping expects them
+var CAs = map[string]string{
+	"CitizenMSP":        "ca.citizen.lran.com",
+	"LawyerMSP":         "ca.lawyer.lran.com",
+	"RegistryOfficeMSP": "ca.registryoffice.lran.com",
+	"BLROMSP":           "ca.blro.lran.com",
+}
+
+// Identity is a member of an organization, enrolled by one of its CAs with attributes as Fabric CA would
+type Identity struct {
+	MSP         string
+	Name        string
+	Certificate *x509.Certificate
+	// Creator is the identity serialized as transactions carry it
+	Creator []byte
+	key     *ecdsa.PrivateKey
+}
+
+// PEM of the certificate of the identity, as rekeyX takes it
+func (id *Identity) PEM() string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: id.Certificate.Raw}))
+}
+
+// Factory issues the certificates of every organization, each CA generated the first time it enrolls a member
+type Factory struct {
+	cas     map[string]*authority
+	serials int64
+}
+
+// Certificate authority of an organization
+type authority struct {
+	key         *ecdsa.PrivateKey
+	certificate *x509.Certificate
+}
+
+// NewFactory with no CA generated yet
+func NewFactory() *Factory {
+	return &Factory{cas: map[string]*authority{}}
+}
+
+// Enroll Name as a member of the organization of MSP through its CA in CAs, its certificate carrying attributes,
+// e.g. role => blro. The subject CN of the certificate is Name.
+func (f *Factory) Enroll(MSP string, Name string, attributes map[string]string) (*Identity, error) {
+	return f.EnrollBy(CAs[MSP], MSP, Name, attributes)
+}
+
+// EnrollBy enrolls Name as a member of the organization of MSP through the CA with issuer CN CA,
+// e.g. one an identity mapping does not trust
+func (f *Factory) EnrollBy(CA string, MSP string, Name string, attributes map[string]string) (*Identity, error) {
+	ca, err := f.authority(MSP, CA)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return f.issue(ca, MSP, Name, key, attributes)
+}
+
+// Reenroll id through the CA that enrolled it, as Fabric CA reenrolls an identity: its new certificate
+// carries attributes and the same key, so the key hash a Profile is bound to stays the same
+func (f *Factory) Reenroll(id *Identity, attributes map[string]string) (*Identity, error) {
+	ca, err := f.authority(id.MSP, id.Certificate.Issuer.CommonName)
+	if err != nil {
+		return nil, err
+	}
+	return f.issue(ca, id.MSP, id.Name, id.key, attributes)
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Identity Name of the organization of MSP, its certificate for key issued by ca and carrying attributes
+func (f *Factory) issue(ca *authority, MSP string, Name string, key *ecdsa.PrivateKey, attributes map[string]string) (*Identity, error) {
+	f.serials++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(f.serials),
+		Subject:      pkix.Name{CommonName: Name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	err := attrmgr.New().AddAttributesToCert(&attrmgr.Attributes{Attrs: attributes}, template)
+	if err != nil {
+		return nil, err
+	}
+	template.ExtraExtensions = template.Extensions
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.certificate, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	creator, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   MSP,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{MSP, Name, certificate, creator, key}, nil
+}
+
+// CA with issuer CN of the organization of MSP, generated the first time one of its members is enrolled
+func (f *Factory) authority(MSP string, CN string) (*authority, error) {
+	if ca, ok := f.cas[MSP+"/"+CN]; ok {
+		return ca, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	f.serials++
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(f.serials),
+		Subject:               pkix.Name{CommonName: CN, Organization: []string{MSP}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	ca := &authority{key, certificate}
+	f.cas[MSP+"/"+CN] = ca
+	return ca, nil
+}
@@ -0,0 +1,94 @@
+package identitytest
+
+import (
+	"testing"
+
+	"example.org/lib/identity"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	sc "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Chaincode recording what the stub tells it of the Tx Creator of the last invocation
+type recorder struct {
+	MSP, CA, Creator, Role, Chaincode string
+	err                               error
+}
+
+func (r *recorder) Init(stub shim.ChaincodeStubInterface) sc.Response {
+	return shim.Success(nil)
+}
+
+func (r *recorder) Invoke(stub shim.ChaincodeStubInterface) sc.Response {
+	*r = recorder{}
+	r.MSP, r.CA, r.Creator, r.err = identity.GetTxCreatorInfo(stub)
+	if r.err != nil {
+		return shim.Error(r.err.Error())
+	}
+	r.Role, _, _ = cid.GetAttributeValue(stub, "role")
+	r.Chaincode, _ = identity.GetProposalChaincode(stub)
+	return shim.Success(nil)
+}
+
+func TestMockInvokeAs(t *testing.T) {
+	r := &recorder{}
+	stub := NewStub("test_cc", r)
+	ids := NewFactory()
+
+	lawyer, err := ids.Enroll("LawyerMSP", "L1", map[string]string{"role": "lawyer", "profileID": "L1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := stub.MockInvokeAs(lawyer, "1", [][]byte{[]byte("fcn")})
+	if res.Status != shim.OK {
+		t.Fatal("invoke failed", res.Message)
+	}
+	if r.MSP != "LawyerMSP" || r.CA != "ca.lawyer.lran.com" || r.Creator != "L1" || r.Role != "lawyer" || r.Chaincode != "test_cc" {
+		t.Error("unexpected Tx Creator", *r)
+	}
+
+	// Certificates of one organization can be issued by a CA the identity mapping does not trust
+	rogue, err := ids.EnrollBy("ca.rogue.example.com", "BLROMSP", "B1", map[string]string{"role": "blro"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.MockInvokeAs(rogue, "2", [][]byte{[]byte("fcn")})
+	if r.MSP != "BLROMSP" || r.CA != "ca.rogue.example.com" || r.Role != "blro" {
+		t.Error("unexpected Tx Creator", *r)
+	}
+	trusted, err := ids.Enroll("BLROMSP", "B2", map[string]string{"role": "blro"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rogue.Certificate.CheckSignatureFrom(ids.cas["BLROMSP/"+trusted.Certificate.Issuer.CommonName].certificate) == nil {
+		t.Error("certificate issued by the rogue CA verifies against the trusted one")
+	}
+
+	// The creator only holds for the invocation it was given for
+	res = stub.MockInvokeAs(nil, "3", [][]byte{[]byte("fcn")})
+	if res.Status == shim.OK || r.err == nil {
+		t.Error("invocation without an identity has a Tx Creator", *r)
+	}
+}
+
+func TestEnrollSharesCA(t *testing.T) {
+	ids := NewFactory()
+	first, err := ids.Enroll("CitizenMSP", "C1", map[string]string{"role": "citizen", "profileID": "C1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ids.Enroll("CitizenMSP", "C2", map[string]string{"role": "citizen", "profileID": "C2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca := ids.cas["CitizenMSP/ca.citizen.lran.com"]
+	for _, id := range []*Identity{first, second} {
+		if err := id.Certificate.CheckSignatureFrom(ca.certificate); err != nil {
+			t.Error(id.Name, "not issued by the organization's CA", err)
+		}
+	}
+	if first.Certificate.SerialNumber.Cmp(second.Certificate.SerialNumber) == 0 {
+		t.Error("certificates issued with the same serial number")
+	}
+}
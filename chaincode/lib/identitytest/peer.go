@@ -0,0 +1,86 @@
+package identitytest
+
+import (
+	"encoding/json"
+	"strings"
+
+	"example.org/lib/envelope"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	sc "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Peer stands in for the chaincodes the chaincode under test invokes, as the Invoker of its Stub.
+// Transactions reading a record, e.g. readLand, answer with the record Records holds for the ID of their
+// payload, or NOT_FOUND; those Responses holds answer with it, and any other succeeds with no payload.
+type Peer struct {
+	// Records by chaincode and ID, e.g. land_cc => L1 => the Land
+	Records map[string]map[string]interface{}
+	// Responses by chaincode and function, e.g. registryoffice_cc/getLeastBusyRegistryOfficer
+	Responses map[string]interface{}
+	// Calls made so far, in order
+	Calls []Call
+}
+
+// Call of a transaction of another chaincode
+type Call struct {
+	Chaincode string
+	Function  string
+	Payload   json.RawMessage
+}
+
+// NewPeer holding no records
+func NewPeer() *Peer {
+	return &Peer{Records: map[string]map[string]interface{}{}, Responses: map[string]interface{}{}}
+}
+
+// Put record with ID in chaincode, replacing any held before
+func (p *Peer) Put(chaincode string, ID string, record interface{}) {
+	if p.Records[chaincode] == nil {
+		p.Records[chaincode] = map[string]interface{}{}
+	}
+	p.Records[chaincode][ID] = record
+}
+
+// Called gives the payloads of the calls made to fcn of chaincode, in order
+func (p *Peer) Called(chaincode string, fcn string) []json.RawMessage {
+	var payloads []json.RawMessage
+	for _, c := range p.Calls {
+		if c.Chaincode == chaincode && c.Function == fcn {
+			payloads = append(payloads, c.Payload)
+		}
+	}
+	return payloads
+}
+
+// Invoke fcn of chaincodeName, the function and payload being the args a chaincode invokes it with
+func (p *Peer) Invoke(chaincodeName string, args [][]byte, channel string) sc.Response {
+	if len(args) == 0 {
+		return shim.Error(envelope.InvalidArgument("Received unknown function invocation!").Error())
+	}
+	call := Call{Chaincode: chaincodeName, Function: string(args[0]), Payload: json.RawMessage("{}")}
+	if len(args) > 1 {
+		call.Payload = args[1]
+	}
+	p.Calls = append(p.Calls, call)
+
+	response, ok := p.Responses[chaincodeName+"/"+call.Function]
+	if !ok && strings.HasPrefix(call.Function, "read") {
+		var payload struct {
+			ID string `json:"ID"`
+		}
+		_ = json.Unmarshal(call.Payload, &payload)
+		response, ok = p.Records[chaincodeName][payload.ID]
+		if !ok {
+			return shim.Error(envelope.NotFound("Record does not exist!", "ID", payload.ID).Error())
+		}
+	}
+	if !ok {
+		return shim.Success(nil)
+	}
+
+	responseJSONasBytes, err := json.Marshal(response)
+	if err != nil {
+		return shim.Error(envelope.Internal(err.Error()).Error())
+	}
+	return shim.Success(responseJSONasBytes)
+}
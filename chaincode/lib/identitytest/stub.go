@@ -0,0 +1,334 @@
+package identitytest
+
+import (
+	"errors"
+	"testing"
+	"unicode/utf8"
+
+	"example.org/lib/contract"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	sc "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Key a peer ranges from when given an empty start key, leaving out composite keys
+const emptyKeySubstitute = "\x01"
+
+// Stub is a MockStub reporting the creator and proposal of the transaction running on it,
+// paginating range queries and keeping the chaincode event of a transaction as a peer does.
+// The writes of a transaction are held back until it ends, so it reads only committed state, as on a peer.
+type Stub struct {
+	*shimtest.MockStub
+	cc       shim.Chaincode
+	args     [][]byte
+	creator  []byte
+	proposal *sc.SignedProposal
+	// Invoker runs the chaincodes this one invokes within its transaction, MockStub's peer chaincodes if nil
+	Invoker func(chaincodeName string, args [][]byte, channel string) sc.Response
+	// Event set by the transaction last run on the stub, nil if it set none
+	Event *sc.ChaincodeEvent
+	// Hold keeps the writes of transactions back until Commit, e.g. until every chaincode a transaction
+	// reaches has run; otherwise they are committed when the transaction ends
+	Hold bool
+	// Writes held back from the ledger, nil for a deleted key
+	writes map[string][]byte
+}
+
+// NewStub running cc as the chaincode called name
+func NewStub(name string, cc shim.Chaincode) *Stub {
+	return &Stub{MockStub: shimtest.NewMockStub(name, cc), cc: cc}
+}
+
+// Chaincode builds the chaincode called name with newChaincode, e.g. land.New, on a Stub, failing t if it cannot be built
+func Chaincode(t testing.TB, name string, newChaincode func() (*contract.Chaincode, error)) *Stub {
+	t.Helper()
+	cc, err := newChaincode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewStub(name, cc)
+}
+
+// Instantiate builds the chaincode as Chaincode does and instantiates it, proposed by no one,
+// giving the Factory enrolling the identities that propose its transactions
+func Instantiate(t testing.TB, name string, newChaincode func() (*contract.Chaincode, error)) (*Stub, *Factory) {
+	t.Helper()
+	stub := Chaincode(t, name, newChaincode)
+	res := stub.MockInitAs(nil, "0", [][]byte{[]byte("init")})
+	if res.Status != shim.OK {
+		t.Fatal("Init failed", res.Status, res.Message)
+	}
+	return stub, NewFactory()
+}
+
+// MockInitAs instantiates the chaincode with args, proposed by id, or no one if id is nil
+func (stub *Stub) MockInitAs(id *Identity, uuid string, args [][]byte) sc.Response {
+	return stub.propose(id, uuid, args, stub.cc.Init)
+}
+
+// MockInvokeAs calls the chaincode with args, proposed by id, or no one if id is nil
+func (stub *Stub) MockInvokeAs(id *Identity, uuid string, args [][]byte) sc.Response {
+	return stub.propose(id, uuid, args, stub.cc.Invoke)
+}
+
+// MockInvokeAt calls the chaincode as MockInvokeAs does, the transaction being timestamped at the Unix time now
+func (stub *Stub) MockInvokeAt(id *Identity, uuid string, args [][]byte, now int64) sc.Response {
+	return stub.propose(id, uuid, args, func(shim.ChaincodeStubInterface) sc.Response {
+		stub.TxTimestamp = &timestamp.Timestamp{Seconds: now}
+		return stub.cc.Invoke(stub)
+	})
+}
+
+// Transact runs the chaincode on transaction txID as proposed, at time if given, the way it runs when
+// invoked by another chaincode. Its writes are held back for the transaction invoking it to commit.
+// The transaction the stub was running, if it is called back, is restored after.
+func (stub *Stub) Transact(txID string, args [][]byte, creator []byte, proposal *sc.SignedProposal, time *timestamp.Timestamp) sc.Response {
+	previousArgs, previousCreator, previousProposal := stub.args, stub.creator, stub.proposal
+	previousTxID, previousTime, previousEvent := stub.TxID, stub.TxTimestamp, stub.Event
+
+	stub.start(txID, args, creator, proposal)
+	if time != nil {
+		stub.TxTimestamp = time
+	}
+	res := stub.cc.Invoke(stub)
+	stub.MockStub.MockTransactionEnd(txID)
+
+	stub.args, stub.creator, stub.proposal = previousArgs, previousCreator, previousProposal
+	stub.TxID, stub.TxTimestamp, stub.Event = previousTxID, previousTime, previousEvent
+	return res
+}
+
+// MockTransactionEnd ends the transaction running on the stub, committing its writes unless the stub holds them
+func (stub *Stub) MockTransactionEnd(uuid string) {
+	if !stub.Hold {
+		stub.Commit()
+	}
+	stub.MockStub.MockTransactionEnd(uuid)
+}
+
+// PutState holds the write of value to key back until the transaction commits, an empty value deleting key
+func (stub *Stub) PutState(key string, value []byte) error {
+	if stub.TxID == "" {
+		return errors.New("cannot PutState without a transactions - call stub.MockTransactionStart()?")
+	}
+	if stub.writes == nil {
+		stub.writes = map[string][]byte{}
+	}
+	stub.writes[key] = value
+	return nil
+}
+
+// DelState holds the deletion of key back until the transaction commits
+func (stub *Stub) DelState(key string) error {
+	return stub.PutState(key, nil)
+}
+
+// Commit the writes held back to the ledger of the stub
+func (stub *Stub) Commit() {
+	// MockStub only writes within a transaction
+	txID := stub.TxID
+	stub.TxID = "commit"
+	for key, value := range stub.writes {
+		stub.MockStub.PutState(key, value)
+	}
+	stub.TxID, stub.writes = txID, nil
+}
+
+// Discard the writes held back, as a peer does those of a transaction that fails
+func (stub *Stub) Discard() {
+	stub.writes = nil
+}
+
+// GetArgs gives the arguments of the transaction running on the stub
+func (stub *Stub) GetArgs() [][]byte {
+	return stub.args
+}
+
+// GetStringArgs gives the arguments of the transaction running on the stub as strings
+func (stub *Stub) GetStringArgs() []string {
+	var args []string
+	for _, arg := range stub.args {
+		args = append(args, string(arg))
+	}
+	return args
+}
+
+// GetFunctionAndParameters splits the arguments of the transaction into its function and parameters
+func (stub *Stub) GetFunctionAndParameters() (string, []string) {
+	args := stub.GetStringArgs()
+	if len(args) == 0 {
+		return "", []string{}
+	}
+	return args[0], args[1:]
+}
+
+// GetArgsSlice gives the arguments of the transaction joined together
+func (stub *Stub) GetArgsSlice() ([]byte, error) {
+	var slice []byte
+	for _, arg := range stub.args {
+		slice = append(slice, arg...)
+	}
+	return slice, nil
+}
+
+// GetCreator gives the serialized identity proposing the transaction
+func (stub *Stub) GetCreator() ([]byte, error) {
+	return stub.creator, nil
+}
+
+// GetSignedProposal gives the proposal of the transaction, the same for every chaincode it reaches
+func (stub *Stub) GetSignedProposal() (*sc.SignedProposal, error) {
+	return stub.proposal, nil
+}
+
+// InvokeChaincode calls another chaincode within the transaction, through the Invoker if set
+func (stub *Stub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) sc.Response {
+	if stub.Invoker != nil {
+		return stub.Invoker(chaincodeName, args, channel)
+	}
+	return stub.MockStub.InvokeChaincode(chaincodeName, args, channel)
+}
+
+// SetEvent sets the chaincode event of the transaction, replacing any set before, as a peer keeps one per transaction
+func (stub *Stub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return errors.New("event name can not be empty string")
+	}
+	stub.Event = &sc.ChaincodeEvent{ChaincodeId: stub.Name, TxId: stub.TxID, EventName: name, Payload: payload}
+	return nil
+}
+
+// GetStateByRangeWithPagination ranges over the simple keys from startKey to endKey, open if empty, as a peer
+// does, a page of pageSize keys at a time from bookmark, the key the previous page stopped before
+func (stub *Stub) GetStateByRangeWithPagination(startKey string, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *sc.QueryResponseMetadata, error) {
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+	if endKey == "" {
+		endKey = string(utf8.MaxRune)
+	}
+	return stub.page(startKey, endKey, pageSize, bookmark)
+}
+
+// GetStateByPartialCompositeKeyWithPagination ranges over the composite keys of objectType starting with keys,
+// a page of pageSize keys at a time from bookmark, the key the previous page stopped before
+func (stub *Stub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *sc.QueryResponseMetadata, error) {
+	partialKey, err := stub.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stub.page(partialKey, partialKey+string(utf8.MaxRune), pageSize, bookmark)
+}
+
+// SignedProposal of transaction txID to chaincode by creator, as a client would sign it
+func SignedProposal(txID string, chaincode string, creator []byte) (*sc.SignedProposal, error) {
+	extension, err := proto.Marshal(&sc.ChaincodeHeaderExtension{ChaincodeId: &sc.ChaincodeID{Name: chaincode}})
+	if err != nil {
+		return nil, err
+	}
+	channelHeader, err := proto.Marshal(&common.ChannelHeader{
+		Type:      int32(common.HeaderType_ENDORSER_TRANSACTION),
+		ChannelId: contract.Channel,
+		TxId:      txID,
+		Extension: extension,
+	})
+	if err != nil {
+		return nil, err
+	}
+	signatureHeader, err := proto.Marshal(&common.SignatureHeader{Creator: creator})
+	if err != nil {
+		return nil, err
+	}
+	header, err := proto.Marshal(&common.Header{ChannelHeader: channelHeader, SignatureHeader: signatureHeader})
+	if err != nil {
+		return nil, err
+	}
+	proposalBytes, err := proto.Marshal(&sc.Proposal{Header: header})
+	if err != nil {
+		return nil, err
+	}
+	return &sc.SignedProposal{ProposalBytes: proposalBytes}, nil
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Run fcn, the chaincode's Init or Invoke, on a transaction proposed to this chaincode by id
+func (stub *Stub) propose(id *Identity, uuid string, args [][]byte, fcn func(shim.ChaincodeStubInterface) sc.Response) sc.Response {
+	var creator []byte
+	if id != nil {
+		creator = id.Creator
+	}
+	proposal, err := SignedProposal(uuid, stub.Name, creator)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	stub.start(uuid, args, creator, proposal)
+	res := fcn(stub)
+	if res.Status != shim.OK {
+		stub.Discard()
+	}
+	stub.MockTransactionEnd(uuid)
+	stub.args, stub.creator, stub.proposal = nil, nil, nil
+	return res
+}
+
+// Page of at most pageSize keys from startKey, or bookmark if set, to endKey, and the key the next page starts at
+func (stub *Stub) page(startKey string, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *sc.QueryResponseMetadata, error) {
+	if bookmark != "" {
+		startKey = bookmark
+	}
+
+	resultsIterator := shimtest.NewMockStateRangeQueryIterator(stub.MockStub, startKey, endKey)
+	defer resultsIterator.Close()
+	page := &pageIterator{}
+	metadata := &sc.QueryResponseMetadata{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(page.results) == int(pageSize) {
+			metadata.Bookmark = queryResponse.Key
+			break
+		}
+		page.results = append(page.results, queryResponse)
+	}
+	metadata.FetchedRecordsCount = int32(len(page.results))
+	return page, metadata, nil
+}
+
+// Start a transaction on the stub
+func (stub *Stub) start(txID string, args [][]byte, creator []byte, proposal *sc.SignedProposal) {
+	stub.MockTransactionStart(txID)
+	stub.args, stub.creator, stub.proposal = args, creator, proposal
+	stub.Event = nil
+}
+
+// Iterator over a page of results
+type pageIterator struct {
+	results []*queryresult.KV
+}
+
+func (it *pageIterator) HasNext() bool {
+	return len(it.results) > 0
+}
+
+func (it *pageIterator) Next() (*queryresult.KV, error) {
+	if len(it.results) == 0 {
+		return nil, errors.New("no more results")
+	}
+	next := it.results[0]
+	it.results = it.results[1:]
+	return next, nil
+}
+
+func (it *pageIterator) Close() error {
+	return nil
+}
@@ -0,0 +1,88 @@
+// Package query turns ledger queries into the JSON the chaincodes return to the middleware.
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"example.org/lib/envelope"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// FromIterator constructs a JSON array of {Key, Value} records from an iterator
+func FromIterator(resultsIterator shim.StateQueryIteratorInterface) (*bytes.Buffer, error) {
+	// buffer is a JSON array containing QueryResults
+	var buffer bytes.Buffer
+	buffer.WriteString("[")
+
+	bArrayMemberAlreadyWritten := false
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		// Add a comma before array members, suppress it for the first array member
+		if bArrayMemberAlreadyWritten == true {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString("{\"Key\":")
+		key, err := json.Marshal(queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(key)
+
+		buffer.WriteString(", \"Value\":")
+		// Record is a JSON object, so we write as-is
+		buffer.WriteString(string(queryResponse.Value))
+		buffer.WriteString("}")
+		bArrayMemberAlreadyWritten = true
+	}
+	buffer.WriteString("]")
+	return &buffer, nil
+}
+
+// Results gets the records matching a CouchDB query string as a JSON array
+func Results(stub shim.ChaincodeStubInterface, queryString string) ([]byte, error) {
+	resultsIterator, err := stub.GetQueryResult(queryString)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	buffer, err := FromIterator(resultsIterator)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// ByPrefix ranges over every key starting with prefix, in key order
+func ByPrefix(stub shim.ChaincodeStubInterface, prefix string) (shim.StateQueryIteratorInterface, error) {
+	if len(prefix) == 0 {
+		return nil, envelope.InvalidArgument("Error: Empty key prefix!")
+	}
+
+	// The end key is the prefix with its last byte incremented, e.g. "lawyer." for "lawyer-"
+	end := []byte(prefix)
+	end[len(end)-1]++
+	return stub.GetStateByRange(prefix, string(end))
+}
+
+// Page is a JSON object holding a page of records and the Bookmark of the next page
+func Page(records [][]byte, bookmark string) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("{\"Records\":[")
+	buffer.Write(bytes.Join(records, []byte(",")))
+	buffer.WriteString("],\"Count\":" + strconv.Itoa(len(records)))
+
+	nextBookmark, err := json.Marshal(bookmark)
+	if err != nil {
+		return nil, err
+	}
+	buffer.WriteString(",\"Bookmark\":")
+	buffer.Write(nextBookmark)
+	buffer.WriteString("}")
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+)
+
+func TestByPrefix(t *testing.T) {
+	stub := shimtest.NewMockStub("query", nil)
+	stub.MockTransactionStart("1")
+	defer stub.MockTransactionEnd("1")
+	for _, key := range []string{"lawyer-L1", "lawyer-L2", "lawyer.", "lawyerX", "land-1"} {
+		stub.PutState(key, []byte(`{}`))
+	}
+
+	resultsIterator, err := ByPrefix(stub, "lawyer-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resultsIterator.Close()
+
+	var keys []string
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, queryResponse.Key)
+	}
+	if len(keys) != 2 || keys[0] != "lawyer-L1" || keys[1] != "lawyer-L2" {
+		t.Error("unexpected keys", keys)
+	}
+}
+
+func TestPage(t *testing.T) {
+	page, err := Page([][]byte{[]byte(`{"ID":"T1"}`), []byte(`{"ID":"T2"}`)}, "next")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(page) != `{"Records":[{"ID":"T1"},{"ID":"T2"}],"Count":2,"Bookmark":"next"}` {
+		t.Error("unexpected page", string(page))
+	}
+}
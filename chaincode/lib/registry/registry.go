@@ -0,0 +1,360 @@
+// Package registry is the professional registry lawyer_cc, registryoffice_cc and blro_cc configure:
+// Profiles bound to the certificate creating them, licensed by an admin and tracking their cases.
+package registry
+
+import (
+	"encoding/json"
+
+	"example.org/lib/cases"
+	"example.org/lib/contract"
+	"example.org/lib/envelope"
+	"example.org/lib/events"
+	"example.org/lib/identity"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Professional holds the fields every professional Profile shares
+// Status is pending until a licence is recorded, then active, suspended or revoked;
+// an active licence outside ValidFrom..ValidUntil (Unix seconds) counts as expired.
+type Professional struct {
+	ID   string `json:"ID"`
+	Name string `json:"Name"`
+	cases.Cases
+	KeyHash       string `json:"KeyHash"`
+	LicenceNumber string `json:"LicenceNumber"`
+	IssuingBody   string `json:"IssuingBody"`
+	ValidFrom     int    `json:"ValidFrom"`
+	ValidUntil    int    `json:"ValidUntil"`
+	Status        string `json:"Status"`
+}
+
+// Profile is a chaincode's professional structure, embedding Professional
+type Profile interface {
+	Base() *Professional
+}
+
+// Base gives the registry the shared fields of a Profile
+func (p *Professional) Base() *Professional {
+	return p
+}
+
+// Active checks the licence is active at Unix time now
+func (p *Professional) Active(now int) bool {
+	return p.Status == "active" && p.ValidFrom <= now && now <= p.ValidUntil
+}
+
+// Config is what a chaincode tells the registry about its professionals
+type Config struct {
+	// Name in function names and messages, e.g. Lawyer for createLawyer
+	Name string
+	// Profiles are stored with Key => Prefix-ID
+	Prefix string
+	// Role creating Profiles
+	Role string
+	// New returns an empty Profile, and Fill sets its own fields from the ones createX takes after ID and Name
+	New  func() Profile
+	Fill func(stub shim.ChaincodeStubInterface, p Profile, fields []string) error
+}
+
+// RekeyArgs is the payload of rekeyX, Certificate being the renewed certificate as PEM
+type RekeyArgs struct {
+	ID          string `json:"ID" validate:"required,key"`
+	Certificate string `json:"Certificate" validate:"required,pem"`
+}
+
+// LicenceArgs is the payload of licenseX, the licence being valid from ValidFrom to ValidUntil (Unix seconds)
+type LicenceArgs struct {
+	ID            string `json:"ID" validate:"required,key"`
+	LicenceNumber string `json:"LicenceNumber" validate:"required"`
+	IssuingBody   string `json:"IssuingBody" validate:"required"`
+	ValidFrom     int    `json:"ValidFrom" validate:"min=0"`
+	ValidUntil    int    `json:"ValidUntil" validate:"required,min=1"`
+}
+
+// StatusArgs is the payload of setXStatus
+type StatusArgs struct {
+	ID     string `json:"ID" validate:"required,key"`
+	Status string `json:"Status" validate:"required,oneof=active suspended revoked"`
+}
+
+// Registry of the Profiles of one kind of professional
+type Registry struct {
+	Config
+}
+
+// New registry of the professionals described by c
+func New(c Config) *Registry {
+	return &Registry{c}
+}
+
+// Access gives the roles allowed to call the registry's transactions
+func (r *Registry) Access() map[string][]string {
+	return map[string][]string{
+		"create" + r.Name:         {r.Role},
+		"license" + r.Name:        {"admin"},
+		"set" + r.Name + "Status": {"admin"},
+	}
+}
+
+// Ledger of the chaincode holding the registry, importing only its Profiles
+func (r *Registry) Ledger() contract.Ledger {
+	return contract.Ledger{Records: map[string]interface{}{r.key(""): r.New()}}
+}
+
+// Authorize the caller of a transaction, for the chaincode's BeforeTransaction hook.
+// Cases are only updated through the chaincodes tracking them, which authorize the Tx Creator themselves.
+func (r *Registry) Authorize(ctx *contract.TransactionContext) error {
+	err := ctx.AuthorizeCaller(cases.Callers())
+	if err != nil {
+		return err
+	}
+	return ctx.Authorize(r.Access())
+}
+
+// Create a new Profile, only by the certificate enrolled for it (C of CRUD)
+func (r *Registry) Create(ctx *contract.TransactionContext, ID string, Name string, fields ...string) error {
+	// A profile can only be created by the certificate enrolled for it
+	if !identity.AuthorizeProfile(ctx.GetStub(), ID) {
+		return ctx.AccessDenied("ProfileID", ID)
+	}
+
+	// Bind the Profile to the public key of the certificate creating it
+	KeyHash, err := identity.GetTxCreatorKeyHash(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+
+	// Check if Profile exists with Key => Prefix-ID
+	profileAsBytes, err := ctx.GetStub().GetState(r.key(ID))
+	if err != nil {
+		return envelope.Internal("Failed to check if " + r.Name + " exists!")
+	} else if profileAsBytes != nil {
+		return envelope.Conflict(r.Name+" Already Exists!", "ID", ID)
+	}
+
+	// Generate Profile from params provided
+	profile := r.New()
+	*profile.Base() = Professional{ID: ID, Name: Name, KeyHash: KeyHash, Status: "pending"}
+	if r.Fill != nil {
+		err = r.Fill(ctx.GetStub(), profile, fields)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = r.Put(ctx.GetStub(), profile)
+	if err != nil {
+		return err
+	}
+	Date, err := TxTime(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	return ctx.Emit(events.ProfileCreated{ProfileID: ID, Role: r.Role, Name: Name, Date: Date})
+}
+
+// AddCase adds a new active case, by the chaincode handing the case over (U of CRUD)
+func (r *Registry) AddCase(ctx *contract.TransactionContext, args cases.Args) error {
+	return r.updateCases(ctx.GetStub(), args.ID, args.CaseID, func(c *cases.Cases, CaseID string) bool {
+		c.Add(CaseID)
+		return true
+	})
+}
+
+// CompleteCase completes a case, by the chaincode approving it (U of CRUD)
+func (r *Registry) CompleteCase(ctx *contract.TransactionContext, args cases.Args) error {
+	return r.updateCases(ctx.GetStub(), args.ID, args.CaseID, (*cases.Cases).Complete)
+}
+
+// RemoveCase removes an active case on reassignment, by the chaincode reassigning it, without completing it (U of CRUD)
+func (r *Registry) RemoveCase(ctx *contract.TransactionContext, args cases.Args) error {
+	return r.updateCases(ctx.GetStub(), args.ID, args.CaseID, (*cases.Cases).Remove)
+}
+
+// UpdateCases applies changes to the cases of a professional in order, writing the Profile once, by the chaincode
+// moving them (U of CRUD)
+func (r *Registry) UpdateCases(ctx *contract.TransactionContext, args cases.UpdateArgs) error {
+	profileToUpdate, err := r.Get(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	}
+	for _, change := range args.Changes {
+		if !profileToUpdate.Base().Apply(change) {
+			return envelope.InvalidState("Case is not active for "+r.Name+"!", "ID", args.ID, "CaseID", change.CaseID)
+		}
+	}
+
+	return r.Put(ctx.GetStub(), profileToUpdate)
+}
+
+// Rekey binds a Profile to a renewed certificate, by its bound certificate or an admin (U of CRUD)
+func (r *Registry) Rekey(ctx *contract.TransactionContext, args RekeyArgs) error {
+	KeyHash, err := identity.GetCertificateKeyHash(args.Certificate, args.ID)
+	if err != nil {
+		return err
+	}
+
+	profileToUpdate, err := r.Get(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	}
+	p := profileToUpdate.Base()
+
+	// Only the bound certificate, or an admin when it is lost or expired, can rekey the Profile
+	if !ctx.HasRole("admin") && !identity.AuthorizeKeyHash(ctx.GetStub(), p.KeyHash) {
+		return ctx.AccessDenied("ProfileID", args.ID)
+	}
+
+	// Update KeyHash => hash of Certificate
+	p.KeyHash = KeyHash
+
+	err = r.Put(ctx.GetStub(), profileToUpdate)
+	if err != nil {
+		return err
+	}
+	Date, err := TxTime(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	return ctx.Emit(events.ProfileRekeyed{ProfileID: p.ID, Role: r.Role, KeyHash: KeyHash, Date: Date})
+}
+
+// License records or renews the licence of a professional, by an admin (U of CRUD)
+func (r *Registry) License(ctx *contract.TransactionContext, args LicenceArgs) error {
+	if args.ValidUntil <= args.ValidFrom {
+		return contract.InvalidArguments("ValidUntil", "must be after ValidFrom")
+	}
+
+	profileToUpdate, err := r.Get(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	}
+	p := profileToUpdate.Base()
+	if p.Status == "revoked" {
+		return envelope.InvalidState(r.Name+" licence has been revoked!", "ID", p.ID)
+	}
+
+	// Update the licence, activating a pending Profile
+	p.LicenceNumber = args.LicenceNumber
+	p.IssuingBody = args.IssuingBody
+	p.ValidFrom = args.ValidFrom
+	p.ValidUntil = args.ValidUntil
+	if p.Status == "" || p.Status == "pending" {
+		p.Status = "active"
+	}
+
+	err = r.Put(ctx.GetStub(), profileToUpdate)
+	if err != nil {
+		return err
+	}
+	Date, err := TxTime(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	return ctx.Emit(events.ProfileLicensed{
+		ProfileID:     p.ID,
+		Role:          r.Role,
+		LicenceNumber: p.LicenceNumber,
+		IssuingBody:   p.IssuingBody,
+		ValidFrom:     p.ValidFrom,
+		ValidUntil:    p.ValidUntil,
+		Status:        p.Status,
+		Date:          Date,
+	})
+}
+
+// SetStatus suspends, reinstates or revokes a licensed professional, by an admin (U of CRUD)
+func (r *Registry) SetStatus(ctx *contract.TransactionContext, args StatusArgs) error {
+	profileToUpdate, err := r.Get(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	}
+	p := profileToUpdate.Base()
+
+	// Revocation is final, and only a licensed professional can be reinstated or suspended
+	if p.Status == "revoked" {
+		return envelope.InvalidState(r.Name+" licence has been revoked!", "ID", p.ID)
+	} else if p.LicenceNumber == "" && args.Status != "revoked" {
+		return envelope.InvalidState(r.Name+" has no licence!", "ID", p.ID)
+	}
+
+	// Update Status => Status
+	PreviousStatus := p.Status
+	p.Status = args.Status
+
+	err = r.Put(ctx.GetStub(), profileToUpdate)
+	if err != nil {
+		return err
+	}
+	Date, err := TxTime(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	return ctx.Emit(events.ProfileStatusChanged{ProfileID: p.ID, Role: r.Role, PreviousStatus: PreviousStatus, Status: p.Status, Date: Date})
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Get the Profile with ID
+func (r *Registry) Get(stub shim.ChaincodeStubInterface, ID string) (Profile, error) {
+	profileToRead := r.New()
+
+	profileAsBytes, err := r.getState(stub, ID)
+	if err != nil {
+		return profileToRead, err
+	}
+
+	err = json.Unmarshal(profileAsBytes, profileToRead) //unmarshal it aka JSON.parse()
+	return profileToRead, err
+}
+
+// Put State of Profile with Key => Prefix-ID
+func (r *Registry) Put(stub shim.ChaincodeStubInterface, p Profile) error {
+	// Convert to Byte[]
+	profileJSONasBytes, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	return stub.PutState(r.key(p.Base().ID), profileJSONasBytes)
+}
+
+// TxTime is the Unix time of the transaction, the same on every endorsing peer
+func TxTime(stub shim.ChaincodeStubInterface) (int, error) {
+	timestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return 0, err
+	}
+	return int(timestamp.Seconds), nil
+}
+
+// Key the Profile with ID is stored under
+func (r *Registry) key(ID string) string {
+	return r.Prefix + "-" + ID
+}
+
+// Get State of the Profile with ID as stored
+func (r *Registry) getState(stub shim.ChaincodeStubInterface, ID string) ([]byte, error) {
+	profileAsBytes, err := stub.GetState(r.key(ID))
+	if err != nil {
+		return nil, envelope.Internal("Failed to get state for " + ID)
+	} else if profileAsBytes == nil {
+		return nil, envelope.NotFound(r.Name+" does not exist!", "ID", ID)
+	}
+	return profileAsBytes, nil
+}
+
+// Apply update to the Cases of the Profile with ID, failing if it reports the case was not active.
+func (r *Registry) updateCases(stub shim.ChaincodeStubInterface, ID string, CaseID string, update func(c *cases.Cases, CaseID string) bool) error {
+	profileToUpdate, err := r.Get(stub, ID)
+	if err != nil {
+		return err
+	}
+	if !update(&profileToUpdate.Base().Cases, CaseID) {
+		return envelope.InvalidState("Case is not active for "+r.Name+"!", "ID", ID, "CaseID", CaseID)
+	}
+
+	return r.Put(stub, profileToUpdate)
+}
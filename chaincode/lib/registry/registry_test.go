@@ -0,0 +1,94 @@
+package registry
+
+import (
+	"encoding/json"
+	"testing"
+
+	"example.org/lib/cases"
+	"example.org/lib/envelope"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+)
+
+// Profile of a professional with a field of its own, as the chaincodes configure them
+type lawyer struct {
+	Professional
+	CitizenID string `json:"CitizenID"`
+}
+
+var lawyers = New(Config{
+	Name:   "Lawyer",
+	Prefix: "lawyer",
+	Role:   "lawyer",
+	New: func() Profile {
+		return &lawyer{}
+	},
+})
+
+func TestProfilesKeepTheirFormat(t *testing.T) {
+	stub := shimtest.NewMockStub("registry", nil)
+
+	// A Profile written before the registry existed
+	stub.MockTransactionStart("1")
+	stub.PutState("lawyer-L1", []byte(`{"ID":"L1","Name":"Lawyer","CitizenID":"C1","CompletedCases":["T1"],"ActiveCases":["T2"],"KeyHash":"hash","Status":"active","ValidFrom":0,"ValidUntil":10}`))
+	stub.MockTransactionEnd("1")
+
+	profile, err := lawyers.Get(stub, "L1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := profile.(*lawyer)
+	if l.ID != "L1" || l.CitizenID != "C1" || l.KeyHash != "hash" || len(l.ActiveCases) != 1 || len(l.CompletedCases) != 1 {
+		t.Error("Profile not read in full", l)
+	}
+	if !l.Active(5) || l.Active(11) {
+		t.Error("licence window not applied")
+	}
+
+	// Shared fields are written at the top level, next to the chaincode's own
+	stub.MockTransactionStart("2")
+	if err := lawyers.Put(stub, l); err != nil {
+		t.Fatal(err)
+	}
+	stub.MockTransactionEnd("2")
+	var stored map[string]interface{}
+	if err := json.Unmarshal(stub.State["lawyer-L1"], &stored); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"ID", "Name", "CitizenID", "CompletedCases", "ActiveCases", "KeyHash", "Status", "ValidFrom", "ValidUntil"} {
+		if _, ok := stored[field]; !ok {
+			t.Error("field", field, "missing from stored Profile")
+		}
+	}
+
+	if _, err := lawyers.Get(stub, "L2"); err == nil {
+		t.Error("read a Profile that does not exist")
+	}
+}
+
+func TestCaseUpdates(t *testing.T) {
+	stub := shimtest.NewMockStub("registry", nil)
+	stub.MockTransactionStart("1")
+	stub.PutState("lawyer-L1", []byte(`{"ID":"L1","ActiveCases":["T1"]}`))
+	defer stub.MockTransactionEnd("1")
+
+	// removeCase without the authentication only MockStub cannot provide
+	removeCase := (*cases.Cases).Remove
+
+	if err := lawyers.updateCases(stub, "L1", "T2", removeCase); err == nil {
+		t.Error("removed a case that was not active")
+	}
+	if err := lawyers.updateCases(stub, "L1", "T1", removeCase); err != nil {
+		t.Fatal("removeCase failed", err)
+	}
+	profile, _ := lawyers.Get(stub, "L1")
+	if len(profile.Base().ActiveCases) != 0 {
+		t.Error("case still active", profile.Base().ActiveCases)
+	}
+
+	// A case completes only while it is active
+	completeCase := (*cases.Cases).Complete
+	err := lawyers.updateCases(stub, "L1", "T1", completeCase)
+	if envelope.CodeOf(err) != envelope.CodeInvalidState {
+		t.Error("completed a case that was not active", err)
+	}
+}
@@ -0,0 +1,168 @@
+// Package archive assembles the ledgers of the chaincodes of a network, dumped a page at a time through
+// dumpState, into a versioned, checksummed archive, and seeds a network with an archive through importState.
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"example.org/lib/contract"
+)
+
+// Version of the archive format Dump writes and Read accepts
+const Version = 1
+
+// Archive holds the entries of the ledger of each chaincode, in key order, as dumped at Height of the chain
+// Checksum is the SHA-256 of the archive without its Checksum, as hex.
+type Archive struct {
+	Version  int                         `json:"Version"`
+	Created  string                      `json:"Created"`
+	Height   uint64                      `json:"Height"`
+	Ledgers  map[string][]contract.Entry `json:"Ledgers"`
+	Checksum string                      `json:"Checksum"`
+}
+
+// Network is what an archive is dumped from or imported into, by an admin
+type Network interface {
+	// Query evaluates fcn of chaincode with payload as its argument, without submitting a transaction
+	Query(chaincode string, fcn string, payload interface{}) ([]byte, error)
+	// Submit fcn of chaincode with payload as its argument, giving its response once the transaction is committed
+	Submit(chaincode string, fcn string, payload interface{}) ([]byte, error)
+	// Height of the chain, which grows with every transaction committed
+	Height() (uint64, error)
+}
+
+// Dump the ledger of each of chaincodes from the network, pageSize entries at a time.
+// Pages are separate queries, so the dump fails if a transaction is committed while it is taken.
+func Dump(n Network, chaincodes []string, pageSize int32) (*Archive, error) {
+	height, err := n.Height()
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Archive{Version: Version, Created: time.Now().UTC().Format(time.RFC3339), Height: height, Ledgers: map[string][]contract.Entry{}}
+	for _, chaincode := range chaincodes {
+		entries := []contract.Entry{}
+		bookmark := ""
+		for {
+			pageAsBytes, err := n.Query(chaincode, "dumpState", contract.DumpArgs{PageSize: pageSize, Bookmark: bookmark})
+			if err != nil {
+				return nil, fmt.Errorf("dumping %s: %v", chaincode, err)
+			}
+			var page contract.LedgerPage
+			err = json.Unmarshal(pageAsBytes, &page) //unmarshal it aka JSON.parse()
+			if err != nil {
+				return nil, fmt.Errorf("dumping %s: %v", chaincode, err)
+			}
+			entries = append(entries, page.Records...)
+			if page.Bookmark == "" {
+				break
+			}
+			bookmark = page.Bookmark
+		}
+		a.Ledgers[chaincode] = entries
+	}
+
+	after, err := n.Height()
+	if err != nil {
+		return nil, err
+	} else if after != height {
+		return nil, fmt.Errorf("the chain grew from height %d to %d while dumping", height, after)
+	}
+
+	a.Checksum, err = a.sum()
+	return a, err
+}
+
+// Import the ledgers of the archive into the network, batchSize entries per transaction, reporting
+// the entries of each chaincode imported so far to progress if set. Entries overwrite the keys they hold,
+// so an interrupted import is resumed by running it again.
+func Import(n Network, a *Archive, batchSize int, progress func(chaincode string, imported int)) error {
+	err := a.Verify()
+	if err != nil {
+		return err
+	}
+
+	for _, chaincode := range a.Chaincodes() {
+		entries := a.Ledgers[chaincode]
+		for start := 0; start < len(entries); start += batchSize {
+			end := start + batchSize
+			if end > len(entries) {
+				end = len(entries)
+			}
+			_, err := n.Submit(chaincode, "importState", contract.ImportArgs{Records: entries[start:end]})
+			if err != nil {
+				return fmt.Errorf("importing entries %d to %d of %s: %v", start, end, chaincode, err)
+			}
+			if progress != nil {
+				progress(chaincode, end)
+			}
+		}
+	}
+	return nil
+}
+
+// Read an archive written by Write, checking its Version and Checksum
+func Read(r io.Reader) (*Archive, error) {
+	a := &Archive{}
+	err := json.NewDecoder(r).Decode(a)
+	if err != nil {
+		return nil, err
+	}
+	return a, a.Verify()
+}
+
+// Write the archive as JSON
+func (a *Archive) Write(w io.Writer) error {
+	archiveJSONasBytes, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(archiveJSONasBytes, '\n'))
+	return err
+}
+
+// Verify the archive is of the Version this package reads, and matches its Checksum
+func (a *Archive) Verify() error {
+	if a.Version != Version {
+		return fmt.Errorf("archive version %d is not supported, expecting %d", a.Version, Version)
+	}
+	sum, err := a.sum()
+	if err != nil {
+		return err
+	} else if sum != a.Checksum {
+		return fmt.Errorf("archive checksum %s does not match its contents, %s", a.Checksum, sum)
+	}
+	return nil
+}
+
+// Chaincodes the archive holds the ledgers of, in name order
+func (a *Archive) Chaincodes() []string {
+	var chaincodes []string
+	for chaincode := range a.Ledgers {
+		chaincodes = append(chaincodes, chaincode)
+	}
+	sort.Strings(chaincodes)
+	return chaincodes
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// SHA-256 of the archive without its Checksum. encoding/json writes map keys in order, so the JSON is canonical.
+func (a *Archive) sum() (string, error) {
+	unsummed := *a
+	unsummed.Checksum = ""
+	archiveJSONasBytes, err := json.Marshal(unsummed)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(archiveJSONasBytes)
+	return hex.EncodeToString(sum[:]), nil
+}
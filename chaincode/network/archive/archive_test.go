@@ -0,0 +1,132 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"example.org/network"
+)
+
+var office = network.Office{State: "KA", District: "BLR", OfficeID: "SRO1"}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// World with LAND1 transferred from C1 to C2 through T1, commented on by C1, and its admin
+func newWorld(t *testing.T) (*network.World, *network.Identity) {
+	w, err := network.New()
+	check(t, err)
+	admin, err := w.Admin()
+	check(t, err)
+
+	citizen, err := w.Citizen("C1")
+	check(t, err)
+	lawyer, err := w.Lawyer("L1")
+	check(t, err)
+	registryOfficer, err := w.RegistryOfficer("R1", office)
+	check(t, err)
+	blro, err := w.BLRO("B1", office)
+	check(t, err)
+	check(t, w.CreateLand(blro, "LAND1", "C1", office))
+
+	transfer := network.Transfer{ID: "T1", LandID: "LAND1", To: "C2", Citizen: citizen, Lawyer: lawyer, RegistryOfficer: registryOfficer, BLRO: blro}
+	check(t, w.RequestTransfer(transfer))
+	_, err = w.Call(citizen, "transfer_cc", "postComment", map[string]interface{}{"TransferRequestID": "T1", "ID": "M1", "Message": "Deed attached", "Date": w.Date()})
+	check(t, err)
+	check(t, w.ApproveTransfer(transfer))
+	return w, admin
+}
+
+func TestDumpAndImport(t *testing.T) {
+	w, admin := newWorld(t)
+	a, err := Dump(w.Session(admin), network.Chaincodes, 3)
+	check(t, err)
+	if len(a.Ledgers) != len(network.Chaincodes) || a.Height != uint64(w.Height()) {
+		t.Error("unexpected archive", a.Height, a.Chaincodes())
+	}
+
+	// The archive reads back as written, and not once altered
+	var written bytes.Buffer
+	check(t, a.Write(&written))
+	read, err := Read(bytes.NewReader(written.Bytes()))
+	check(t, err)
+	if read.Checksum != a.Checksum {
+		t.Error("archive read back with checksum", read.Checksum)
+	}
+	tampered := strings.Replace(written.String(), `"Height": `, `"Height": 1`, 1)
+	if _, err := Read(strings.NewReader(tampered)); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Error("tampered archive read", err)
+	}
+
+	// A fresh World seeded with the archive holds the same state, indexes and comments included
+	seeded, err := network.New()
+	check(t, err)
+	seededAdmin, err := seeded.Admin()
+	check(t, err)
+	var imported []string
+	err = Import(seeded.Session(seededAdmin), read, 4, func(chaincode string, n int) {
+		imported = append(imported, chaincode)
+	})
+	check(t, err)
+	if len(imported) == 0 {
+		t.Error("no progress reported")
+	}
+	for _, name := range network.Chaincodes {
+		if got, want := seeded.Stub(name).State, w.Stub(name).State; len(got) != len(want) {
+			t.Error(name, "seeded with", len(got), "keys, dumped", len(want))
+		} else {
+			for key, value := range want {
+				if !bytes.Equal(got[key], value) {
+					t.Errorf("%s seeded %q as %s", name, key, got[key])
+				}
+			}
+		}
+	}
+
+	citizen, err := seeded.Citizen("C2")
+	check(t, err)
+	page, err := seeded.Query(citizen, "transfer_cc", "queryTransferRequests", map[string]interface{}{"By": "to", "Value": "C2", "Complete": "all", "PageSize": 10})
+	check(t, err)
+	var requests struct{ Count int }
+	check(t, json.Unmarshal(page, &requests))
+	if requests.Count != 1 {
+		t.Error("seeded TransferRequests not indexed", string(page))
+	}
+}
+
+// Network committing a transaction while it is being dumped
+type busyNetwork struct {
+	*network.Session
+	submit func()
+}
+
+func (n busyNetwork) Query(chaincode string, fcn string, payload interface{}) ([]byte, error) {
+	n.submit()
+	return n.Session.Query(chaincode, fcn, payload)
+}
+
+func TestDumpRequiresQuietChain(t *testing.T) {
+	w, admin := newWorld(t)
+	blro, err := w.BLRO("B2", office)
+	check(t, err)
+	lands := 1
+	busy := busyNetwork{w.Session(admin), func() {
+		lands++
+		check(t, w.CreateLand(blro, "LAND"+string(rune('0'+lands)), "C1", office))
+	}}
+	if _, err := Dump(busy, network.Chaincodes, 100); err == nil || !strings.Contains(err.Error(), "while dumping") {
+		t.Error("dump of a busy chain succeeded", err)
+	}
+
+	citizen, err := w.Citizen("C1")
+	check(t, err)
+	if _, err := Dump(w.Session(citizen), network.Chaincodes, 100); err == nil || !strings.Contains(err.Error(), "ACCESS_DENIED") {
+		t.Error("dump by a citizen succeeded", err)
+	}
+}
@@ -0,0 +1,168 @@
+package network
+
+import (
+	"encoding/pem"
+	"testing"
+
+	"example.org/lib/envelope"
+)
+
+// Fixture with, besides T1 forwarded to B1, T3 of LAND2 with L1 and a clarification Q1 L1 asked for,
+// T4 of LAND3 declined by B1 and appealed as A1 for B2 to review, and professionals R3 and B3
+// licensed outside the office of the Land
+func newAuthorizationFixture(t *testing.T) (f *fixture, outsiders map[string]*Identity) {
+	f = newFixture(t)
+	check(t, f.RequestTransfer(f.transfer))
+
+	check(t, f.CreateLand(f.blros[0], "LAND2", "C1", office))
+	_, err := f.Call(f.citizen, "transfer_cc", "createTransferRequest", map[string]interface{}{"ID": "T3", "To": "C2", "LandID": "LAND2", "Assignee": "L1", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.lawyers[0], "transfer_cc", "requestClarification", map[string]interface{}{"TransferRequestID": "T3", "ID": "Q1", "Message": "Sale deed?", "Date": f.Date()})
+	check(t, err)
+
+	check(t, f.CreateLand(f.blros[0], "LAND3", "C1", office))
+	declined := Transfer{"T4", "LAND3", "C2", f.citizen, f.lawyers[0], f.registryOfficers[0], f.blros[0]}
+	check(t, f.RequestTransfer(declined))
+	_, err = f.Call(f.blros[0], "transfer_cc", "declineTransferRequest", map[string]interface{}{"ID": "T4", "Reason": "Unpaid stamp duty", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.citizen, "transfer_cc", "fileAppeal", map[string]interface{}{"ID": "A1", "TransferRequestID": "T4", "Grounds": "Duty paid", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.blros[0], "transfer_cc", "assignAppealReviewer", map[string]interface{}{"ID": "A1", "Reviewer": "B2", "Date": f.Date()})
+	check(t, err)
+
+	outsiders = map[string]*Identity{}
+	outsiders["R3"], err = f.RegistryOfficer("R3", Office{"KA", "BLR", "SRO2"})
+	check(t, err)
+	outsiders["B3"], err = f.BLRO("B3", Office{"KA", "MYS", "SRO9"})
+	check(t, err)
+
+	// A certificate enrolled for B1 with another key, as if B1's profileID were reissued to someone else
+	outsiders["B1"], err = f.Enroll("BLROMSP", "B1", map[string]string{"role": "blro", "profileID": "B1", "state": "KA", "district": "BLR"})
+	check(t, err)
+	return f, outsiders
+}
+
+// Call refused to a Tx Creator
+type refusal struct {
+	by        *Identity
+	chaincode string
+	fcn       string
+	payload   interface{}
+}
+
+func TestRoleAccess(t *testing.T) {
+	f, _ := newAuthorizationFixture(t)
+	citizen, lawyer, registryOfficer, blro := f.citizen, f.lawyers[0], f.registryOfficers[0], f.blros[0]
+	licence := Licence
+	licence.ID = "L2"
+	status := map[string]string{"ID": "L2", "Status": "suspended"}
+	date := f.Date()
+
+	refusals := []refusal{
+		{citizen, "land_cc", "createLand", map[string]interface{}{"ID": "LAND9", "Address": "9 MG Road", "Owner": "C1", "Date": date, "State": "KA", "District": "BLR", "OfficeID": "SRO1"}},
+		{blro, "land_cc", "setLandJurisdiction", map[string]interface{}{"ID": "LAND1", "State": "KA", "District": "BLR", "OfficeID": "SRO2"}},
+		{citizen, "lawyer_cc", "createLawyer", map[string]string{"ID": "C1", "Name": "Citizen", "CitizenID": "C1"}},
+		{blro, "lawyer_cc", "licenseLawyer", licence},
+		{lawyer, "lawyer_cc", "setLawyerStatus", status},
+		{lawyer, "registryoffice_cc", "createRegistryOfficer", map[string]string{"ID": "L1", "Name": "Lawyer", "CitizenID": "C9"}},
+		{registryOfficer, "registryoffice_cc", "licenseRegistryOfficer", licence},
+		{registryOfficer, "registryoffice_cc", "setRegistryOfficerStatus", status},
+		{registryOfficer, "blro_cc", "createBLRO", map[string]string{"ID": "R1", "Name": "Registry Officer"}},
+		{blro, "blro_cc", "licenseBLRO", licence},
+		{blro, "blro_cc", "setBLROStatus", status},
+		{lawyer, "transfer_cc", "createTransferRequest", map[string]interface{}{"ID": "T9", "To": "C2", "LandID": "LAND1", "Assignee": "L1", "Date": date}},
+		{lawyer, "transfer_cc", "reassignLawyer", map[string]interface{}{"ID": "T3", "Lawyer": "L2", "Date": date}},
+		{lawyer, "transfer_cc", "reassignRegistryOfficer", map[string]interface{}{"ID": "T1", "RegistryOfficer": "R2", "Date": date}},
+		{registryOfficer, "transfer_cc", "reassignBLRO", map[string]interface{}{"ID": "T1", "BLRO": "B2", "Date": date}},
+		{blro, "transfer_cc", "fileAppeal", map[string]interface{}{"ID": "A9", "TransferRequestID": "T4", "Grounds": "None", "Date": date}},
+		{citizen, "transfer_cc", "assignAppealReviewer", map[string]interface{}{"ID": "A1", "Reviewer": "B1", "Date": date}},
+		{citizen, "transfer_cc", "decideAppeal", map[string]interface{}{"ID": "A1", "Outcome": "overturned", "Date": date}},
+		{citizen, "transfer_cc", "createWorkflow", map[string]interface{}{"ID": "direct", "Stages": []map[string]string{}}},
+	}
+	for _, name := range Chaincodes {
+		refusals = append(refusals, refusal{blro, name, "updateIdentityMapping", map[string]interface{}{}})
+	}
+
+	for _, r := range refusals {
+		_, err := f.Call(r.by, r.chaincode, r.fcn, r.payload)
+		expectCode(t, err, envelope.CodeAccessDenied, r.fcn+" of "+r.chaincode+" by "+r.by.Name)
+	}
+}
+
+func TestChaincodeOnlyTransactions(t *testing.T) {
+	f, _ := newAuthorizationFixture(t)
+
+	// Transactions transfer_cc calls on behalf of the Tx Creator are refused to every Tx Creator proposing them directly
+	calls := []refusal{
+		{nil, "land_cc", "transferLand", map[string]interface{}{"ID": "LAND1", "CurrentOwner": "C2", "TransferDate": f.Date(), "TransferRequestID": "T1"}},
+	}
+	for _, p := range []struct{ chaincode, ID string }{{"lawyer_cc", "L1"}, {"registryoffice_cc", "R1"}, {"blro_cc", "B1"}} {
+		for _, fcn := range []string{"addCase", "completeCase", "removeCase"} {
+			calls = append(calls, refusal{nil, p.chaincode, fcn, map[string]string{"ID": p.ID, "CaseID": "T1"}})
+		}
+		changes := []map[string]string{{"Op": "complete", "CaseID": "T1"}}
+		calls = append(calls, refusal{nil, p.chaincode, "updateCases", map[string]interface{}{"ID": p.ID, "Changes": changes}})
+	}
+
+	for _, by := range []*Identity{f.admin, f.citizen, f.lawyers[0], f.registryOfficers[0], f.blros[0]} {
+		for _, r := range calls {
+			_, err := f.Call(by, r.chaincode, r.fcn, r.payload)
+			expected := `{"code":"ACCESS_DENIED","message":"Access Denied!","details":{"Chaincode":"` + r.chaincode + `"}}`
+			if err == nil || err.Error() != expected {
+				t.Error(r.fcn, "of", r.chaincode, "by", by.Name, "expected", expected, "got", err)
+			}
+		}
+	}
+}
+
+func TestRecordAccess(t *testing.T) {
+	f, outsiders := newAuthorizationFixture(t)
+	date := f.Date()
+	certificate := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.lawyers[0].Certificate.Raw}))
+
+	// Callers holding the role of a transaction, but not the record it acts on
+	refusals := []refusal{
+		{f.blros[0], "land_cc", "createLand", map[string]interface{}{"ID": "LAND9", "Address": "9 Sayyaji Rao Road", "Owner": "C1", "Date": date, "State": "KA", "District": "MYS", "OfficeID": "SRO9"}},
+		{f.lawyers[1], "lawyer_cc", "createLawyer", map[string]string{"ID": "L9", "Name": "Lawyer", "CitizenID": "C9"}},
+		{f.lawyers[1], "lawyer_cc", "rekeyLawyer", map[string]string{"ID": "L1", "Certificate": certificate}},
+		{f.buyer, "transfer_cc", "createTransferRequest", map[string]interface{}{"ID": "T9", "To": "C2", "LandID": "LAND2", "Assignee": "L1", "Date": date}},
+		{f.buyer, "transfer_cc", "reassignLawyer", map[string]interface{}{"ID": "T3", "Lawyer": "L2", "Date": date}},
+		{f.buyer, "transfer_cc", "fileAppeal", map[string]interface{}{"ID": "A9", "TransferRequestID": "T4", "Grounds": "None", "Date": date}},
+		{outsiders["R3"], "transfer_cc", "reassignRegistryOfficer", map[string]interface{}{"ID": "T1", "RegistryOfficer": "R2", "Date": date}},
+		{outsiders["B3"], "transfer_cc", "reassignBLRO", map[string]interface{}{"ID": "T1", "BLRO": "B2", "Date": date}},
+		{outsiders["B3"], "transfer_cc", "assignAppealReviewer", map[string]interface{}{"ID": "A1", "Reviewer": "B2", "Date": date}},
+		{f.blros[0], "transfer_cc", "decideAppeal", map[string]interface{}{"ID": "A1", "Outcome": "overturned", "Date": date}},
+	}
+
+	// Stage actions by anyone but the Assignee of the current stage, with the certificate bound to their Profile
+	refusals = append(refusals,
+		refusal{f.lawyers[1], "transfer_cc", "transfer2RegistryOfficer", map[string]interface{}{"ID": "T3", "RegistryOfficer": "R1", "Date": date}},
+		refusal{f.lawyers[1], "transfer_cc", "autoTransfer2RegistryOfficer", map[string]interface{}{"ID": "T3", "Date": date}},
+		refusal{f.registryOfficers[0], "transfer_cc", "transfer2BLRO", map[string]interface{}{"ID": "T3", "BLRO": "B1", "Date": date}},
+		refusal{f.citizen, "transfer_cc", "advanceTransferRequest", map[string]interface{}{"ID": "T3", "Assignee": "R1", "Date": date}},
+		refusal{f.citizen, "transfer_cc", "addArtefact", map[string]interface{}{"ID": "T3", "Name": "deed", "Reference": "sha256:00", "Date": date}},
+		refusal{f.registryOfficers[0], "transfer_cc", "approveTransferRequest", map[string]interface{}{"ID": "T1", "Date": date}},
+		refusal{f.blros[1], "transfer_cc", "approveTransferRequest", map[string]interface{}{"ID": "T1", "Date": date}},
+		refusal{outsiders["B1"], "transfer_cc", "approveTransferRequest", map[string]interface{}{"ID": "T1", "Date": date}},
+		refusal{f.blros[1], "transfer_cc", "declineTransferRequest", map[string]interface{}{"ID": "T1", "Reason": "None", "Date": date}},
+	)
+
+	// Comment threads are open to the parties of the TransferRequest, and clarifications resolved by their author,
+	// the holder of the author's stage or the requester
+	refusals = append(refusals,
+		refusal{f.lawyers[1], "transfer_cc", "postComment", map[string]interface{}{"TransferRequestID": "T3", "ID": "M9", "Message": "Hello", "Date": date}},
+		refusal{f.lawyers[1], "transfer_cc", "listComments", map[string]interface{}{"TransferRequestID": "T3", "PageSize": 10}},
+		refusal{f.buyer, "transfer_cc", "requestClarification", map[string]interface{}{"TransferRequestID": "T3", "ID": "Q9", "Message": "Why?", "Date": date}},
+		refusal{f.buyer, "transfer_cc", "resolveClarification", map[string]interface{}{"TransferRequestID": "T3", "ID": "Q1", "Date": date}},
+	)
+
+	for _, r := range refusals {
+		_, err := f.Call(r.by, r.chaincode, r.fcn, r.payload)
+		expectCode(t, err, envelope.CodeAccessDenied, r.fcn+" of "+r.chaincode+" by "+r.by.Name)
+	}
+
+	// The refused calls changed nothing
+	if request := f.transferRequest(t, "T1"); request.Complete || request.Declined || request.BLRO != "B1" || len(request.Reassignments) != 0 {
+		t.Error("refused calls changed TransferRequest T1", request)
+	}
+}
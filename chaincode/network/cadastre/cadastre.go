@@ -0,0 +1,247 @@
+// Package cadastre onboards the parcels of a cadastral CSV as Land, a chunk of rows per createLands
+// transaction of land_cc, reporting every row rejected and why. Onboarding is resumed past the rows
+// of the chunks already committed.
+package cadastre
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"example.org/lib/envelope"
+)
+
+// Columns of a cadastral CSV, matched against its header regardless of case, spaces, dashes and underscores.
+// ID, Address and Owner are required. State, District and OfficeID, if given, override those of the Options.
+var Columns = []string{"ID", "Address", "Owner", "SurveyNumber", "Area", "Geometry", "State", "District", "OfficeID"}
+
+// Network is what the parcels are onboarded into, by a BLRO of their district
+type Network interface {
+	// Submit fcn of chaincode with payload as its argument, giving its response once the transaction is committed
+	Submit(chaincode string, fcn string, payload interface{}) ([]byte, error)
+}
+
+// Options of an onboarding: the jurisdiction of parcels the CSV does not place in one, the Unix time the
+// Lands are registered at, the rows per createLands transaction, and the rows already onboarded to skip
+type Options struct {
+	State     string
+	District  string
+	OfficeID  string
+	Date      int
+	ChunkSize int
+	Skip      int
+}
+
+// Failure is a row of the CSV rejected, either before it was sent or by createLands, with its envelope.
+// Row 1 is the first after the header.
+type Failure struct {
+	Row     int
+	ID      string
+	Code    string
+	Message string
+}
+
+// Report of an onboarding: the Rows processed, skipped ones included, the Lands Created and the rows rejected
+type Report struct {
+	Rows     int
+	Created  int
+	Failures []Failure
+}
+
+// Onboard the parcels of the CSV read from r into the network, calling committed, if set, after each chunk
+// with the report so far and the failures of the chunk. An error of committed stops the onboarding, which is
+// then resumed by skipping the Rows reported to it.
+func Onboard(n Network, r io.Reader, o Options, committed func(report *Report, failures []Failure) error) (*Report, error) {
+	if o.ChunkSize < 1 || o.ChunkSize > 500 {
+		return nil, fmt.Errorf("chunk size %d is not within 1 to 500", o.ChunkSize)
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %v", err)
+	}
+	columns, err := columnsOf(header)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Failures: []Failure{}}
+	var chunk []parcel
+	var failures []Failure
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return report, err
+		}
+		row := report.Rows + len(chunk) + len(failures) + 1
+		if row <= o.Skip {
+			report.Rows++
+			continue
+		}
+
+		p, err := parcelOf(record, columns, len(header), o)
+		if err != nil {
+			failures = append(failures, Failure{row, p.args["ID"].(string), envelope.CodeInvalidArgument, err.Error()})
+		} else {
+			p.row = row
+			chunk = append(chunk, p)
+		}
+
+		if len(chunk)+len(failures) == o.ChunkSize {
+			err = submit(n, report, chunk, failures, committed)
+			if err != nil {
+				return report, err
+			}
+			chunk, failures = nil, nil
+		}
+	}
+
+	if len(chunk) > 0 || len(failures) > 0 {
+		err = submit(n, report, chunk, failures, committed)
+	}
+	return report, err
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Parcel of a row, as the createLand payload it is sent as
+type parcel struct {
+	row  int
+	args map[string]interface{}
+}
+
+// Outcome of createLands, as land_cc responds with it
+type createLandsResult struct {
+	Created int
+	Failed  int
+	Results []struct {
+		Row   int
+		ID    string
+		Error json.RawMessage
+	}
+}
+
+// Submit the parcels of chunk through createLands, adding them and the failures of the rows before them
+// that were not sent to the report
+func submit(n Network, report *Report, chunk []parcel, failures []Failure, committed func(*Report, []Failure) error) error {
+	rows := len(chunk) + len(failures)
+	if len(chunk) > 0 {
+		lands := make([]map[string]interface{}, len(chunk))
+		for i, p := range chunk {
+			lands[i] = p.args
+		}
+		resultAsBytes, err := n.Submit("land_cc", "createLands", map[string]interface{}{"Lands": lands})
+		if err != nil {
+			return fmt.Errorf("onboarding rows %d to %d: %v", chunk[0].row, chunk[len(chunk)-1].row, err)
+		}
+		var result createLandsResult
+		err = json.Unmarshal(resultAsBytes, &result) //unmarshal it aka JSON.parse()
+		if err != nil {
+			return fmt.Errorf("onboarding rows %d to %d: %v", chunk[0].row, chunk[len(chunk)-1].row, err)
+		} else if len(result.Results) != len(chunk) {
+			return fmt.Errorf("onboarding rows %d to %d: %d results for %d parcels", chunk[0].row, chunk[len(chunk)-1].row, len(result.Results), len(chunk))
+		}
+
+		report.Created += result.Created
+		for i, r := range result.Results {
+			if r.Error != nil {
+				e := envelope.Parse(string(r.Error))
+				failures = append(failures, Failure{chunk[i].row, r.ID, e.Code, messageOf(e)})
+			}
+		}
+	}
+
+	// Rows rejected before they were sent come first, so the failures are put back in row order
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Row < failures[j].Row
+	})
+	report.Rows += rows
+	report.Failures = append(report.Failures, failures...)
+	if committed != nil {
+		return committed(report, failures)
+	}
+	return nil
+}
+
+// Index of each of the Columns in header, -1 if missing
+func columnsOf(header []string) (map[string]int, error) {
+	columns := map[string]int{}
+	for _, column := range Columns {
+		columns[column] = -1
+	}
+	for i, name := range header {
+		name = strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+		for _, column := range Columns {
+			if name == strings.ToLower(column) {
+				columns[column] = i
+			}
+		}
+	}
+	for _, column := range []string{"ID", "Address", "Owner"} {
+		if columns[column] < 0 {
+			return nil, fmt.Errorf("header %v has no %s column", header, column)
+		}
+	}
+	return columns, nil
+}
+
+// Parcel of a record of a CSV of width columns, its ID set even if it is invalid
+func parcelOf(record []string, columns map[string]int, width int, o Options) (parcel, error) {
+	field := func(column string) string {
+		if i := columns[column]; i >= 0 && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	or := func(value string, fallback string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	}
+
+	p := parcel{args: map[string]interface{}{
+		"ID":           field("ID"),
+		"Address":      field("Address"),
+		"Owner":        field("Owner"),
+		"Date":         o.Date,
+		"State":        or(field("State"), o.State),
+		"District":     or(field("District"), o.District),
+		"OfficeID":     or(field("OfficeID"), o.OfficeID),
+		"SurveyNumber": field("SurveyNumber"),
+		"Geometry":     field("Geometry"),
+	}}
+	if len(record) != width {
+		return p, fmt.Errorf("row has %d fields, expecting %d", len(record), width)
+	}
+	if area := field("Area"); area != "" {
+		value, err := strconv.ParseFloat(area, 64)
+		if err != nil {
+			return p, fmt.Errorf("Area %q is not a number", area)
+		}
+		p.args["Area"] = value
+	}
+	return p, nil
+}
+
+// Message of e, followed by its details, e.g. Invalid argument (Area: must be at least 0)
+func messageOf(e *envelope.Error) string {
+	var details []string
+	for i := 0; i+1 < len(e.Details); i += 2 {
+		details = append(details, e.Details[i]+": "+e.Details[i+1])
+	}
+	if len(details) == 0 {
+		return e.Message
+	}
+	return e.Message + " (" + strings.Join(details, ", ") + ")"
+}
@@ -0,0 +1,85 @@
+package cadastre
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"example.org/lib/envelope"
+	"example.org/network"
+)
+
+var office = network.Office{State: "KA", District: "BLR", OfficeID: "SRO1"}
+
+const parcels = `Id,Address,Owner,Survey Number,Area,Geometry
+L1,1 MG Road,C1,12/3,240.5,"POLYGON((0 0,0 1,1 1,0 0))"
+L2,2 MG Road,C1,12/4,big,
+L3,3 MG Road,C2,12/5,-5,
+L4,4 MG Road,C2
+L1,1 MG Road,C1,12/3,240.5,
+L5,5 MG Road,C3,12/6,100,
+L6,6 MG Road,C3,12/7,100,
+`
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOnboard(t *testing.T) {
+	w, err := network.New()
+	check(t, err)
+	blro, err := w.BLRO("B1", office)
+	check(t, err)
+	options := Options{State: office.State, District: office.District, OfficeID: office.OfficeID, Date: w.Date(), ChunkSize: 2}
+
+	// Interrupted after its second chunk, the onboarding has processed four rows
+	var chunks []int
+	interrupted := errors.New("interrupted")
+	report, err := Onboard(w.Session(blro), strings.NewReader(parcels), options, func(report *Report, failures []Failure) error {
+		chunks = append(chunks, report.Rows)
+		if len(chunks) == 2 {
+			return interrupted
+		}
+		return nil
+	})
+	if err != interrupted || report.Rows != 4 || report.Created != 1 || len(report.Failures) != 3 {
+		t.Fatal("unexpected interrupted onboarding", err, report)
+	}
+
+	// Resumed past them, the rest are onboarded without conflicting with those already created but the repeated L1
+	options.Skip = report.Rows
+	resumed, err := Onboard(w.Session(blro), strings.NewReader(parcels), options, nil)
+	check(t, err)
+	if resumed.Rows != 7 || resumed.Created != 2 || len(resumed.Failures) != 1 {
+		t.Fatal("unexpected resumed onboarding", resumed)
+	}
+
+	failures := append(report.Failures, resumed.Failures...)
+	expected := []Failure{{2, "L2", envelope.CodeInvalidArgument, ""}, {3, "L3", envelope.CodeInvalidArgument, ""}, {4, "L4", envelope.CodeInvalidArgument, ""}, {5, "L1", envelope.CodeConflict, ""}}
+	for i, f := range expected {
+		if failures[i].Row != f.Row || failures[i].ID != f.ID || failures[i].Code != f.Code || failures[i].Message == "" {
+			t.Error("expected", f, "got", failures[i])
+		}
+	}
+
+	var land struct {
+		SurveyNumber string
+		Area         float64
+		Geometry     string
+	}
+	check(t, w.Read(blro, "land_cc", "readLand", "L1", &land))
+	if land.SurveyNumber != "12/3" || land.Area != 240.5 || land.Geometry != "POLYGON((0 0,0 1,1 1,0 0))" {
+		t.Error("unexpected land", land)
+	}
+	check(t, w.Read(blro, "land_cc", "readLand", "L6", &land))
+}
+
+func TestOnboardRequiresColumns(t *testing.T) {
+	_, err := Onboard(nil, strings.NewReader("ID,Address\nL1,1 MG Road\n"), Options{ChunkSize: 10}, nil)
+	if err == nil || !strings.Contains(err.Error(), "Owner") {
+		t.Error("CSV without Owner onboarded", err)
+	}
+}
@@ -0,0 +1,112 @@
+// Package client calls the transactions of the land registry chaincodes with typed methods, taking the
+// payloads and giving the records the chaincodes themselves use, e.g.
+//
+//	c := client.New(gateway)
+//	err := c.Transfer.CreateTransferRequest(transfer.CreateTransferRequestArgs{ID: "T1", To: "C2", LandID: "LAND1", Assignee: "L1", Date: now})
+//	request, err := c.Transfer.ReadTransferRequest("T1")
+//
+// Transactions are sent through a Network: a peer.Gateway to a Fabric network, or a network.Session
+// proposing them to the chaincodes running in memory, for tests. Rejected transactions give the envelope
+// the chaincode rejected them with as error, so envelope.CodeOf tells why.
+package client
+
+import (
+	"encoding/json"
+
+	"example.org/lib/contract"
+	"example.org/lib/identity"
+)
+
+// Network the transactions are sent through, as the identity it was set up with
+type Network interface {
+	// Query evaluates fcn of chaincode with payload as its argument, or none if nil, without submitting a transaction
+	Query(chaincode string, fcn string, payload interface{}) ([]byte, error)
+	// Submit fcn of chaincode with payload as its argument, or none if nil, giving its response once the transaction is committed
+	Submit(chaincode string, fcn string, payload interface{}) ([]byte, error)
+}
+
+// Client calls the transactions of each chaincode
+type Client struct {
+	Land           *LandChaincode
+	Lawyer         *LawyerChaincode
+	RegistryOffice *RegistryOfficeChaincode
+	BLRO           *BLROChaincode
+	Transfer       *TransferChaincode
+}
+
+// New Client sending transactions through n
+func New(n Network) *Client {
+	return &Client{
+		Land:           &LandChaincode{Chaincode{n, "land_cc"}},
+		Lawyer:         &LawyerChaincode{ProfessionalChaincode{Chaincode{n, "lawyer_cc"}}},
+		RegistryOffice: &RegistryOfficeChaincode{ProfessionalChaincode{Chaincode{n, "registryoffice_cc"}}},
+		BLRO:           &BLROChaincode{ProfessionalChaincode{Chaincode{n, "blro_cc"}}},
+		Transfer:       &TransferChaincode{Chaincode{n, "transfer_cc"}},
+	}
+}
+
+// Chaincode calls the transactions every chaincode has, on the role => identity mapping it authenticates
+// against and on its ledger
+type Chaincode struct {
+	n    Network
+	name string
+}
+
+// Name of the chaincode, e.g. land_cc
+func (c *Chaincode) Name() string {
+	return c.name
+}
+
+// ReadIdentityMapping reads the role => identity mapping
+func (c *Chaincode) ReadIdentityMapping() (map[string]identity.Identity, error) {
+	mapping := map[string]identity.Identity{}
+	return mapping, c.query("readIdentityMapping", nil, &mapping)
+}
+
+// UpdateIdentityMapping replaces the role => identity mapping, only by an admin of the current mapping
+func (c *Chaincode) UpdateIdentityMapping(mapping map[string]identity.Identity) error {
+	mappingJSONasBytes, err := json.Marshal(mapping)
+	if err != nil {
+		return err
+	}
+	// The mapping is the argument itself, not a payload holding it
+	return c.submit("updateIdentityMapping", json.RawMessage(mappingJSONasBytes), nil)
+}
+
+// DumpState reads a page of the ledger, only by an admin
+func (c *Chaincode) DumpState(args contract.DumpArgs) (*contract.LedgerPage, error) {
+	page := &contract.LedgerPage{}
+	return page, c.query("dumpState", args, page)
+}
+
+// ImportState writes the Records of a dump to the ledger, only by an admin
+func (c *Chaincode) ImportState(args contract.ImportArgs) error {
+	return c.submit("importState", args, nil)
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Evaluate fcn with payload, unmarshalling its response into v
+func (c *Chaincode) query(fcn string, payload interface{}, v interface{}) error {
+	responseAsBytes, err := c.n.Query(c.name, fcn, payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(responseAsBytes, v) //unmarshal it aka JSON.parse()
+}
+
+// Submit fcn with payload, unmarshalling its response into v unless nil
+func (c *Chaincode) submit(fcn string, payload interface{}, v interface{}) error {
+	responseAsBytes, err := c.n.Submit(c.name, fcn, payload)
+	if err != nil || v == nil {
+		return err
+	}
+	return json.Unmarshal(responseAsBytes, v) //unmarshal it aka JSON.parse()
+}
+
+// Payload of the transactions reading a record by its ID
+func idArgs(ID string) contract.IDArgs {
+	return contract.IDArgs{ID: ID}
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"example.org/blro_cc/blro"
+	"example.org/land_cc/land"
+	"example.org/lawyer_cc/lawyer"
+	"example.org/lib/contract"
+	"example.org/lib/envelope"
+	"example.org/network"
+	"example.org/network/peer"
+	"example.org/registryoffice_cc/registryoffice"
+	"example.org/transfer_cc/transfer"
+)
+
+// A Fabric network through fabric-sdk-go, and the chaincodes in memory, are both Networks
+var (
+	_ Network = (*peer.Gateway)(nil)
+	_ Network = (*network.Session)(nil)
+)
+
+// Office of the Land and professionals of the tests
+var office = network.Office{State: "KA", District: "BLR", OfficeID: "SRO1"}
+
+// Fail the test on err
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTransactions(t *testing.T) {
+	c := New(nil)
+	clients := []struct {
+		new    func() (*contract.Chaincode, error)
+		client interface{ Name() string }
+	}{
+		{land.New, c.Land},
+		{lawyer.New, c.Lawyer},
+		{registryoffice.New, c.RegistryOffice},
+		{blro.New, c.BLRO},
+		{transfer.New, c.Transfer},
+	}
+
+	// Each transaction of a chaincode has a method of the same name, and each method is a transaction
+	for _, client := range clients {
+		cc, err := client.new()
+		check(t, err)
+		methods := map[string]bool{}
+		clientType := reflect.TypeOf(client.client)
+		for i := 0; i < clientType.NumMethod(); i++ {
+			methods[clientType.Method(i).Name] = true
+		}
+		delete(methods, "Name")
+
+		for _, name := range cc.Transactions() {
+			method := strings.ToUpper(name[:1]) + name[1:]
+			if !methods[method] {
+				t.Error(cc.Name, "has no method for", name)
+			}
+			delete(methods, method)
+		}
+		for method := range methods {
+			t.Error(cc.Name, "has no transaction for", method)
+		}
+		if client.client.Name() != cc.Name {
+			t.Error("client of", cc.Name, "calls", client.client.Name())
+		}
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	w, err := network.New()
+	check(t, err)
+	identities := map[string]*network.Identity{}
+	for ID, enroll := range map[string]func(string) (*network.Identity, error){
+		"C1": w.Citizen,
+		"C2": w.Citizen,
+		"L1": w.Lawyer,
+		"R1": func(ID string) (*network.Identity, error) { return w.RegistryOfficer(ID, office) },
+		"B1": func(ID string) (*network.Identity, error) { return w.BLRO(ID, office) },
+	} {
+		identities[ID], err = enroll(ID)
+		check(t, err)
+	}
+	as := func(ID string) *Client {
+		return New(w.Session(identities[ID]))
+	}
+
+	// Lands rejected by createLands are reported by row, while the others are created
+	newLand := land.CreateLandArgs{ID: "LAND1", Address: "12 MG Road", Owner: "C1", Date: w.Date(), State: office.State, District: office.District, OfficeID: office.OfficeID, Area: 120.5}
+	invalid := newLand
+	invalid.ID, invalid.Owner = "LAND2", ""
+	result, err := as("B1").Land.CreateLands([]land.CreateLandArgs{newLand, invalid})
+	check(t, err)
+	if result.Created != 1 || result.Failed != 1 || result.Results[1].ID != "LAND2" || envelope.CodeOf(envelope.Parse(string(result.Results[1].Error))) != envelope.CodeInvalidArgument {
+		t.Error("unexpected createLands result", result)
+	}
+
+	// The transfer moves through its Workflow with the payloads of each Stage
+	c1 := as("C1").Transfer
+	check(t, c1.CreateTransferRequest(transfer.CreateTransferRequestArgs{ID: "T1", To: "C2", LandID: "LAND1", Assignee: "L1", Date: w.Date()}))
+	check(t, c1.PostComment(transfer.CommentArgs{TransferRequestID: "T1", ID: "M1", Message: "Deed attached", Date: w.Date()}))
+	check(t, as("L1").Transfer.AutoTransfer2RegistryOfficer(transfer.DateArgs{ID: "T1", Date: w.Date()}))
+	check(t, as("R1").Transfer.Transfer2BLRO(transfer.BLROArgs{ID: "T1", BLRO: "B1", Date: w.Date()}))
+	check(t, as("B1").Transfer.ApproveTransferRequest(transfer.DateArgs{ID: "T1", Date: w.Date()}))
+
+	request, err := c1.ReadTransferRequest("T1")
+	check(t, err)
+	if !request.Complete || request.RegistryOfficer != "R1" || request.Stage != "blro" || len(request.StatusHistory) != 4 {
+		t.Error("unexpected TransferRequest", request)
+	}
+	comments, err := c1.ListComments(transfer.ThreadArgs{TransferRequestID: "T1", PageSize: 10})
+	check(t, err)
+	if comments.Count != 1 || comments.Records[0].Author != "C1" || comments.Records[0].Message != "Deed attached" {
+		t.Error("unexpected comments", comments)
+	}
+	l, err := as("C2").Land.ReadLand("LAND1")
+	check(t, err)
+	if l.Owner != "C2" || l.Area != 120.5 || len(l.History) != 2 || l.History[1].TransferRequestID != "T1" {
+		t.Error("unexpected Land", l)
+	}
+	lawyer, err := as("C1").Lawyer.ReadLawyer("L1")
+	check(t, err)
+	if len(lawyer.CompletedCases) != 1 || lawyer.CompletedCases[0] != "T1" {
+		t.Error("unexpected Lawyer", lawyer)
+	}
+
+	// Rejections give their envelope
+	_, err = as("C1").Land.ReadLand("LAND2")
+	if envelope.CodeOf(err) != envelope.CodeNotFound {
+		t.Error("expected LAND2 not to exist, got", err)
+	}
+	err = as("C2").Transfer.CreateTransferRequest(transfer.CreateTransferRequestArgs{ID: "T2", To: "C1", LandID: "LAND1", Assignee: "L1", Date: w.Date()})
+	if err != nil {
+		t.Error("new owner could not request a transfer", err)
+	}
+	if err = c1.ApproveTransferRequest(transfer.DateArgs{ID: "T2", Date: w.Date()}); envelope.CodeOf(err) != envelope.CodeAccessDenied {
+		t.Error("approval by a citizen expected ACCESS_DENIED, got", err)
+	}
+}
+
+func TestAdministration(t *testing.T) {
+	w, err := network.New()
+	check(t, err)
+	admin, err := w.Admin()
+	check(t, err)
+	c := New(w.Session(admin))
+
+	// Transactions without arguments are called with none
+	mapping, err := c.Land.ReadIdentityMapping()
+	check(t, err)
+	if _, ok := mapping["blro"]; !ok {
+		t.Error("unexpected mapping", mapping)
+	}
+	check(t, c.Land.UpdateIdentityMapping(mapping))
+
+	b1, err := w.BLRO("B1", office)
+	check(t, err)
+	check(t, New(w.Session(b1)).Land.CreateLand(land.CreateLandArgs{ID: "LAND1", Address: "12 MG Road", Owner: "C1", Date: w.Date(), State: office.State, District: office.District, OfficeID: office.OfficeID}))
+	page, err := c.Land.DumpState(contract.DumpArgs{PageSize: 100})
+	check(t, err)
+	found := false
+	for _, r := range page.Records {
+		found = found || r.Key == "land-LAND1"
+	}
+	if !found {
+		t.Error("LAND1 not dumped", page)
+	}
+
+	blro, err := c.BLRO.ReadBLRO("B1")
+	check(t, err)
+	if blro.Status != "active" {
+		t.Error("unexpected BLRO", blro)
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"encoding/json"
+
+	"example.org/land_cc/land"
+)
+
+// LandChaincode calls the transactions of land_cc
+type LandChaincode struct {
+	Chaincode
+}
+
+// CreateLand registers Land, by a BLRO of its district
+func (c *LandChaincode) CreateLand(args land.CreateLandArgs) error {
+	return c.submit("createLand", args, nil)
+}
+
+// CreateLands registers Lands in one transaction, by a BLRO of their district, giving the outcome of each:
+// those rejected are reported in their LandResult, while the others are created
+func (c *LandChaincode) CreateLands(lands []land.CreateLandArgs) (*land.CreateLandsResult, error) {
+	args := land.CreateLandsArgs{Lands: []json.RawMessage{}}
+	for _, l := range lands {
+		landJSONasBytes, err := json.Marshal(l)
+		if err != nil {
+			return nil, err
+		}
+		args.Lands = append(args.Lands, landJSONasBytes)
+	}
+
+	result := &land.CreateLandsResult{}
+	return result, c.submit("createLands", args, result)
+}
+
+// ReadLand reads the Land with ID
+func (c *LandChaincode) ReadLand(ID string) (*land.Land, error) {
+	l := &land.Land{}
+	return l, c.query("readLand", idArgs(ID), l)
+}
+
+// TransferLand moves Land to its CurrentOwner, only called by transfer_cc as it approves a TransferRequest
+func (c *LandChaincode) TransferLand(args land.TransferLandArgs) error {
+	return c.submit("transferLand", args, nil)
+}
+
+// SetLandJurisdiction places Land in the jurisdiction of an office, by an admin
+func (c *LandChaincode) SetLandJurisdiction(args land.JurisdictionArgs) error {
+	return c.submit("setLandJurisdiction", args, nil)
+}
+
+// GetLands lists the Lands of owner, matched exactly
+func (c *LandChaincode) GetLands(owner string) ([]land.Land, error) {
+	var records []struct {
+		Key   string    `json:"Key"`
+		Value land.Land `json:"Value"`
+	}
+	err := c.query("getLands", land.OwnerArgs{Owner: owner}, &records)
+	if err != nil {
+		return nil, err
+	}
+
+	lands := []land.Land{}
+	for _, r := range records {
+		lands = append(lands, r.Value)
+	}
+	return lands, nil
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"example.org/blro_cc/blro"
+	"example.org/lawyer_cc/lawyer"
+	"example.org/lib/cases"
+	"example.org/lib/registry"
+	"example.org/registryoffice_cc/registryoffice"
+)
+
+// ProfessionalChaincode calls the transactions on cases every chaincode of professionals has,
+// only called by transfer_cc as TransferRequests move through their Stages
+type ProfessionalChaincode struct {
+	Chaincode
+}
+
+// AddCase adds the active case CaseID to the professional with ID
+func (c *ProfessionalChaincode) AddCase(args cases.Args) error {
+	return c.submit("addCase", args, nil)
+}
+
+// CompleteCase moves the case CaseID of the professional with ID from active to completed
+func (c *ProfessionalChaincode) CompleteCase(args cases.Args) error {
+	return c.submit("completeCase", args, nil)
+}
+
+// RemoveCase removes the active case CaseID from the professional with ID
+func (c *ProfessionalChaincode) RemoveCase(args cases.Args) error {
+	return c.submit("removeCase", args, nil)
+}
+
+// UpdateCases applies the Changes to the cases of the professional with ID in order
+func (c *ProfessionalChaincode) UpdateCases(args cases.UpdateArgs) error {
+	return c.submit("updateCases", args, nil)
+}
+
+// ---------------------------------------------
+// lawyer_cc
+// ---------------------------------------------
+
+// LawyerChaincode calls the transactions of lawyer_cc
+type LawyerChaincode struct {
+	ProfessionalChaincode
+}
+
+// CreateLawyer creates the Profile of a Lawyer, only by the certificate enrolled for it
+func (c *LawyerChaincode) CreateLawyer(args lawyer.CreateLawyerArgs) error {
+	return c.submit("createLawyer", args, nil)
+}
+
+// ReadLawyer reads the Lawyer with ID
+func (c *LawyerChaincode) ReadLawyer(ID string) (*lawyer.Lawyer, error) {
+	l := &lawyer.Lawyer{}
+	return l, c.query("readLawyer", idArgs(ID), l)
+}
+
+// RekeyLawyer binds the Profile with ID to a renewed Certificate, by its bound certificate or an admin
+func (c *LawyerChaincode) RekeyLawyer(args registry.RekeyArgs) error {
+	return c.submit("rekeyLawyer", args, nil)
+}
+
+// LicenseLawyer records the licence of a Lawyer, by an admin
+func (c *LawyerChaincode) LicenseLawyer(args registry.LicenceArgs) error {
+	return c.submit("licenseLawyer", args, nil)
+}
+
+// SetLawyerStatus suspends, revokes or reinstates a Lawyer, by an admin
+func (c *LawyerChaincode) SetLawyerStatus(args registry.StatusArgs) error {
+	return c.submit("setLawyerStatus", args, nil)
+}
+
+// ---------------------------------------------
+// registryoffice_cc
+// ---------------------------------------------
+
+// RegistryOfficeChaincode calls the transactions of registryoffice_cc
+type RegistryOfficeChaincode struct {
+	ProfessionalChaincode
+}
+
+// CreateRegistryOfficer creates the Profile of a RegistryOfficer, only by the certificate enrolled for it, at its office
+func (c *RegistryOfficeChaincode) CreateRegistryOfficer(args registryoffice.CreateRegistryOfficerArgs) error {
+	return c.submit("createRegistryOfficer", args, nil)
+}
+
+// ReadRegistryOfficer reads the RegistryOfficer with ID
+func (c *RegistryOfficeChaincode) ReadRegistryOfficer(ID string) (*registryoffice.RegistryOfficer, error) {
+	r := &registryoffice.RegistryOfficer{}
+	return r, c.query("readRegistryOfficer", idArgs(ID), r)
+}
+
+// RekeyRegistryOfficer binds the Profile with ID to a renewed Certificate, by its bound certificate or an admin
+func (c *RegistryOfficeChaincode) RekeyRegistryOfficer(args registry.RekeyArgs) error {
+	return c.submit("rekeyRegistryOfficer", args, nil)
+}
+
+// LicenseRegistryOfficer records the licence of a RegistryOfficer, by an admin
+func (c *RegistryOfficeChaincode) LicenseRegistryOfficer(args registry.LicenceArgs) error {
+	return c.submit("licenseRegistryOfficer", args, nil)
+}
+
+// SetRegistryOfficerStatus suspends, revokes or reinstates a RegistryOfficer, by an admin
+func (c *RegistryOfficeChaincode) SetRegistryOfficerStatus(args registry.StatusArgs) error {
+	return c.submit("setRegistryOfficerStatus", args, nil)
+}
+
+// GetLeastBusyRegistryOfficer picks the licensed RegistryOfficer of an office with the fewest ActiveCases
+func (c *RegistryOfficeChaincode) GetLeastBusyRegistryOfficer(args registryoffice.OfficeArgs) (*registryoffice.RegistryOfficer, error) {
+	r := &registryoffice.RegistryOfficer{}
+	return r, c.query("getLeastBusyRegistryOfficer", args, r)
+}
+
+// ---------------------------------------------
+// blro_cc
+// ---------------------------------------------
+
+// BLROChaincode calls the transactions of blro_cc
+type BLROChaincode struct {
+	ProfessionalChaincode
+}
+
+// CreateBLRO creates the Profile of a BLRO, only by the certificate enrolled for it
+func (c *BLROChaincode) CreateBLRO(args blro.CreateBLROArgs) error {
+	return c.submit("createBLRO", args, nil)
+}
+
+// ReadBLRO reads the BLRO with ID
+func (c *BLROChaincode) ReadBLRO(ID string) (*blro.BLRO, error) {
+	b := &blro.BLRO{}
+	return b, c.query("readBLRO", idArgs(ID), b)
+}
+
+// RekeyBLRO binds the Profile with ID to a renewed Certificate, by its bound certificate or an admin
+func (c *BLROChaincode) RekeyBLRO(args registry.RekeyArgs) error {
+	return c.submit("rekeyBLRO", args, nil)
+}
+
+// LicenseBLRO records the licence of a BLRO, by an admin
+func (c *BLROChaincode) LicenseBLRO(args registry.LicenceArgs) error {
+	return c.submit("licenseBLRO", args, nil)
+}
+
+// SetBLROStatus suspends, revokes or reinstates a BLRO, by an admin
+func (c *BLROChaincode) SetBLROStatus(args registry.StatusArgs) error {
+	return c.submit("setBLROStatus", args, nil)
+}
+
+// SetBLRORank ranks a BLRO junior or senior, by an admin; only senior BLROs review appeals
+func (c *BLROChaincode) SetBLRORank(args blro.RankArgs) error {
+	return c.submit("setBLRORank", args, nil)
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"example.org/transfer_cc/transfer"
+)
+
+// TransferChaincode calls the transactions of transfer_cc
+type TransferChaincode struct {
+	Chaincode
+}
+
+// TransferRequestPage is a page of queryTransferRequests, with the Bookmark of the next page
+type TransferRequestPage struct {
+	Records  []transfer.TransferRequest `json:"Records"`
+	Count    int                        `json:"Count"`
+	Bookmark string                     `json:"Bookmark"`
+}
+
+// CommentPage is a page of listComments, with the Bookmark of the next page
+type CommentPage struct {
+	Records  []transfer.Comment `json:"Records"`
+	Count    int                `json:"Count"`
+	Bookmark string             `json:"Bookmark"`
+}
+
+// CreateTransferRequest requests the transfer of Land of the citizen proposing it, assigned to the
+// professional of the first Stage of its Workflow
+func (c *TransferChaincode) CreateTransferRequest(args transfer.CreateTransferRequestArgs) error {
+	return c.submit("createTransferRequest", args, nil)
+}
+
+// ReadTransferRequest reads the TransferRequest with ID
+func (c *TransferChaincode) ReadTransferRequest(ID string) (*transfer.TransferRequest, error) {
+	t := &transfer.TransferRequest{}
+	return t, c.query("readTransferRequest", idArgs(ID), t)
+}
+
+// AdvanceTransferRequest forwards a TransferRequest to the next Stage of its Workflow, assigned to Assignee
+func (c *TransferChaincode) AdvanceTransferRequest(args transfer.AssigneeArgs) error {
+	return c.submit("advanceTransferRequest", args, nil)
+}
+
+// Transfer2RegistryOfficer forwards a TransferRequest to a RegistryOfficer
+func (c *TransferChaincode) Transfer2RegistryOfficer(args transfer.RegistryOfficerArgs) error {
+	return c.submit("transfer2RegistryOfficer", args, nil)
+}
+
+// AutoTransfer2RegistryOfficer forwards a TransferRequest to the RegistryOfficer of its office with the fewest ActiveCases
+func (c *TransferChaincode) AutoTransfer2RegistryOfficer(args transfer.DateArgs) error {
+	return c.submit("autoTransfer2RegistryOfficer", args, nil)
+}
+
+// Transfer2BLRO forwards a TransferRequest to a BLRO
+func (c *TransferChaincode) Transfer2BLRO(args transfer.BLROArgs) error {
+	return c.submit("transfer2BLRO", args, nil)
+}
+
+// AddArtefact attaches an artefact the current Stage of a TransferRequest requires
+func (c *TransferChaincode) AddArtefact(args transfer.ArtefactArgs) error {
+	return c.submit("addArtefact", args, nil)
+}
+
+// ApproveTransferRequest approves a TransferRequest at its last Stage, moving its Land to its new owner
+func (c *TransferChaincode) ApproveTransferRequest(args transfer.DateArgs) error {
+	return c.submit("approveTransferRequest", args, nil)
+}
+
+// ReassignLawyer reassigns the Lawyer of an open TransferRequest
+func (c *TransferChaincode) ReassignLawyer(args transfer.LawyerArgs) error {
+	return c.submit("reassignLawyer", args, nil)
+}
+
+// ReassignRegistryOfficer reassigns the RegistryOfficer of an open TransferRequest
+func (c *TransferChaincode) ReassignRegistryOfficer(args transfer.RegistryOfficerArgs) error {
+	return c.submit("reassignRegistryOfficer", args, nil)
+}
+
+// ReassignBLRO reassigns the BLRO of an open TransferRequest
+func (c *TransferChaincode) ReassignBLRO(args transfer.BLROArgs) error {
+	return c.submit("reassignBLRO", args, nil)
+}
+
+// QueryTransferRequests lists the TransferRequests the caller is a party to by an indexed attribute, a page at a time
+func (c *TransferChaincode) QueryTransferRequests(args transfer.QueryArgs) (*TransferRequestPage, error) {
+	page := &TransferRequestPage{}
+	return page, c.query("queryTransferRequests", args, page)
+}
+
+// ---------------------------------------------
+// Appeals
+// ---------------------------------------------
+
+// DeclineTransferRequest declines a TransferRequest at the last Stage of its Workflow
+func (c *TransferChaincode) DeclineTransferRequest(args transfer.DeclineArgs) error {
+	return c.submit("declineTransferRequest", args, nil)
+}
+
+// FileAppeal appeals against the decline of a TransferRequest
+func (c *TransferChaincode) FileAppeal(args transfer.FileAppealArgs) error {
+	return c.submit("fileAppeal", args, nil)
+}
+
+// ReadAppeal reads the Appeal with ID
+func (c *TransferChaincode) ReadAppeal(ID string) (*transfer.Appeal, error) {
+	a := &transfer.Appeal{}
+	return a, c.query("readAppeal", idArgs(ID), a)
+}
+
+// AssignAppealReviewer assigns a senior BLRO, other than the one who declined, to review an Appeal
+func (c *TransferChaincode) AssignAppealReviewer(args transfer.ReviewerArgs) error {
+	return c.submit("assignAppealReviewer", args, nil)
+}
+
+// DecideAppeal decides an Appeal; overturning it reopens the TransferRequest at its last Stage for the Reviewer
+func (c *TransferChaincode) DecideAppeal(args transfer.DecideAppealArgs) error {
+	return c.submit("decideAppeal", args, nil)
+}
+
+// CloseTransferRequest closes a declined TransferRequest without an Appeal, by its Requester
+// or, once the appeal deadline has passed, by the BLRO who declined it
+func (c *TransferChaincode) CloseTransferRequest(args transfer.CloseArgs) error {
+	return c.submit("closeTransferRequest", args, nil)
+}
+
+// ---------------------------------------------
+// Comments
+// ---------------------------------------------
+
+// PostComment posts a Comment on a TransferRequest, optionally attaching the hash of a Document
+func (c *TransferChaincode) PostComment(args transfer.CommentArgs) error {
+	return c.submit("postComment", args, nil)
+}
+
+// ReplyComment replies to a Comment on a TransferRequest
+func (c *TransferChaincode) ReplyComment(args transfer.ReplyCommentArgs) error {
+	return c.submit("replyComment", args, nil)
+}
+
+// RequestClarification asks the other parties of a TransferRequest for a clarification, blocking it until resolved
+func (c *TransferChaincode) RequestClarification(args transfer.CommentArgs) error {
+	return c.submit("requestClarification", args, nil)
+}
+
+// ResolveClarification resolves a clarification, by the party who asked for it, the professional now holding
+// the stage they asked from, or the requester
+func (c *TransferChaincode) ResolveClarification(args transfer.ResolveClarificationArgs) error {
+	return c.submit("resolveClarification", args, nil)
+}
+
+// ListComments lists the Comments of a TransferRequest the caller can read, a page at a time
+func (c *TransferChaincode) ListComments(args transfer.ThreadArgs) (*CommentPage, error) {
+	page := &CommentPage{}
+	return page, c.query("listComments", args, page)
+}
+
+// ---------------------------------------------
+// Workflows
+// ---------------------------------------------
+
+// CreateWorkflow defines a Workflow TransferRequests can follow
+func (c *TransferChaincode) CreateWorkflow(args transfer.CreateWorkflowArgs) error {
+	return c.submit("createWorkflow", args, nil)
+}
+
+// ReadWorkflow reads the Workflow with ID
+func (c *TransferChaincode) ReadWorkflow(ID string) (*transfer.Workflow, error) {
+	w := &transfer.Workflow{}
+	return w, c.query("readWorkflow", idArgs(ID), w)
+}
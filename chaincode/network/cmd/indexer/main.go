@@ -0,0 +1,97 @@
+// Command indexer projects the Events of the chaincodes into an SQLite database and answers reporting
+// queries of it over HTTP.
+//
+//	indexer [-db index.db] [-listen :8080] [-replay blocks.jsonl | -config sdk.yaml -org org -user Admin -channel mainchannel]
+//
+// Blocks are streamed from the deliver service of a peer of the channel through the fabric-sdk-go event client,
+// as the user of the organization the SDK configuration gives the credentials of, each once it is committed,
+// or replayed from a file simulate -blocks wrote. The indexer resumes after the last block it applied.
+// The API keeps answering once a replay is done; see package indexer for its queries.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"example.org/network/indexer"
+	"example.org/network/peer"
+)
+
+func main() {
+	flags := flag.NewFlagSet("indexer", flag.ExitOnError)
+	db := flags.String("db", "index.db", "SQLite database the Events are projected into")
+	listen := flags.String("listen", ":8080", "address the query API listens on, none if empty")
+	replay := flags.String("replay", "", "file of blocks to replay instead of streaming them from a peer")
+	p := peer.Flags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: indexer [-db index.db] [-listen :8080] [-replay blocks.jsonl | -config sdk.yaml [-org org] [-user Admin] [-channel mainchannel]]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(os.Args[1:])
+	if flags.NArg() != 0 || (*replay == "") == (p.Path == "") {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	source, closeSource, err := open(*replay, p)
+	if err == nil {
+		err = run(*db, *listen, source)
+		closeSource()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "indexer:", err)
+		os.Exit(1)
+	}
+}
+
+// Open the source of the blocks, the file replay if set, or else the peers the Gateway of p connects to,
+// giving the function closing it
+func open(replay string, p *peer.Config) (indexer.Source, func(), error) {
+	if replay != "" {
+		return indexer.FileSource{Path: replay}, func() {}, nil
+	}
+
+	g, err := p.Connect()
+	if err != nil {
+		return nil, nil, err
+	}
+	return indexer.DeliverSource{Deliverer: indexer.EventClient{Channel: g.Channel()}}, g.Close, nil
+}
+
+// Apply the blocks of source to the database at path while the API answers queries at listen
+func run(path string, listen string, source indexer.Source) error {
+	ix, err := indexer.Open(path)
+	if err != nil {
+		return err
+	}
+	defer ix.Close()
+	next, err := ix.Next()
+	if err != nil {
+		return err
+	}
+	log.Printf("indexing %s from block %d", path, next)
+
+	if listen == "" {
+		return ix.Run(source)
+	}
+	api, err := indexer.OpenAPI(path)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- http.ListenAndServe(listen, api)
+	}()
+	err = ix.Run(source)
+	if err != nil {
+		return err
+	}
+	next, _ = ix.Next()
+	log.Printf("indexed up to block %d, serving %s", next, listen)
+	return <-served
+}
@@ -0,0 +1,186 @@
+// Command ledger archives the ledgers of the chaincodes of a network, and seeds a network with an archive.
+//
+//	ledger dump [-page 100] [-o archive.json] [-scenario scenario.yaml]
+//	ledger import [-batch 100] archive.json
+//	ledger verify archive.json
+//
+// dump pages through dumpState of every chaincode into a versioned, checksummed archive, failing if the
+// chain grows while it is taken. import checks the archive, then puts its entries through importState,
+// and can be run again to resume. Both call the network through fabric-sdk-go as an admin, as configured by
+// -config, the SDK configuration of the network; with -scenario, dump archives the in-memory network a
+// simulator scenario leaves instead.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"example.org/network"
+	"example.org/network/archive"
+	"example.org/network/peer"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "dump":
+		err = dump(os.Args[2:])
+	case "import":
+		err = load(os.Args[2:])
+	case "verify":
+		err = verify(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ledger:", err)
+		os.Exit(1)
+	}
+}
+
+// Dump the ledgers of every chaincode into an archive
+func dump(args []string) error {
+	flags := flag.NewFlagSet("dump", flag.ExitOnError)
+	page := flags.Int("page", 100, "entries per dumpState page, at most 1000")
+	output := flags.String("o", "", "file to write the archive to, standard output if not set")
+	scenario := flags.String("scenario", "", "archive the in-memory network this scenario leaves, instead of a peer")
+	p := peer.Flags(flags)
+	flags.Parse(args)
+
+	var n archive.Network
+	if *scenario != "" {
+		session, err := simulate(*scenario)
+		if err != nil {
+			return err
+		}
+		n = session
+	} else {
+		g, err := p.Connect()
+		if err != nil {
+			return err
+		}
+		defer g.Close()
+		n = g
+	}
+
+	a, err := archive.Dump(n, network.Chaincodes, int32(*page))
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	err = a.Write(out)
+	if err != nil {
+		return err
+	}
+	for _, chaincode := range a.Chaincodes() {
+		fmt.Fprintf(os.Stderr, "%s: %d entries\n", chaincode, len(a.Ledgers[chaincode]))
+	}
+	fmt.Fprintf(os.Stderr, "archived at height %d, checksum %s\n", a.Height, a.Checksum)
+	return nil
+}
+
+// Import an archive into the ledgers of its chaincodes
+func load(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	batch := flags.Int("batch", 100, "entries per importState transaction, at most 1000")
+	p := peer.Flags(flags)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+	}
+
+	a, err := read(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	g, err := p.Connect()
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+	return archive.Import(g, a, *batch, func(chaincode string, imported int) {
+		fmt.Fprintf(os.Stderr, "%s: %d of %d entries imported\n", chaincode, imported, len(a.Ledgers[chaincode]))
+	})
+}
+
+// Check an archive is of a supported version and matches its checksum
+func verify(args []string) error {
+	if len(args) != 1 {
+		usage()
+	}
+	a, err := read(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("archive version %d, taken at height %d on %s, checksum %s: ok\n", a.Version, a.Height, a.Created, a.Checksum)
+	return nil
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Read and verify the archive at path
+func read(path string) (*archive.Archive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	a, err := archive.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return a, nil
+}
+
+// Session of the admin of a World left by the scenario at path
+func simulate(path string) (*network.Session, error) {
+	scenarioAsBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	scenario, err := network.ParseScenario(scenarioAsBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	w, err := network.New()
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.Replay(scenario, ioutil.Discard)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := w.Admin()
+	if err != nil {
+		return nil, err
+	}
+	return w.Session(admin), nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: ledger dump [-page 100] [-o archive.json] [-scenario scenario.yaml]
+       ledger import [-batch 100] archive.json
+       ledger verify archive.json
+Network flags of dump and import: -config sdk.yaml, -org org, -user Admin, -channel mainchannel`)
+	os.Exit(2)
+}
@@ -0,0 +1,178 @@
+// Command onboard registers the parcels of a cadastral CSV as Land through createLands of land_cc.
+//
+//	onboard [-chunk 100] [-state KA -district BLR -office SRO1] [-date unix] [-progress file] [-failures file] parcels.csv
+//
+// The CSV has a header naming its ID, Address, Owner, SurveyNumber, Area and Geometry columns, and optionally
+// State, District and OfficeID, which otherwise are those of the flags. Each chunk of rows is one transaction,
+// proposed through fabric-sdk-go by -user, a BLRO of the parcels' district, as -config, the SDK configuration of
+// the network, gives their credentials.
+//
+// The rows rejected, before they are sent or by createLands, are appended to the failures CSV with the code and
+// message they were rejected with. The rows processed are recorded in the progress file after each chunk commits,
+// so an interrupted onboarding is resumed by running it again.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"example.org/network/cadastre"
+	"example.org/network/peer"
+)
+
+// Progress of an onboarding, as recorded in its progress file
+type progress struct {
+	Rows    int `json:"Rows"`
+	Created int `json:"Created"`
+	Failed  int `json:"Failed"`
+}
+
+func main() {
+	flags := flag.NewFlagSet("onboard", flag.ExitOnError)
+	chunk := flags.Int("chunk", 100, "rows per createLands transaction, at most 500")
+	state := flags.String("state", "", "State of parcels the CSV does not give one")
+	district := flags.String("district", "", "District of parcels the CSV does not give one")
+	office := flags.String("office", "", "OfficeID of parcels the CSV does not give one")
+	date := flags.Int("date", int(time.Now().Unix()), "Unix time the Lands are registered at")
+	progressPath := flags.String("progress", "", "file recording the rows processed, the CSV's path with .progress if not set")
+	failuresPath := flags.String("failures", "", "CSV the rejected rows are appended to, the CSV's path with .failures.csv if not set")
+	p := peer.Flags(flags)
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+	if flags.NArg() != 1 {
+		usage()
+	}
+
+	path := flags.Arg(0)
+	if *progressPath == "" {
+		*progressPath = path + ".progress"
+	}
+	if *failuresPath == "" {
+		*failuresPath = path + ".failures.csv"
+	}
+	options := cadastre.Options{State: *state, District: *district, OfficeID: *office, Date: *date, ChunkSize: *chunk}
+
+	g, err := p.Connect()
+	if err == nil {
+		err = onboard(g, path, options, *progressPath, *failuresPath)
+		g.Close()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "onboard:", err)
+		os.Exit(1)
+	}
+}
+
+// Onboard the CSV at path, resuming past the rows recorded in the progress file
+func onboard(n cadastre.Network, path string, o cadastre.Options, progressPath string, failuresPath string) error {
+	done, err := readProgress(progressPath)
+	if err != nil {
+		return err
+	}
+	o.Skip = done.Rows
+	if done.Rows > 0 {
+		fmt.Fprintf(os.Stderr, "resuming after row %d\n", done.Rows)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	failures, err := openFailures(failuresPath)
+	if err != nil {
+		return err
+	}
+	defer failures.Close()
+
+	report, err := cadastre.Onboard(n, f, o, func(report *cadastre.Report, rejected []cadastre.Failure) error {
+		// Failures are recorded before progress, so a crash in between records them twice rather than never
+		w := csv.NewWriter(failures)
+		for _, r := range rejected {
+			w.Write([]string{strconv.Itoa(r.Row), r.ID, r.Code, r.Message})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+
+		now := progress{report.Rows, done.Created + report.Created, done.Failed + len(report.Failures)}
+		fmt.Fprintf(os.Stderr, "%d rows processed: %d created, %d failed\n", now.Rows, now.Created, now.Failed)
+		return writeProgress(progressPath, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d rows onboarded: %d created, %d failed", report.Rows, done.Created+report.Created, done.Failed+len(report.Failures))
+	if done.Failed+len(report.Failures) > 0 {
+		fmt.Printf(", see %s", failuresPath)
+	}
+	fmt.Println()
+	return nil
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Progress recorded at path, none if it does not exist
+func readProgress(path string) (progress, error) {
+	p := progress{}
+	progressAsBytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, nil
+	} else if err != nil {
+		return p, err
+	}
+	err = json.Unmarshal(progressAsBytes, &p) //unmarshal it aka JSON.parse()
+	if err != nil {
+		return p, fmt.Errorf("%s: %v", path, err)
+	}
+	return p, nil
+}
+
+// Record p at path, replacing the file whole so an interruption leaves the progress before or after it
+func writeProgress(path string, p progress) error {
+	progressJSONasBytes, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(path+".tmp", append(progressJSONasBytes, '\n'), 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Open the failures CSV at path for appending, writing its header if it is new
+func openFailures(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err == nil && info.Size() == 0 {
+		w := csv.NewWriter(f)
+		w.Write([]string{"Row", "ID", "Code", "Message"})
+		w.Flush()
+		err = w.Error()
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: onboard [-chunk 100] [-state KA -district BLR -office SRO1] [-date unix] [-progress file] [-failures file] parcels.csv
+Network flags: -config sdk.yaml, -org org, -user BLRO, -channel mainchannel`)
+	os.Exit(2)
+}
@@ -0,0 +1,145 @@
+// Command simulate replays a scenario of registry traffic on the five chaincodes running in memory,
+// without a Fabric network, then prints the ledger of each chaincode and the StatusHistory of every
+// TransferRequest.
+//
+//	simulate [-export state.json] [-blocks blocks.jsonl] scenario.yaml
+//
+// The scenario lists its members and the actions they take, in YAML or JSON; see
+// network/testdata/transfer.yaml. With -export, the final world state is written as JSON.
+// With -blocks, the chaincode events of the committed transactions are written as blocks
+// for indexer -replay.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"example.org/network"
+	"example.org/network/indexer"
+)
+
+// Status of a TransferRequest, as transfer_cc records it
+type statusHistory struct {
+	Status        string `json:"Status"`
+	StatusCreator string `json:"StatusCreator"`
+	Date          int    `json:"Date"`
+}
+
+func main() {
+	export := flag.String("export", "", "write the final world state as JSON to this file")
+	blocks := flag.String("blocks", "", "write the chaincode events as blocks the indexer replays to this file")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: simulate [-export state.json] [-blocks blocks.jsonl] scenario.yaml")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	err := simulate(flag.Arg(0), *export, *blocks)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "simulate:", err)
+		os.Exit(1)
+	}
+}
+
+// Replay the scenario at path and print the resulting state, exporting it to export and its events to
+// blocks if set. The state is printed even when the scenario stops early, as far as it got.
+func simulate(path string, export string, blocks string) error {
+	scenarioAsBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	scenario, err := network.ParseScenario(scenarioAsBytes)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	w, err := network.New()
+	if err != nil {
+		return err
+	}
+
+	replayErr := w.Replay(scenario, os.Stdout)
+	state := w.State()
+	printState(state)
+	printStatusHistory(state["transfer_cc"])
+
+	if export != "" {
+		stateJSONasBytes, err := json.MarshalIndent(state, "", "  ")
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(export, append(stateJSONasBytes, '\n'), 0644)
+		if err != nil {
+			return err
+		}
+	}
+	if blocks != "" {
+		var buffer bytes.Buffer
+		err = indexer.WriteBlocks(&buffer, w.Events())
+		if err == nil {
+			err = ioutil.WriteFile(blocks, buffer.Bytes(), 0644)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return replayErr
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Print the records of every chaincode, in key order
+func printState(state map[string]map[string]json.RawMessage) {
+	for _, name := range network.Chaincodes {
+		fmt.Printf("\n== %s\n", name)
+		for _, key := range sortedKeys(state[name]) {
+			var indented bytes.Buffer
+			json.Indent(&indented, state[name][key], "  ", "  ")
+			fmt.Printf("%s:\n  %s\n", key, indented.String())
+		}
+	}
+}
+
+// Print the StatusHistory of every TransferRequest in the records of transfer_cc
+func printStatusHistory(records map[string]json.RawMessage) {
+	fmt.Printf("\n== StatusHistory\n")
+	for _, key := range sortedKeys(records) {
+		if !strings.HasPrefix(key, "transferRequest-") {
+			continue
+		}
+		var transferRequest struct {
+			ID            string          `json:"ID"`
+			StatusHistory []statusHistory `json:"StatusHistory"`
+		}
+		err := json.Unmarshal(records[key], &transferRequest) //unmarshal it aka JSON.parse()
+		if err != nil {
+			continue
+		}
+
+		fmt.Println(transferRequest.ID)
+		for _, status := range transferRequest.StatusHistory {
+			fmt.Printf("  %4d  %-12s %s\n", status.Date, status.StatusCreator, status.Status)
+		}
+	}
+}
+
+// Keys of records in order
+func sortedKeys(records map[string]json.RawMessage) []string {
+	var keys []string
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
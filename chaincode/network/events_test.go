@@ -0,0 +1,242 @@
+package network
+
+import (
+	"encoding/pem"
+	"strconv"
+	"strings"
+	"testing"
+
+	"example.org/lib/contract"
+	"example.org/lib/events"
+)
+
+// Types of the Events of each transaction committed from block from on, one transaction per line
+func (f *fixture) eventTypes(t *testing.T, from int) []string {
+	var lines []string
+	for _, e := range f.Events() {
+		if e.Block < uint64(from) {
+			continue
+		}
+		if e.Name != contract.EventName {
+			t.Error("unexpected event", e.Name)
+		}
+		envelope, typed, err := events.Parse(e.Payload)
+		check(t, err)
+		if envelope.TxID != e.TxID || len(typed) != len(envelope.Events) {
+			t.Error("unexpected envelope", envelope)
+		}
+
+		var types []string
+		for _, r := range envelope.Events {
+			types = append(types, r.Type)
+		}
+		lines = append(lines, envelope.Function+": "+strings.Join(types, " "))
+	}
+	return lines
+}
+
+func TestTransferEvents(t *testing.T) {
+	f := newFixture(t)
+	if types := f.eventTypes(t, 0); types[len(types)-1] != "createLand: LandCreated" {
+		t.Error("Land created without an event", types)
+	}
+
+	// Failed transactions and queries emit nothing
+	from := f.Height() + 1
+	expectCode(t, f.ApproveTransfer(f.transfer), "NOT_FOUND", "approval of an unrequested transfer")
+	_, err := f.Query(f.citizen, "transfer_cc", "createTransferRequest", map[string]interface{}{"ID": "T9", "To": "C2", "LandID": "LAND1", "Assignee": "L1", "Date": f.Date()})
+	check(t, err)
+
+	check(t, f.CompleteTransfer(f.transfer))
+	expected := []string{
+		"createTransferRequest: TransferRequestCreated CaseAssigned",
+		"transfer2RegistryOfficer: StageChanged CaseAssigned",
+		"transfer2BLRO: StageChanged CaseAssigned",
+		"approveTransferRequest: RequestApproved CaseCompleted CaseCompleted CaseCompleted LandTransferred",
+	}
+	if types := f.eventTypes(t, from); !equal(types, expected) {
+		t.Error("unexpected events", types)
+	}
+
+	// The last event holds the transfer of the Land, which land_cc made within the transaction
+	last := f.Events()[len(f.Events())-1]
+	_, typed, err := events.Parse(last.Payload)
+	check(t, err)
+	transferred, ok := typed[len(typed)-1].(*events.LandTransferred)
+	if last.Chaincode != "transfer_cc" || !ok || transferred.PreviousOwner != "C1" || transferred.CurrentOwner != "C2" || transferred.TransferRequestID != "T1" {
+		t.Error("unexpected LandTransferred", string(last.Payload))
+	}
+
+	// So does placing the Land in the jurisdiction of another office
+	_, err = f.Call(f.admin, "land_cc", "setLandJurisdiction", map[string]interface{}{"ID": "LAND1", "State": "KA", "District": "MYS", "OfficeID": "SRO2"})
+	check(t, err)
+	_, typed, err = events.Parse(f.Events()[len(f.Events())-1].Payload)
+	check(t, err)
+	if moved, ok := typed[0].(*events.LandJurisdictionSet); !ok || moved.LandID != "LAND1" || moved.District != "MYS" || moved.OfficeID != "SRO2" || moved.Date == 0 {
+		t.Error("unexpected LandJurisdictionSet", typed)
+	}
+}
+
+func TestDeclineEvents(t *testing.T) {
+	f := newFixture(t)
+	check(t, f.RequestTransfer(f.transfer))
+	_, err := f.Call(f.citizen, "transfer_cc", "reassignLawyer", map[string]interface{}{"ID": "T1", "Lawyer": "L2", "Date": f.Date()})
+	check(t, err)
+
+	from := f.Height() + 1
+	_, err = f.Call(f.blros[0], "transfer_cc", "declineTransferRequest", map[string]interface{}{"ID": "T1", "Reason": "Disputed boundary", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.citizen, "transfer_cc", "fileAppeal", map[string]interface{}{"ID": "A1", "TransferRequestID": "T1", "Grounds": "Survey attached", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.blros[0], "transfer_cc", "assignAppealReviewer", map[string]interface{}{"ID": "A1", "Reviewer": "B2", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.blros[1], "transfer_cc", "decideAppeal", map[string]interface{}{"ID": "A1", "Outcome": "upheld", "Date": f.Date()})
+	check(t, err)
+
+	expected := []string{
+		"declineTransferRequest: StageChanged",
+		"fileAppeal: AppealFiled",
+		"assignAppealReviewer: AppealReviewerAssigned",
+		"decideAppeal: AppealDecided StageChanged CaseCompleted CaseCompleted CaseCompleted",
+	}
+	if types := f.eventTypes(t, from); !equal(types, expected) {
+		t.Error("unexpected events", types)
+	}
+
+	var statuses []string
+	for _, e := range f.Events() {
+		_, typed, err := events.Parse(e.Payload)
+		check(t, err)
+		for _, event := range typed {
+			switch event := event.(type) {
+			case *events.StageChanged:
+				statuses = append(statuses, event.Status)
+			case *events.CaseRemoved:
+				statuses = append(statuses, "removed "+event.Professional)
+			case *events.AppealFiled:
+				statuses = append(statuses, "appealed by "+event.Appellant)
+			case *events.AppealReviewerAssigned:
+				statuses = append(statuses, "reviewed by "+event.Reviewer)
+			case *events.AppealDecided:
+				statuses = append(statuses, event.Outcome+" on "+event.AppealID)
+			}
+		}
+	}
+	if !equal(statuses, []string{"open", "open", "removed L1", "declined", "appealed by C1", "reviewed by B2", "upheld on A1", "closed"}) {
+		t.Error("unexpected changes", statuses)
+	}
+}
+
+func TestWithdrawnAppealEvents(t *testing.T) {
+	f := newFixture(t)
+	check(t, f.RequestTransfer(f.transfer))
+	_, err := f.Call(f.blros[0], "transfer_cc", "declineTransferRequest", map[string]interface{}{"ID": "T1", "Reason": "Disputed boundary", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.citizen, "transfer_cc", "fileAppeal", map[string]interface{}{"ID": "A1", "TransferRequestID": "T1", "Grounds": "Survey attached", "Date": f.Date()})
+	check(t, err)
+
+	// Another request transferring the Land withdraws the pending Appeal
+	from := f.Height() + 1
+	second := f.transfer
+	second.ID = "T2"
+	check(t, f.CompleteTransfer(second))
+	types := f.eventTypes(t, from)
+	if last := types[len(types)-1]; !strings.HasPrefix(last, "approveTransferRequest: RequestApproved") || !strings.Contains(last, "AppealDecided") {
+		t.Error("unexpected events", types)
+	}
+}
+
+func TestThreadEvents(t *testing.T) {
+	f := newFixture(t)
+	stages := []map[string]interface{}{
+		{"Name": "lawyer", "Title": "Lawyer", "MSP": "LawyerMSP", "CA": "ca.lawyer.lran.com", "Role": "lawyer", "Chaincode": "lawyer_cc", "Artefacts": []string{"deed"}},
+		{"Name": "blro", "Title": "BLRO", "MSP": "BLROMSP", "CA": "ca.blro.lran.com", "Role": "blro", "Chaincode": "blro_cc"},
+	}
+	from := f.Height() + 1
+	_, err := f.Call(f.blros[0], "transfer_cc", "createWorkflow", map[string]interface{}{"ID": "direct", "Description": "Lawyer, then BLRO.", "Stages": stages})
+	check(t, err)
+	_, err = f.Call(f.citizen, "transfer_cc", "createTransferRequest", map[string]interface{}{"ID": "T1", "To": "C2", "LandID": "LAND1", "Assignee": "L1", "Date": f.Date(), "WorkflowID": "direct"})
+	check(t, err)
+	_, err = f.Call(f.lawyers[0], "transfer_cc", "addArtefact", map[string]interface{}{"ID": "T1", "Name": "deed", "Reference": "sha256:00", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.citizen, "transfer_cc", "postComment", map[string]interface{}{"TransferRequestID": "T1", "ID": "M1", "Message": "Deed sent", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.lawyers[0], "transfer_cc", "requestClarification", map[string]interface{}{"TransferRequestID": "T1", "ID": "Q1", "Message": "Survey?", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.citizen, "transfer_cc", "replyComment", map[string]interface{}{"TransferRequestID": "T1", "ID": "Q1R", "Message": "Attached", "Document": "survey", "ReplyTo": "Q1", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.lawyers[0], "transfer_cc", "resolveClarification", map[string]interface{}{"TransferRequestID": "T1", "ID": "Q1", "Date": f.Date()})
+	check(t, err)
+
+	expected := []string{
+		"createWorkflow: WorkflowCreated",
+		"createTransferRequest: TransferRequestCreated CaseAssigned",
+		"addArtefact: ArtefactAdded",
+		"postComment: CommentPosted",
+		"requestClarification: CommentPosted",
+		"replyComment: CommentPosted",
+		"resolveClarification: ClarificationResolved",
+	}
+	if types := f.eventTypes(t, from); !equal(types, expected) {
+		t.Error("unexpected events", types)
+	}
+
+	var changes []string
+	for _, e := range f.Events() {
+		_, typed, err := events.Parse(e.Payload)
+		check(t, err)
+		for _, event := range typed {
+			switch event := event.(type) {
+			case *events.WorkflowCreated:
+				changes = append(changes, event.WorkflowID+" "+strings.Join(event.Stages, ","))
+			case *events.ArtefactAdded:
+				changes = append(changes, event.Name+" at "+event.Stage)
+			case *events.CommentPosted:
+				changes = append(changes, event.CommentID+" by "+event.Author+" to "+event.ReplyTo+" "+strconv.FormatBool(event.Clarification))
+			case *events.ClarificationResolved:
+				changes = append(changes, event.CommentID+" resolved by "+event.Author)
+			}
+		}
+	}
+	expected = []string{"direct lawyer,blro", "deed at lawyer", "M1 by C1 to  false", "Q1 by L1 to  true", "Q1R by C1 to Q1 false", "Q1 resolved by L1"}
+	if !equal(changes, expected) {
+		t.Error("unexpected changes", changes)
+	}
+}
+
+func TestRegistryEvents(t *testing.T) {
+	f := newFixture(t)
+	types := f.eventTypes(t, 0)
+	for _, line := range []string{"createLawyer: ProfileCreated", "licenseRegistryOfficer: ProfileLicensed", "setBLRORank: ProfileRanked"} {
+		if !contains(types, line) {
+			t.Error("no", line, "in", types)
+		}
+	}
+
+	from := f.Height() + 1
+	certificate := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.lawyers[0].Certificate.Raw}))
+	_, err := f.Call(f.admin, "lawyer_cc", "setLawyerStatus", map[string]string{"ID": "L1", "Status": "suspended"})
+	check(t, err)
+	_, err = f.Call(f.admin, "lawyer_cc", "rekeyLawyer", map[string]string{"ID": "L1", "Certificate": certificate})
+	check(t, err)
+	if types := f.eventTypes(t, from); !equal(types, []string{"setLawyerStatus: ProfileStatusChanged", "rekeyLawyer: ProfileRekeyed"}) {
+		t.Error("unexpected events", types)
+	}
+
+	var changes []string
+	for _, e := range f.Events() {
+		_, typed, err := events.Parse(e.Payload)
+		check(t, err)
+		for _, event := range typed {
+			switch event := event.(type) {
+			case *events.ProfileStatusChanged:
+				changes = append(changes, event.Role+" "+event.ProfileID+" "+event.PreviousStatus+" to "+event.Status)
+			case *events.ProfileRekeyed:
+				changes = append(changes, event.Role+" "+event.ProfileID+" rekeyed")
+			}
+		}
+	}
+	if !equal(changes, []string{"lawyer L1 active to suspended", "lawyer L1 rekeyed"}) {
+		t.Error("unexpected changes", changes)
+	}
+}
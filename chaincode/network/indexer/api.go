@@ -0,0 +1,398 @@
+package indexer
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"example.org/lib/envelope"
+)
+
+// Land as the API gives it, Transferred being the Date of its last transfer, if any
+type Land struct {
+	ID          string `json:"ID"`
+	Owner       string `json:"Owner"`
+	State       string `json:"State"`
+	District    string `json:"District"`
+	OfficeID    string `json:"OfficeID"`
+	BLRO        string `json:"BLRO"`
+	Created     int    `json:"Created"`
+	Transferred int    `json:"Transferred,omitempty"`
+}
+
+// Transfer as the API gives it, its State, District and OfficeID those of its Land, and Closed the Date it was
+// approved, or its decline upheld. Its StageChanges and Cases are only given by /transfers/{id}.
+type Transfer struct {
+	ID           string        `json:"ID"`
+	LandID       string        `json:"LandID"`
+	State        string        `json:"State"`
+	District     string        `json:"District"`
+	OfficeID     string        `json:"OfficeID"`
+	From         string        `json:"From"`
+	To           string        `json:"To"`
+	Requester    string        `json:"Requester"`
+	Workflow     string        `json:"Workflow"`
+	Stage        string        `json:"Stage"`
+	Status       string        `json:"Status"`
+	Assignee     string        `json:"Assignee"`
+	Reason       string        `json:"Reason,omitempty"`
+	Approver     string        `json:"Approver,omitempty"`
+	Created      int           `json:"Created"`
+	Closed       int           `json:"Closed,omitempty"`
+	StageChanges []StageChange `json:"StageChanges,omitempty"`
+	Cases        []Case        `json:"Cases,omitempty"`
+}
+
+// StageChange of a Transfer, in the order they were committed
+type StageChange struct {
+	TxID          string `json:"TxID"`
+	PreviousStage string `json:"PreviousStage"`
+	Stage         string `json:"Stage"`
+	Status        string `json:"Status"`
+	Assignee      string `json:"Assignee"`
+	Reason        string `json:"Reason,omitempty"`
+	Date          int    `json:"Date"`
+}
+
+// Case of a Professional held in Chaincode, active, completed or removed
+type Case struct {
+	Chaincode    string `json:"Chaincode"`
+	Professional string `json:"Professional"`
+	Stage        string `json:"Stage"`
+	Status       string `json:"Status"`
+	Assigned     int    `json:"Assigned"`
+	Closed       int    `json:"Closed,omitempty"`
+}
+
+// Professional as onboarded, licensed and ranked, with the number of their cases by status
+type Professional struct {
+	Chaincode    string `json:"Chaincode"`
+	Professional string `json:"Professional"`
+	Role         string `json:"Role"`
+	Name         string `json:"Name"`
+	Status       string `json:"Status"`
+	Rank         string `json:"Rank"`
+	Active       int    `json:"Active"`
+	Completed    int    `json:"Completed"`
+	Removed      int    `json:"Removed"`
+}
+
+// DistrictReport counts the transfers requested in a District by Status, with the average time approved ones took
+type DistrictReport struct {
+	State                 string  `json:"State"`
+	District              string  `json:"District"`
+	Transfers             int     `json:"Transfers"`
+	Open                  int     `json:"Open"`
+	Declined              int     `json:"Declined"`
+	Closed                int     `json:"Closed"`
+	Approved              int     `json:"Approved"`
+	AverageProcessingTime float64 `json:"AverageProcessingTime"`
+}
+
+// Code of the envelope answering a request that is not a GET
+const codeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+
+// Status of the database, the next block the Indexer applies
+type Status struct {
+	NextBlock uint64 `json:"NextBlock"`
+}
+
+// API answers the queries of the database, read only, over HTTP:
+//
+//	GET /status
+//	GET /lands?state=&district=&owner=
+//	GET /lands/{id}
+//	GET /transfers?state=&district=&status=&land=&from=&to=
+//	GET /transfers/{id}
+//	GET /professionals?chaincode=
+//	GET /reports/transfers?from=&to=
+//
+// from and to bound the Date transfers were requested at, inclusive, e.g. the last quarter as Unix times.
+// Errors are envelopes, as the chaincodes give them.
+type API struct {
+	db *sql.DB
+}
+
+// OpenAPI opens the database at path read only, as an Indexer created it
+func OpenAPI(path string) (*API, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &API{db}, nil
+}
+
+// Close the database
+func (a *API) Close() error {
+	return a.db.Close()
+}
+
+// ServeHTTP answers a query
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, envelope.New(codeMethodNotAllowed, "The API is read only!", "Method", r.Method))
+		return
+	}
+
+	var result interface{}
+	var err error
+	path := strings.Trim(r.URL.Path, "/")
+	switch {
+	case path == "status":
+		result, err = a.status()
+	case path == "lands":
+		result, err = a.lands(r)
+	case strings.HasPrefix(path, "lands/"):
+		result, err = a.land(strings.TrimPrefix(path, "lands/"))
+	case path == "transfers":
+		result, err = a.transfers(r)
+	case strings.HasPrefix(path, "transfers/"):
+		result, err = a.transfer(strings.TrimPrefix(path, "transfers/"))
+	case path == "professionals":
+		result, err = a.professionals(r)
+	case path == "reports/transfers":
+		result, err = a.transferReport(r)
+	default:
+		err = envelope.NotFound("No such query!", "Path", r.URL.Path)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ---------------------------------------------
+// Queries
+// ---------------------------------------------
+
+func (a *API) status() (Status, error) {
+	var s Status
+	err := a.db.QueryRow("SELECT next_block FROM checkpoint WHERE id = 0").Scan(&s.NextBlock)
+	return s, err
+}
+
+const landColumns = "id, owner, state, district, office_id, blro, created, COALESCE(transferred, 0)"
+
+func (a *API) lands(r *http.Request) ([]Land, error) {
+	where, args, err := filters(r, map[string]string{"state": "state", "district": "district", "owner": "owner"}, "")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := a.db.Query("SELECT "+landColumns+" FROM lands"+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lands := []Land{}
+	for rows.Next() {
+		var l Land
+		err = rows.Scan(&l.ID, &l.Owner, &l.State, &l.District, &l.OfficeID, &l.BLRO, &l.Created, &l.Transferred)
+		if err != nil {
+			return nil, err
+		}
+		lands = append(lands, l)
+	}
+	return lands, rows.Err()
+}
+
+func (a *API) land(ID string) (*Land, error) {
+	var l Land
+	err := a.db.QueryRow("SELECT "+landColumns+" FROM lands WHERE id = ?", ID).
+		Scan(&l.ID, &l.Owner, &l.State, &l.District, &l.OfficeID, &l.BLRO, &l.Created, &l.Transferred)
+	if err == sql.ErrNoRows {
+		return nil, envelope.NotFound("Land does not exist!", "ID", ID)
+	}
+	return &l, err
+}
+
+const transferColumns = `id, land_id, COALESCE(state, ''), COALESCE(district, ''), COALESCE(office_id, ''), from_id, to_id,
+	requester, workflow, stage, status, assignee, reason, approver, created, COALESCE(closed, 0)`
+
+func (a *API) transfers(r *http.Request) ([]Transfer, error) {
+	columns := map[string]string{"state": "state", "district": "district", "status": "status", "land": "land_id"}
+	where, args, err := filters(r, columns, "created")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := a.db.Query("SELECT "+transferColumns+" FROM transfers"+where+" ORDER BY created, id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := []Transfer{}
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, *t)
+	}
+	return transfers, rows.Err()
+}
+
+func (a *API) transfer(ID string) (*Transfer, error) {
+	t, err := scanTransfer(a.db.QueryRow("SELECT "+transferColumns+" FROM transfers WHERE id = ?", ID))
+	if err == sql.ErrNoRows {
+		return nil, envelope.NotFound("TransferRequest does not exist!", "ID", ID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	rows, err := a.db.Query("SELECT tx_id, previous_stage, stage, status, assignee, reason, date FROM stage_changes WHERE transfer_id = ? ORDER BY rowid", ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c StageChange
+		err = rows.Scan(&c.TxID, &c.PreviousStage, &c.Stage, &c.Status, &c.Assignee, &c.Reason, &c.Date)
+		if err != nil {
+			return nil, err
+		}
+		t.StageChanges = append(t.StageChanges, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = a.db.Query("SELECT chaincode, professional, stage, status, assigned, COALESCE(closed, 0) FROM cases WHERE transfer_id = ? ORDER BY assigned, rowid", ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c Case
+		err = rows.Scan(&c.Chaincode, &c.Professional, &c.Stage, &c.Status, &c.Assigned, &c.Closed)
+		if err != nil {
+			return nil, err
+		}
+		t.Cases = append(t.Cases, c)
+	}
+	return t, rows.Err()
+}
+
+func (a *API) professionals(r *http.Request) ([]Professional, error) {
+	where, args, err := filters(r, map[string]string{"chaincode": "chaincode"}, "")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := a.db.Query("SELECT chaincode, professional, role, name, status, rank, active, completed, removed FROM professionals"+where+" ORDER BY chaincode, professional", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	professionals := []Professional{}
+	for rows.Next() {
+		var p Professional
+		err = rows.Scan(&p.Chaincode, &p.Professional, &p.Role, &p.Name, &p.Status, &p.Rank, &p.Active, &p.Completed, &p.Removed)
+		if err != nil {
+			return nil, err
+		}
+		professionals = append(professionals, p)
+	}
+	return professionals, rows.Err()
+}
+
+func (a *API) transferReport(r *http.Request) ([]DistrictReport, error) {
+	where, args, err := filters(r, nil, "created")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := a.db.Query(`SELECT COALESCE(state, ''), COALESCE(district, ''), COUNT(*),
+			SUM(status = 'open'), SUM(status = 'declined'), SUM(status = 'closed'), SUM(status = 'approved'),
+			COALESCE(AVG(CASE WHEN status = 'approved' THEN closed - created END), 0)
+		FROM transfers`+where+` GROUP BY state, district ORDER BY state, district`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []DistrictReport{}
+	for rows.Next() {
+		var d DistrictReport
+		err = rows.Scan(&d.State, &d.District, &d.Transfers, &d.Open, &d.Declined, &d.Closed, &d.Approved, &d.AverageProcessingTime)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, d)
+	}
+	return reports, rows.Err()
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// WHERE clause matching the query parameters of r to their columns, and from and to bounding date if set
+func filters(r *http.Request, columns map[string]string, date string) (string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+	query := r.URL.Query()
+	for parameter := range query {
+		column, ok := columns[parameter]
+		switch {
+		case ok:
+			conditions = append(conditions, column+" = ?")
+			args = append(args, query.Get(parameter))
+		case date != "" && (parameter == "from" || parameter == "to"):
+			bound, err := strconv.ParseInt(query.Get(parameter), 10, 64)
+			if err != nil {
+				return "", nil, envelope.InvalidArgument("Date bound is not a Unix time!", parameter, query.Get(parameter))
+			}
+			operator := " >= ?"
+			if parameter == "to" {
+				operator = " <= ?"
+			}
+			conditions = append(conditions, date+operator)
+			args = append(args, bound)
+		default:
+			return "", nil, envelope.InvalidArgument("Unknown query parameter!", "Parameter", parameter)
+		}
+	}
+	if len(conditions) == 0 {
+		return "", nil, nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// Scan a row of transferColumns
+func scanTransfer(row interface{ Scan(...interface{}) error }) (*Transfer, error) {
+	var t Transfer
+	err := row.Scan(&t.ID, &t.LandID, &t.State, &t.District, &t.OfficeID, &t.From, &t.To, &t.Requester, &t.Workflow,
+		&t.Stage, &t.Status, &t.Assignee, &t.Reason, &t.Approver, &t.Created, &t.Closed)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Write err as its envelope, with the HTTP status of its code
+func writeError(w http.ResponseWriter, err error) {
+	e := envelope.Wrap(err)
+	status := http.StatusInternalServerError
+	switch e.Code {
+	case envelope.CodeNotFound:
+		status = http.StatusNotFound
+	case envelope.CodeInvalidArgument:
+		status = http.StatusBadRequest
+	case codeMethodNotAllowed:
+		status = http.StatusMethodNotAllowed
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write([]byte(e.Error()))
+}
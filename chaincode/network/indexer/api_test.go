@@ -0,0 +1,104 @@
+package indexer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"example.org/lib/envelope"
+)
+
+func TestAPI(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	ix := open(t, dir)
+	defer ix.Close()
+	check(t, ix.Run(FileSource{replayFile(t, dir)}))
+
+	api, err := OpenAPI(filepath.Join(dir, "index.db"))
+	check(t, err)
+	defer api.Close()
+	get := func(method string, target string, v interface{}) int {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		api.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+		check(t, json.Unmarshal(recorder.Body.Bytes(), v))
+		return recorder.Code
+	}
+
+	var transfers []Transfer
+	if code := get("GET", "/transfers?district=BLR&status=approved", &transfers); code != http.StatusOK || len(transfers) != 1 || transfers[0].ID != "T1" {
+		t.Error("unexpected approved transfers of BLR", code, transfers)
+	}
+	get("GET", "/transfers?from="+strconv.Itoa(transfers[0].Created+1), &transfers)
+	if len(transfers) != 2 || transfers[0].ID != "T2" || transfers[1].ID != "T3" {
+		t.Error("unexpected transfers requested after T1", transfers)
+	}
+
+	var transfer Transfer
+	get("GET", "/transfers/T3", &transfer)
+	if transfer.Status != "declined" || transfer.Reason != "Unpaid stamp duty" || len(transfer.StageChanges) != 3 || len(transfer.Cases) != 3 {
+		t.Error("unexpected T3", transfer)
+	}
+
+	// Processing time is reported for approved transfers only
+	var reports []DistrictReport
+	get("GET", "/reports/transfers", &reports)
+	// T3 is reported within MYS, where its Land moved before it was declined
+	if len(reports) != 2 || reports[0].District != "BLR" || reports[0].Transfers != 1 || reports[0].Approved != 1 || reports[1].Transfers != 2 || reports[1].Declined != 1 {
+		t.Fatal("unexpected report", reports)
+	}
+	var t1 Transfer
+	get("GET", "/transfers/T1", &t1)
+	if reports[0].AverageProcessingTime != float64(t1.Closed-t1.Created) {
+		t.Error("unexpected processing time", reports[0].AverageProcessingTime, "for T1 of", t1.Created, t1.Closed)
+	}
+
+	var land Land
+	if get("GET", "/lands/LAND2", &land); land.Owner != "C2" || land.District != "MYS" || land.Transferred == 0 {
+		t.Error("unexpected LAND2", land)
+	}
+	var professionals []Professional
+	get("GET", "/professionals?chaincode=blro_cc", &professionals)
+	if len(professionals) != 2 || professionals[0].Professional != "B1" || professionals[0].Completed != 1 || professionals[0].Active != 1 ||
+		professionals[0].Rank != "" || professionals[1].Rank != "senior" || professionals[1].Role != "blro" || professionals[1].Status != "active" {
+		t.Error("unexpected BLROs", professionals)
+	}
+	var status Status
+	if get("GET", "/status", &status); status.NextBlock == 0 {
+		t.Error("unexpected status", status)
+	}
+
+	// Errors are envelopes
+	for target, expected := range map[string]string{
+		"/lands/LAND9":             envelope.CodeNotFound,
+		"/transfers?from=tomorrow": envelope.CodeInvalidArgument,
+		"/lands?district=BLR&x=1":  envelope.CodeInvalidArgument,
+		"/ledger":                  envelope.CodeNotFound,
+	} {
+		var e struct {
+			Code string `json:"code"`
+		}
+		recorder := httptest.NewRecorder()
+		api.ServeHTTP(recorder, httptest.NewRequest("GET", target, nil))
+		json.Unmarshal(recorder.Body.Bytes(), &e)
+		if e.Code != expected {
+			t.Error(target, "expected", expected, "got", recorder.Code, strings.TrimSpace(recorder.Body.String()))
+		}
+	}
+
+	// The API is read only, as is its connection
+	var e struct {
+		Code string `json:"code"`
+	}
+	if code := get("POST", "/lands", &e); code != http.StatusMethodNotAllowed {
+		t.Error("POST answered with", code)
+	}
+	if _, err := api.db.Exec("DELETE FROM lands"); err == nil {
+		t.Error("API connection is writable")
+	}
+}
@@ -0,0 +1,51 @@
+package indexer
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
+)
+
+// EventClient streams the blocks of a channel through the fabric-sdk-go event client, which subscribes to the
+// deliver service of a peer of the channel, never of an orderer, so only blocks a peer committed are delivered
+type EventClient struct {
+	Channel context.ChannelProvider
+}
+
+// Deliver subscribes to the full blocks of the channel from block from on
+func (c EventClient) Deliver(from uint64) (<-chan *common.Block, func(), error) {
+	client, err := event.New(c.Channel, event.WithBlockEvents(), event.WithSeekType(seek.FromBlock), event.WithBlockNum(from))
+	if err != nil {
+		return nil, nil, err
+	}
+	registration, events, err := client.RegisterBlockEvent()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Forward the blocks of the events until the subscription ends, or it is stopped
+	blocks := make(chan *common.Block)
+	done := make(chan struct{})
+	go func() {
+		defer close(blocks)
+		for e := range events {
+			select {
+			case blocks <- e.Block:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			client.Unregister(registration)
+		})
+	}
+	return blocks, stop, nil
+}
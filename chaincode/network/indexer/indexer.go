@@ -0,0 +1,310 @@
+// Package indexer projects the Events the chaincodes emit into an SQLite database, off the chain, for the
+// reporting queries the chaincodes cannot answer from their state, e.g. the transfers of a district over a
+// quarter, or how long they took to approve.
+//
+// Blocks come from a Source, the deliver stream of a peer or a replay file, and each is applied in one SQL
+// transaction along with the number of the next block to apply, so an interrupted indexer resumes where it
+// stopped. The database is only written by the Indexer; the API reads it through a read-only connection.
+//
+// The SQLite driver uses cgo, so building this package needs a C compiler.
+package indexer
+
+import (
+	"database/sql"
+	"fmt"
+
+	"example.org/lib/contract"
+	"example.org/lib/events"
+	_ "github.com/mattn/go-sqlite3" // registers the sqlite3 driver
+)
+
+// Schema of the database, the lands, transfers, cases and profiles the Events describe, and the Events themselves.
+// The professionals view is recreated on Open, so databases of earlier versions report the profiles too.
+const schema = `
+CREATE TABLE IF NOT EXISTS checkpoint (
+	id         INTEGER PRIMARY KEY CHECK (id = 0),
+	next_block INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS events (
+	block     INTEGER NOT NULL,
+	tx_id     TEXT NOT NULL,
+	seq       INTEGER NOT NULL,
+	chaincode TEXT NOT NULL,
+	function  TEXT NOT NULL,
+	type      TEXT NOT NULL,
+	payload   TEXT NOT NULL,
+	PRIMARY KEY (tx_id, seq)
+);
+CREATE TABLE IF NOT EXISTS lands (
+	id          TEXT PRIMARY KEY,
+	owner       TEXT NOT NULL,
+	state       TEXT NOT NULL,
+	district    TEXT NOT NULL,
+	office_id   TEXT NOT NULL,
+	blro        TEXT NOT NULL,
+	created     INTEGER NOT NULL,
+	transferred INTEGER
+);
+CREATE TABLE IF NOT EXISTS transfers (
+	id        TEXT PRIMARY KEY,
+	land_id   TEXT NOT NULL,
+	state     TEXT,
+	district  TEXT,
+	office_id TEXT,
+	from_id   TEXT NOT NULL,
+	to_id     TEXT NOT NULL,
+	requester TEXT NOT NULL,
+	workflow  TEXT NOT NULL,
+	stage     TEXT NOT NULL,
+	status    TEXT NOT NULL,
+	assignee  TEXT NOT NULL,
+	reason    TEXT NOT NULL DEFAULT '',
+	approver  TEXT NOT NULL DEFAULT '',
+	created   INTEGER NOT NULL,
+	closed    INTEGER
+);
+CREATE INDEX IF NOT EXISTS transfers_district ON transfers (district, created);
+CREATE TABLE IF NOT EXISTS stage_changes (
+	transfer_id    TEXT NOT NULL,
+	tx_id          TEXT NOT NULL,
+	previous_stage TEXT NOT NULL,
+	stage          TEXT NOT NULL,
+	status         TEXT NOT NULL,
+	assignee       TEXT NOT NULL,
+	reason         TEXT NOT NULL,
+	date           INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS cases (
+	transfer_id  TEXT NOT NULL,
+	chaincode    TEXT NOT NULL,
+	professional TEXT NOT NULL,
+	stage        TEXT NOT NULL,
+	status       TEXT NOT NULL,
+	assigned     INTEGER NOT NULL,
+	closed       INTEGER,
+	PRIMARY KEY (transfer_id, chaincode, professional)
+);
+CREATE TABLE IF NOT EXISTS profiles (
+	chaincode    TEXT NOT NULL,
+	professional TEXT NOT NULL,
+	role         TEXT NOT NULL,
+	name         TEXT NOT NULL DEFAULT '',
+	status       TEXT NOT NULL DEFAULT '',
+	rank         TEXT NOT NULL DEFAULT '',
+	licence      TEXT NOT NULL DEFAULT '',
+	valid_until  INTEGER,
+	created      INTEGER,
+	PRIMARY KEY (chaincode, professional)
+);
+DROP VIEW IF EXISTS professionals;
+CREATE VIEW professionals AS
+	SELECT k.chaincode, k.professional,
+		COALESCE(p.role, '') AS role,
+		COALESCE(p.name, '') AS name,
+		COALESCE(p.status, '') AS status,
+		COALESCE(p.rank, '') AS rank,
+		COALESCE(c.active, 0) AS active,
+		COALESCE(c.completed, 0) AS completed,
+		COALESCE(c.removed, 0) AS removed
+	FROM (SELECT chaincode, professional FROM profiles UNION SELECT chaincode, professional FROM cases) k
+	LEFT JOIN profiles p ON p.chaincode = k.chaincode AND p.professional = k.professional
+	LEFT JOIN (SELECT chaincode, professional,
+			SUM(status = 'active') AS active,
+			SUM(status = 'completed') AS completed,
+			SUM(status = 'removed') AS removed
+		FROM cases GROUP BY chaincode, professional) c ON c.chaincode = k.chaincode AND c.professional = k.professional;
+`
+
+// Statuses of transfers, those of StageChanged and approved once RequestApproved
+const (
+	statusOpen     = "open"
+	statusApproved = "approved"
+)
+
+// Indexer applies blocks to the database at its path
+type Indexer struct {
+	db   *sql.DB
+	path string
+}
+
+// Open the database at path, creating it if it does not exist
+func Open(path string) (*Indexer, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	// A single writer, so that blocks are applied one after the other
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(schema)
+	if err == nil {
+		_, err = db.Exec("INSERT OR IGNORE INTO checkpoint (id, next_block) VALUES (0, 0)")
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Indexer{db, path}, nil
+}
+
+// Close the database
+func (ix *Indexer) Close() error {
+	return ix.db.Close()
+}
+
+// Next block to apply, following the last block applied
+func (ix *Indexer) Next() (uint64, error) {
+	var next uint64
+	err := ix.db.QueryRow("SELECT next_block FROM checkpoint WHERE id = 0").Scan(&next)
+	return next, err
+}
+
+// Run applies the blocks of s, from the block following the last block applied, until s returns
+func (ix *Indexer) Run(s Source) error {
+	next, err := ix.Next()
+	if err != nil {
+		return err
+	}
+	return s.Blocks(next, ix.Apply)
+}
+
+// Apply the Events of b, with b as the last block applied. Blocks before the next block to apply have been
+// applied already and are skipped, so that a Source may deliver blocks again.
+func (ix *Indexer) Apply(b Block) error {
+	tx, err := ix.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var next uint64
+	err = tx.QueryRow("SELECT next_block FROM checkpoint WHERE id = 0").Scan(&next)
+	if err != nil || b.Number < next {
+		return err
+	}
+
+	for _, e := range b.Events {
+		if e.Name != contract.EventName {
+			continue
+		}
+		err = apply(tx, b.Number, e)
+		if err != nil {
+			return fmt.Errorf("block %d, transaction %s: %v", b.Number, e.TxID, err)
+		}
+	}
+
+	_, err = tx.Exec("UPDATE checkpoint SET next_block = ? WHERE id = 0", b.Number+1)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Apply the Events of the envelope of e, logging them along with it
+func apply(tx *sql.Tx, block uint64, e ChaincodeEvent) error {
+	envelope, typed, err := events.Parse(e.Payload)
+	if err != nil {
+		return err
+	}
+
+	for i, r := range envelope.Events {
+		_, err = tx.Exec("INSERT INTO events (block, tx_id, seq, chaincode, function, type, payload) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			block, envelope.TxID, i, envelope.Chaincode, envelope.Function, r.Type, string(r.Payload))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Events of types the events package does not know are only logged
+	for _, event := range typed {
+		err = project(tx, envelope, event)
+		if err != nil {
+			return fmt.Errorf("%s event: %v", event.EventType(), err)
+		}
+	}
+	return nil
+}
+
+// Project event of envelope into the lands, transfers, cases and profiles it changes
+func project(tx *sql.Tx, envelope *contract.EventEnvelope, event contract.Event) error {
+	txID := envelope.TxID
+	var err error
+	switch e := event.(type) {
+	case *events.LandCreated:
+		_, err = tx.Exec("INSERT OR REPLACE INTO lands (id, owner, state, district, office_id, blro, created) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			e.LandID, e.Owner, e.State, e.District, e.OfficeID, e.BLRO, e.Date)
+
+	case *events.LandTransferred:
+		_, err = tx.Exec("UPDATE lands SET owner = ?, transferred = ? WHERE id = ?", e.CurrentOwner, e.Date, e.LandID)
+
+	case *events.LandJurisdictionSet:
+		// Transfers of the Land not yet closed follow it to its new district; closed ones stay where they were decided
+		_, err = tx.Exec("UPDATE lands SET state = ?, district = ?, office_id = ? WHERE id = ?", e.State, e.District, e.OfficeID, e.LandID)
+		if err == nil {
+			_, err = tx.Exec("UPDATE transfers SET state = ?, district = ?, office_id = ? WHERE land_id = ? AND closed IS NULL",
+				e.State, e.District, e.OfficeID, e.LandID)
+		}
+
+	case *events.TransferRequestCreated:
+		// The transfer is reported within the district of its Land, when the Land is known
+		_, err = tx.Exec(`INSERT OR REPLACE INTO transfers
+			(id, land_id, state, district, office_id, from_id, to_id, requester, workflow, stage, status, assignee, created)
+			VALUES (?, ?, (SELECT state FROM lands WHERE id = ?), (SELECT district FROM lands WHERE id = ?),
+				(SELECT office_id FROM lands WHERE id = ?), ?, ?, ?, ?, ?, ?, ?, ?)`,
+			e.TransferRequestID, e.LandID, e.LandID, e.LandID, e.LandID, e.From, e.To, e.Requester, e.Workflow, e.Stage, statusOpen, e.Assignee, e.Date)
+
+	case *events.StageChanged:
+		_, err = tx.Exec("INSERT INTO stage_changes (transfer_id, tx_id, previous_stage, stage, status, assignee, reason, date) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			e.TransferRequestID, txID, e.PreviousStage, e.Stage, e.Status, e.Assignee, e.Reason, e.Date)
+		if err == nil {
+			// Only a closed transfer is done with; a declined one may be reopened on Appeal
+			_, err = tx.Exec("UPDATE transfers SET stage = ?, status = ?, assignee = ?, reason = ?, closed = CASE WHEN ? = 'closed' THEN ? END WHERE id = ?",
+				e.Stage, e.Status, e.Assignee, e.Reason, e.Status, e.Date, e.TransferRequestID)
+		}
+
+	case *events.RequestApproved:
+		_, err = tx.Exec("UPDATE transfers SET status = ?, approver = ?, closed = ? WHERE id = ?",
+			statusApproved, e.Approver, e.Date, e.TransferRequestID)
+
+	case *events.CaseAssigned:
+		_, err = tx.Exec("INSERT OR REPLACE INTO cases (transfer_id, chaincode, professional, stage, status, assigned) VALUES (?, ?, ?, ?, 'active', ?)",
+			e.TransferRequestID, e.Chaincode, e.Professional, e.Stage, e.Date)
+
+	case *events.CaseCompleted:
+		_, err = tx.Exec("UPDATE cases SET status = 'completed', closed = ? WHERE transfer_id = ? AND chaincode = ? AND professional = ?",
+			e.Date, e.TransferRequestID, e.Chaincode, e.Professional)
+
+	case *events.CaseRemoved:
+		_, err = tx.Exec("UPDATE cases SET status = 'removed', closed = ? WHERE transfer_id = ? AND chaincode = ? AND professional = ?",
+			e.Date, e.TransferRequestID, e.Chaincode, e.Professional)
+
+	// Profiles are emitted by the chaincode holding them, which keys them along with their ID
+	case *events.ProfileCreated:
+		_, err = tx.Exec("INSERT OR REPLACE INTO profiles (chaincode, professional, role, name, status, created) VALUES (?, ?, ?, ?, 'pending', ?)",
+			envelope.Chaincode, e.ProfileID, e.Role, e.Name, e.Date)
+
+	case *events.ProfileLicensed:
+		err = updateProfile(tx, envelope.Chaincode, e.ProfileID, e.Role, "status = ?, licence = ?, valid_until = ?", e.Status, e.LicenceNumber, e.ValidUntil)
+
+	case *events.ProfileStatusChanged:
+		err = updateProfile(tx, envelope.Chaincode, e.ProfileID, e.Role, "status = ?", e.Status)
+
+	case *events.ProfileRanked:
+		err = updateProfile(tx, envelope.Chaincode, e.ProfileID, e.Role, "rank = ?", e.Rank)
+	}
+	return err
+}
+
+// Update the columns of set of the Profile of chaincode with ID, adding it first if it was created before the
+// chaincode emitted ProfileCreated
+func updateProfile(tx *sql.Tx, chaincode string, ID string, role string, set string, args ...interface{}) error {
+	_, err := tx.Exec("INSERT OR IGNORE INTO profiles (chaincode, professional, role) VALUES (?, ?, ?)", chaincode, ID, role)
+	if err == nil {
+		_, err = tx.Exec("UPDATE profiles SET "+set+" WHERE chaincode = ? AND professional = ?", append(args, chaincode, ID)...)
+	}
+	return err
+}
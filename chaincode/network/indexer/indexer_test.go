@@ -0,0 +1,264 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"example.org/network"
+)
+
+// Offices of the two districts of the tests
+var (
+	bangalore = network.Office{State: "KA", District: "BLR", OfficeID: "SRO1"}
+	mysore    = network.Office{State: "KA", District: "MYS", OfficeID: "SRO2"}
+)
+
+// Replay file of a World where T1 transfers LAND1 of Bangalore and T2 LAND2 of Mysore, while T3, of LAND3
+// of Bangalore, is declined once LAND3 has moved to Mysore. B2 is then ranked senior, and L2, onboarded without
+// cases, suspended.
+func replayFile(t *testing.T, dir string) string {
+	w, err := network.New()
+	check(t, err)
+	citizen, err := w.Citizen("C1")
+	check(t, err)
+	_, err = w.Citizen("C2")
+	check(t, err)
+	lawyer, err := w.Lawyer("L1")
+	check(t, err)
+
+	transfers := map[network.Office][]network.Transfer{}
+	for i, o := range []network.Office{bangalore, mysore} {
+		suffix := string('1' + rune(i))
+		registryOfficer, err := w.RegistryOfficer("R"+suffix, o)
+		check(t, err)
+		blro, err := w.BLRO("B"+suffix, o)
+		check(t, err)
+		check(t, w.CreateLand(blro, "LAND"+suffix, "C1", o))
+		transfers[o] = append(transfers[o], network.Transfer{ID: "T" + suffix, LandID: "LAND" + suffix, To: "C2",
+			Citizen: citizen, Lawyer: lawyer, RegistryOfficer: registryOfficer, BLRO: blro})
+	}
+	first := transfers[bangalore][0]
+	check(t, w.CreateLand(first.BLRO, "LAND3", "C1", bangalore))
+	declined := first
+	declined.ID, declined.LandID = "T3", "LAND3"
+
+	check(t, w.CompleteTransfer(first))
+	check(t, w.CompleteTransfer(transfers[mysore][0]))
+	check(t, w.RequestTransfer(declined))
+	admin, err := w.Admin()
+	check(t, err)
+	_, err = w.Call(admin, "land_cc", "setLandJurisdiction", map[string]interface{}{"ID": "LAND3", "State": mysore.State, "District": mysore.District, "OfficeID": mysore.OfficeID})
+	check(t, err)
+	_, err = w.Call(declined.BLRO, "transfer_cc", "declineTransferRequest", map[string]interface{}{"ID": "T3", "Reason": "Unpaid stamp duty", "Date": w.Date()})
+	check(t, err)
+
+	_, err = w.Call(admin, "blro_cc", "setBLRORank", map[string]string{"ID": "B2", "Rank": "senior"})
+	check(t, err)
+	_, err = w.Lawyer("L2")
+	check(t, err)
+	_, err = w.Call(admin, "lawyer_cc", "setLawyerStatus", map[string]string{"ID": "L2", "Status": "suspended"})
+	check(t, err)
+
+	path := filepath.Join(dir, "blocks.jsonl")
+	f, err := os.Create(path)
+	check(t, err)
+	defer f.Close()
+	check(t, WriteBlocks(f, w.Events()))
+	return path
+}
+
+// Indexer of a new database in dir
+func open(t *testing.T, dir string) *Indexer {
+	ix, err := Open(filepath.Join(dir, "index.db"))
+	check(t, err)
+	return ix
+}
+
+// Fail the test on err
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Temporary directory, and the function removing it
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "indexer")
+	check(t, err)
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// Rows of a query of the database as strings, one per row
+func rows(t *testing.T, ix *Indexer, query string) []string {
+	t.Helper()
+	r, err := ix.db.Query(query)
+	check(t, err)
+	defer r.Close()
+	columns, err := r.Columns()
+	check(t, err)
+
+	var result []string
+	for r.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		check(t, r.Scan(pointers...))
+		row := ""
+		for i, v := range values {
+			if i > 0 {
+				row += " "
+			}
+			switch v := v.(type) {
+			case nil:
+				row += "-"
+			case []byte:
+				row += string(v)
+			default:
+				row += fmt.Sprint(v)
+			}
+		}
+		result = append(result, row)
+	}
+	check(t, r.Err())
+	return result
+}
+
+// Whether a and b hold the same strings in the same order
+func equal(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIndexer(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	ix := open(t, dir)
+	defer ix.Close()
+	check(t, ix.Run(FileSource{replayFile(t, dir)}))
+
+	if lands := rows(t, ix, "SELECT id, owner, district FROM lands ORDER BY id"); !equal(lands, []string{"LAND1 C2 BLR", "LAND2 C2 MYS", "LAND3 C1 MYS"}) {
+		t.Error("unexpected lands", lands)
+	}
+	expected := []string{"T1 BLR blro approved B1 closed", "T2 MYS blro approved B2 closed", "T3 MYS blro declined  open"}
+	transfers := rows(t, ix, "SELECT id, district, stage, status, approver, CASE WHEN closed IS NULL THEN 'open' ELSE 'closed' END FROM transfers ORDER BY id")
+	if !equal(transfers, expected) {
+		t.Error("unexpected transfers", transfers)
+	}
+	if changes := rows(t, ix, "SELECT previous_stage, stage, status FROM stage_changes WHERE transfer_id = 'T3' ORDER BY rowid"); !equal(changes, []string{
+		"lawyer registry open", "registry blro open", "blro blro declined",
+	}) {
+		t.Error("unexpected stage changes", changes)
+	}
+
+	// L1 completed T1 and T2, and still holds T3, while L2 holds no case
+	professionals := rows(t, ix, "SELECT professional, name, status, active, completed FROM professionals WHERE chaincode = 'lawyer_cc' ORDER BY professional")
+	if !equal(professionals, []string{"L1 Lawyer L1 active 1 2", "L2 Lawyer L2 suspended 0 0"}) {
+		t.Error("unexpected professionals", professionals)
+	}
+
+	// Each Event is logged, in the order committed
+	if types := rows(t, ix, "SELECT type FROM events WHERE block = (SELECT MAX(block) FROM events WHERE function = 'approveTransferRequest') ORDER BY seq"); len(types) != 5 || types[4] != "LandTransferred" {
+		t.Error("unexpected events", types)
+	}
+}
+
+// Source failing once it has delivered a number of blocks, as a peer going away
+type failingSource struct {
+	Source
+	blocks int
+}
+
+func (s failingSource) Blocks(from uint64, deliver func(Block) error) error {
+	delivered := 0
+	return s.Source.Blocks(from, func(b Block) error {
+		if delivered == s.blocks {
+			return errors.New("peer went away")
+		}
+		delivered++
+		return deliver(b)
+	})
+}
+
+func TestResume(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	replay := FileSource{replayFile(t, dir)}
+
+	ix := open(t, dir)
+	if err := ix.Run(failingSource{replay, 6}); err == nil || err.Error() != "peer went away" {
+		t.Fatal("expected the source to fail, got", err)
+	}
+	next, err := ix.Next()
+	check(t, err)
+	check(t, ix.Close())
+
+	// The reopened indexer resumes at the block following the last it applied
+	ix = open(t, dir)
+	defer ix.Close()
+	var resumed []uint64
+	check(t, replay.Blocks(next, func(b Block) error {
+		resumed = append(resumed, b.Number)
+		return ix.Apply(b)
+	}))
+	if len(resumed) == 0 || resumed[0] != next {
+		t.Error("unexpected blocks resumed from", next, resumed)
+	}
+
+	// Blocks applied again are skipped
+	check(t, ix.Run(replay))
+	check(t, replay.Blocks(0, ix.Apply))
+
+	fullDir, cleanupFull := tempDir(t)
+	defer cleanupFull()
+	full := open(t, fullDir)
+	defer full.Close()
+	check(t, full.Run(replay))
+	for _, query := range []string{
+		"SELECT * FROM lands ORDER BY id",
+		"SELECT * FROM transfers ORDER BY id",
+		"SELECT * FROM cases ORDER BY transfer_id, chaincode, professional",
+		"SELECT * FROM events ORDER BY block, seq",
+		"SELECT * FROM checkpoint",
+	} {
+		if got, expected := rows(t, ix, query), rows(t, full, query); !equal(got, expected) {
+			t.Error(query, "of the resumed indexer", got, "expected", expected)
+		}
+	}
+}
+
+func TestReadBlocks(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	replay := FileSource{replayFile(t, dir)}
+
+	var numbers []uint64
+	check(t, replay.Blocks(10, func(b Block) error {
+		numbers = append(numbers, b.Number)
+		if len(b.Events) != 1 || b.Events[0].Chaincode == "" || b.Events[0].TxID == "" {
+			t.Error("unexpected block", b)
+		}
+		return nil
+	}))
+	if len(numbers) == 0 || numbers[0] < 10 {
+		t.Error("unexpected blocks from 10", numbers)
+	}
+	for i := 1; i < len(numbers); i++ {
+		if numbers[i] <= numbers[i-1] {
+			t.Error("blocks out of order", numbers)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package indexer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"example.org/network"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	sc "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Block is a block of the channel, with the chaincode events of its valid transactions in order
+type Block struct {
+	Number uint64           `json:"Number"`
+	Events []ChaincodeEvent `json:"Events"`
+}
+
+// ChaincodeEvent is the chaincode event a transaction set, its Payload the JSON the chaincode set
+type ChaincodeEvent struct {
+	TxID      string          `json:"TxID"`
+	Chaincode string          `json:"Chaincode"`
+	Name      string          `json:"Name"`
+	Payload   json.RawMessage `json:"Payload"`
+}
+
+// Source delivers the blocks of the channel, from block from on and in order, to deliver.
+// It returns once it has no more blocks to deliver, or deliver fails.
+type Source interface {
+	Blocks(from uint64, deliver func(Block) error) error
+}
+
+// FileSource replays the blocks of a file written by WriteBlocks, one JSON Block per line, for local testing
+type FileSource struct {
+	Path string
+}
+
+// Blocks delivers the blocks of the file numbered from on
+func (s FileSource) Blocks(from uint64, deliver func(Block) error) error {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return ReadBlocks(f, from, deliver)
+}
+
+// ReadBlocks delivers the blocks numbered from on, read from r as WriteBlocks writes them
+func ReadBlocks(r io.Reader, from uint64, deliver func(Block) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var b Block
+		err := json.Unmarshal(scanner.Bytes(), &b) //unmarshal it aka JSON.parse()
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		if b.Number < from {
+			continue
+		}
+		err = deliver(b)
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// WriteBlocks writes the blocks holding the chaincode events of the World, one JSON Block per line
+func WriteBlocks(w io.Writer, events []network.Event) error {
+	var blocks []Block
+	for _, e := range events {
+		if len(blocks) == 0 || blocks[len(blocks)-1].Number != e.Block {
+			blocks = append(blocks, Block{Number: e.Block})
+		}
+		b := &blocks[len(blocks)-1]
+		b.Events = append(b.Events, ChaincodeEvent{e.TxID, e.Chaincode, e.Name, e.Payload})
+	}
+
+	encoder := json.NewEncoder(w)
+	for _, b := range blocks {
+		err := encoder.Encode(b)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliverer streams the blocks of the channel a peer commits, from block from on and as they are committed,
+// over the deliver service of the peer, e.g. an EventClient. The stream ends when stop is called, which
+// closes blocks, or earlier if the peer goes away.
+type Deliverer interface {
+	Deliver(from uint64) (blocks <-chan *common.Block, stop func(), err error)
+}
+
+// DeliverSource delivers the blocks a peer streams, each once it is committed
+type DeliverSource struct {
+	Deliverer Deliverer
+}
+
+// Blocks delivers the blocks the peer streams from from on, until the stream ends or deliver fails
+func (s DeliverSource) Blocks(from uint64, deliver func(Block) error) error {
+	blocks, stop, err := s.Deliverer.Deliver(from)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	next := from
+	for block := range blocks {
+		b, err := ParseBlock(block)
+		if err != nil {
+			return err
+		}
+
+		// A stream resumed after a reconnection may repeat blocks, but must not skip any
+		if b.Number < next {
+			continue
+		} else if b.Number > next {
+			return fmt.Errorf("block %d delivered while waiting for block %d", b.Number, next)
+		}
+		err = deliver(b)
+		if err != nil {
+			return err
+		}
+		next++
+	}
+	return fmt.Errorf("block stream ended while waiting for block %d", next)
+}
+
+// ParseBlock reads the chaincode events of the valid endorser transactions of a block, as the peer delivers it
+func ParseBlock(block *common.Block) (Block, error) {
+	if block == nil || block.Header == nil || block.Data == nil {
+		return Block{}, fmt.Errorf("block has no header or data")
+	}
+
+	// The committing peer flags each transaction of the block as valid or not, so a block lacking a flag
+	// for any of them was not committed by a peer, and none of its transactions can be trusted
+	var flags []byte
+	if block.Metadata != nil && len(block.Metadata.Metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		flags = block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	}
+	if len(flags) < len(block.Data.Data) {
+		return Block{}, fmt.Errorf("block %d: %d transaction flags for %d transactions", block.Header.Number, len(flags), len(block.Data.Data))
+	}
+
+	b := Block{Number: block.Header.Number}
+	for i, envelopeAsBytes := range block.Data.Data {
+		if sc.TxValidationCode(flags[i]) != sc.TxValidationCode_VALID {
+			continue
+		}
+		e, err := chaincodeEvent(envelopeAsBytes)
+		if err != nil {
+			return b, fmt.Errorf("block %d, transaction %d: %v", b.Number, i, err)
+		} else if e != nil {
+			b.Events = append(b.Events, *e)
+		}
+	}
+	return b, nil
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Chaincode event of the transaction of an envelope, nil if it is not an endorser transaction or set none
+func chaincodeEvent(envelopeAsBytes []byte) (*ChaincodeEvent, error) {
+	envelope := &common.Envelope{}
+	payload := &common.Payload{}
+	err := proto.Unmarshal(envelopeAsBytes, envelope)
+	if err == nil {
+		err = proto.Unmarshal(envelope.Payload, payload)
+	}
+	if err != nil {
+		return nil, err
+	} else if payload.Header == nil {
+		return nil, fmt.Errorf("payload has no header")
+	}
+
+	channelHeader := &common.ChannelHeader{}
+	err = proto.Unmarshal(payload.Header.ChannelHeader, channelHeader)
+	if err != nil {
+		return nil, err
+	} else if common.HeaderType(channelHeader.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+		return nil, nil
+	}
+
+	// Transaction => action => proposal response => chaincode action => event
+	transaction := &sc.Transaction{}
+	actionPayload := &sc.ChaincodeActionPayload{}
+	responsePayload := &sc.ProposalResponsePayload{}
+	action := &sc.ChaincodeAction{}
+	event := &sc.ChaincodeEvent{}
+	err = proto.Unmarshal(payload.Data, transaction)
+	if err == nil && len(transaction.Actions) > 0 {
+		err = proto.Unmarshal(transaction.Actions[0].Payload, actionPayload)
+	}
+	if err == nil && actionPayload.Action != nil {
+		err = proto.Unmarshal(actionPayload.Action.ProposalResponsePayload, responsePayload)
+	}
+	if err == nil {
+		err = proto.Unmarshal(responsePayload.Extension, action)
+	}
+	if err == nil {
+		err = proto.Unmarshal(action.Events, event)
+	}
+	if err != nil {
+		return nil, err
+	} else if event.EventName == "" {
+		return nil, nil
+	}
+	return &ChaincodeEvent{channelHeader.TxId, event.ChaincodeId, event.EventName, event.Payload}, nil
+}
@@ -0,0 +1,117 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	sc "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Marshal m, failing the test on error
+func marshal(t *testing.T, m proto.Message) []byte {
+	t.Helper()
+	bytes, err := proto.Marshal(m)
+	check(t, err)
+	return bytes
+}
+
+// Envelope of a transaction of type headerType, setting event if it is an endorser transaction
+func transaction(t *testing.T, headerType common.HeaderType, txID string, event *sc.ChaincodeEvent) []byte {
+	channelHeader := &common.ChannelHeader{Type: int32(headerType), ChannelId: "mainchannel", TxId: txID}
+	payload := &common.Payload{Header: &common.Header{ChannelHeader: marshal(t, channelHeader)}}
+	if headerType == common.HeaderType_ENDORSER_TRANSACTION {
+		action := &sc.ChaincodeAction{Events: marshal(t, event)}
+		responsePayload := &sc.ProposalResponsePayload{Extension: marshal(t, action)}
+		actionPayload := &sc.ChaincodeActionPayload{Action: &sc.ChaincodeEndorsedAction{ProposalResponsePayload: marshal(t, responsePayload)}}
+		payload.Data = marshal(t, &sc.Transaction{Actions: []*sc.TransactionAction{{Payload: marshal(t, actionPayload)}}})
+	}
+	return marshal(t, &common.Envelope{Payload: marshal(t, payload)})
+}
+
+func TestParseBlock(t *testing.T) {
+	event := func(payload string) *sc.ChaincodeEvent {
+		return &sc.ChaincodeEvent{ChaincodeId: "transfer_cc", TxId: "tx", EventName: "events", Payload: []byte(payload)}
+	}
+	block := &common.Block{
+		Header: &common.BlockHeader{Number: 7},
+		Data: &common.BlockData{Data: [][]byte{
+			transaction(t, common.HeaderType_ENDORSER_TRANSACTION, "tx1", event(`{"Version":1}`)),
+			transaction(t, common.HeaderType_ENDORSER_TRANSACTION, "tx2", event(`{"Version":2}`)),
+			transaction(t, common.HeaderType_CONFIG, "tx3", nil),
+			transaction(t, common.HeaderType_ENDORSER_TRANSACTION, "tx4", &sc.ChaincodeEvent{}),
+			transaction(t, common.HeaderType_ENDORSER_TRANSACTION, "tx5", event(`{"Version":5}`)),
+		}},
+		Metadata: &common.BlockMetadata{Metadata: [][]byte{{}, {}, {
+			byte(sc.TxValidationCode_VALID),
+			byte(sc.TxValidationCode_MVCC_READ_CONFLICT),
+			byte(sc.TxValidationCode_VALID),
+			byte(sc.TxValidationCode_VALID),
+			byte(sc.TxValidationCode_VALID),
+		}}},
+	}
+
+	// The invalid transaction, the config transaction and the one without an event are left out
+	b, err := ParseBlock(block)
+	check(t, err)
+	if b.Number != 7 || len(b.Events) != 2 {
+		t.Fatal("unexpected block", b)
+	}
+	if e := b.Events[1]; e.TxID != "tx5" || e.Chaincode != "transfer_cc" || e.Name != "events" || string(e.Payload) != `{"Version":5}` {
+		t.Error("unexpected event", e)
+	}
+
+	// A block not flagging every transaction valid or not was not committed by a peer
+	for _, metadata := range []*common.BlockMetadata{
+		nil,
+		{Metadata: [][]byte{{}, {}}},
+		{Metadata: [][]byte{{}, {}, {}}},
+		{Metadata: [][]byte{{}, {}, {byte(sc.TxValidationCode_VALID), byte(sc.TxValidationCode_VALID)}}},
+	} {
+		block.Metadata = metadata
+		if _, err = ParseBlock(block); err == nil {
+			t.Error("parsed a block with transaction flags", metadata)
+		}
+	}
+	if _, err = ParseBlock(&common.Block{Header: &common.BlockHeader{Number: 8}}); err == nil {
+		t.Error("parsed a block without data")
+	}
+}
+
+// Deliverer streaming blocks, as a peer does, then ending the stream
+type stream []*common.Block
+
+func (s stream) Deliver(from uint64) (<-chan *common.Block, func(), error) {
+	blocks := make(chan *common.Block, len(s))
+	for _, b := range s {
+		blocks <- b
+	}
+	close(blocks)
+	return blocks, func() {}, nil
+}
+
+func TestDeliverSource(t *testing.T) {
+	block := func(number uint64) *common.Block {
+		return &common.Block{Header: &common.BlockHeader{Number: number}, Data: &common.BlockData{}}
+	}
+
+	// Blocks repeated by a resumed stream are delivered once
+	var delivered []uint64
+	err := DeliverSource{stream{block(3), block(4), block(4), block(5)}}.Blocks(3, func(b Block) error {
+		delivered = append(delivered, b.Number)
+		return nil
+	})
+	if len(delivered) != 3 || delivered[2] != 5 || err == nil {
+		t.Error("unexpected delivery", delivered, err)
+	}
+
+	// A stream skipping a block fails rather than leave it out of the index
+	delivered = nil
+	err = DeliverSource{stream{block(3), block(5)}}.Blocks(3, func(b Block) error {
+		delivered = append(delivered, b.Number)
+		return nil
+	})
+	if len(delivered) != 1 || err == nil {
+		t.Error("skipped block not detected", delivered, err)
+	}
+}
@@ -0,0 +1,241 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Chaincode tracking the cases of each stage of the default Workflow
+var stageChaincodes = map[string]string{
+	"lawyer":   "lawyer_cc",
+	"registry": "registryoffice_cc",
+	"blro":     "blro_cc",
+}
+
+// Program of random calls across the chaincodes, each taking two bytes: the call, and who and what it concerns.
+// Most calls are made by the party entitled to them, the rest by anyone, so both succeed and fail.
+type program struct {
+	f        *fixture
+	members  map[string]*Identity
+	citizens []string
+	lands    []string
+	requests []string
+	appeals  []string
+}
+
+func newProgram(t *testing.T) *program {
+	f := newFixture(t)
+	p := &program{f: f, members: map[string]*Identity{}, citizens: []string{"C1", "C2"}, lands: []string{"LAND1"}}
+	for _, id := range append([]*Identity{f.citizen, f.buyer}, append(f.lawyers, append(f.registryOfficers, f.blros...)...)...) {
+		p.members[id.Name] = id
+	}
+	return p
+}
+
+// Run the calls of the program, checking the invariants after each, and give the first violation
+func (p *program) run(calls []byte) error {
+	for i := 0; i+1 < len(calls); i += 2 {
+		action := p.step(calls[i], calls[i+1])
+		if err := p.check(); err != nil {
+			return fmt.Errorf("after call %d, %s: %v", i/2, action, err)
+		}
+	}
+	return nil
+}
+
+// Make the call op with arg, whatever its outcome, and describe it
+func (p *program) step(op byte, arg byte) string {
+	f := p.f
+	n := int(arg)
+	date := f.Date()
+
+	// The party entitled to the call three times out of four, else anyone
+	by := func(entitled string) *Identity {
+		if n%4 != 0 && p.members[entitled] != nil {
+			return p.members[entitled]
+		}
+		names := []string{"C1", "C2", "L1", "L2", "R1", "R2", "B1", "B2"}
+		return p.members[names[(n/4)%len(names)]]
+	}
+	pick := func(from []string) string {
+		if len(from) == 0 {
+			return "none"
+		}
+		return from[(n/2)%len(from)]
+	}
+	call := func(id *Identity, fcn string, payload map[string]interface{}) string {
+		_, err := f.Call(id, "transfer_cc", fcn, payload)
+		return fmt.Sprintf("%s by %s %v: %v", fcn, id.Name, payload, err)
+	}
+
+	ID := pick(p.requests)
+	request := p.request(ID)
+	switch op % 10 {
+	case 0:
+		land := "LAND" + strconv.Itoa(len(p.lands)+1)
+		owner := pick(p.citizens)
+		if err := f.CreateLand(f.blros[n%2], land, owner, office); err != nil {
+			return "createLand: " + err.Error()
+		}
+		p.lands = append(p.lands, land)
+		return "createLand " + land + " of " + owner
+	case 1:
+		land := pick(p.lands)
+		owner := p.owner(land)
+		ID = "T" + strconv.Itoa(len(p.requests)+1)
+		p.requests = append(p.requests, ID)
+		to := p.citizens[n%len(p.citizens)]
+		return call(by(owner), "createTransferRequest", map[string]interface{}{"ID": ID, "To": to, "LandID": land, "Assignee": "L" + strconv.Itoa(n%2+1), "Date": date})
+	case 2:
+		return call(by(request.Assignees["lawyer"]), "transfer2RegistryOfficer", map[string]interface{}{"ID": ID, "RegistryOfficer": "R" + strconv.Itoa(n%2+1), "Date": date})
+	case 3:
+		return call(by(request.Assignees["lawyer"]), "autoTransfer2RegistryOfficer", map[string]interface{}{"ID": ID, "Date": date})
+	case 4:
+		return call(by(request.Assignees["registry"]), "transfer2BLRO", map[string]interface{}{"ID": ID, "BLRO": "B" + strconv.Itoa(n%2+1), "Date": date})
+	case 5:
+		return call(by(request.Assignees["blro"]), "approveTransferRequest", map[string]interface{}{"ID": ID, "Date": date})
+	case 6:
+		return call(by(request.Assignees["blro"]), "declineTransferRequest", map[string]interface{}{"ID": ID, "Reason": "Incomplete", "Date": date})
+	case 7:
+		appeal := "A" + strconv.Itoa(len(p.appeals)+1)
+		p.appeals = append(p.appeals, appeal)
+		return call(by(request.Requester), "fileAppeal", map[string]interface{}{"ID": appeal, "TransferRequestID": ID, "Grounds": "Complete", "Date": date})
+	case 8:
+		// The other BLRO reviews, and decides at once
+		appeal := p.request(ID).Appeal
+		reviewer := "B1"
+		if request.Assignees["blro"] == "B1" {
+			reviewer = "B2"
+		}
+		outcome := []string{"overturned", "upheld"}[n%2]
+		return call(by("B1"), "assignAppealReviewer", map[string]interface{}{"ID": appeal, "Reviewer": reviewer, "Date": date}) + "; " +
+			call(by(reviewer), "decideAppeal", map[string]interface{}{"ID": appeal, "Outcome": outcome, "Date": date})
+	default:
+		role := []string{"Lawyer", "RegistryOfficer", "BLRO"}[n%3]
+		prefix := map[string]string{"Lawyer": "L", "RegistryOfficer": "R", "BLRO": "B"}[role]
+		entitled := map[string]string{"Lawyer": request.Requester, "RegistryOfficer": request.Assignees["registry"], "BLRO": request.Assignees["blro"]}[role]
+		return call(by(entitled), "reassign"+role, map[string]interface{}{"ID": ID, role: prefix + strconv.Itoa((n/8)%2+1), "Date": date})
+	}
+}
+
+// TransferRequest with ID as stored, empty if there is none
+func (p *program) request(ID string) transferRequest {
+	var request transferRequest
+	json.Unmarshal(p.f.Stub("transfer_cc").State["transferRequest-"+ID], &request)
+	return request
+}
+
+// Owner of the Land with ID as stored
+func (p *program) owner(ID string) string {
+	var l parcel
+	json.Unmarshal(p.f.Stub("land_cc").State["land-"+ID], &l)
+	return l.Owner
+}
+
+// Check the invariants of the ledgers of every chaincode
+func (p *program) check() error {
+	f := p.f
+
+	// Land has one owner, the one its History last handed it to, and changes hands only from the owner requesting it
+	for key, value := range f.Stub("land_cc").State {
+		if !strings.HasPrefix(key, "land-") {
+			continue
+		}
+		var l parcel
+		if err := json.Unmarshal(value, &l); err != nil {
+			return err
+		}
+		last := l.History[len(l.History)-1]
+		if l.Owner != last.CurrentOwner {
+			return fmt.Errorf("%s owned by %s, last handed to %s", key, l.Owner, last.CurrentOwner)
+		}
+		for i, entry := range l.History[1:] {
+			request := p.request(entry.TransferRequest)
+			if entry.PreviousOwner != l.History[i].CurrentOwner || entry.PreviousOwner != request.Requester || entry.CurrentOwner != request.To {
+				return fmt.Errorf("%s handed from %s to %s by %s, requested by %s for %s", key, entry.PreviousOwner, entry.CurrentOwner, entry.TransferRequest, request.Requester, request.To)
+			}
+		}
+	}
+
+	// Cases of each professional, by chaincode
+	active := map[string]map[string][]string{}
+	completed := map[string]map[string][]string{}
+	for _, chaincode := range stageChaincodes {
+		active[chaincode], completed[chaincode] = map[string][]string{}, map[string][]string{}
+		for key, value := range f.Stub(chaincode).State {
+			if strings.HasPrefix(key, "\x00") {
+				continue
+			}
+			var profile struct {
+				ID             string
+				ActiveCases    []string
+				CompletedCases []string
+			}
+			if err := json.Unmarshal(value, &profile); err != nil {
+				return err
+			}
+			for _, c := range profile.ActiveCases {
+				active[chaincode][c] = append(active[chaincode][c], profile.ID)
+			}
+			for _, c := range profile.CompletedCases {
+				completed[chaincode][c] = append(completed[chaincode][c], profile.ID)
+			}
+		}
+	}
+
+	// An open request is an active case of exactly its Assignee of each stage it reached, and a completed
+	// one a completed case of exactly them, once
+	for _, ID := range p.requests {
+		request := p.request(ID)
+		for stage, chaincode := range stageChaincodes {
+			expected := []string{}
+			if assignee := request.Assignees[stage]; assignee != "" {
+				expected = []string{assignee}
+			}
+			open, closed := expected, []string{}
+			if request.Complete {
+				open, closed = closed, open
+			}
+			if !equal(active[chaincode][ID], open) || !equal(completed[chaincode][ID], closed) {
+				return fmt.Errorf("%s with %s %v is an active case of %v and a completed one of %v", ID, stage, expected, active[chaincode][ID], completed[chaincode][ID])
+			}
+		}
+	}
+	return nil
+}
+
+// Random programs, from the seed corpus in testdata/fuzz and those go test -fuzz=FuzzTransferInvariants makes of it
+func FuzzTransferInvariants(f *testing.F) {
+	f.Fuzz(func(t *testing.T, calls []byte) {
+		if err := newProgram(t).run(calls); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// Programs taking a request through each of its outcomes, so the calls the random ones make are known to succeed
+func TestTransferInvariantsReplay(t *testing.T) {
+	programs := map[string][]byte{
+		"approved":         {1, 1, 2, 1, 4, 1, 5, 1},
+		"auto transferred": {1, 1, 3, 1, 4, 1, 5, 1},
+		"reassigned":       {1, 1, 9, 3, 2, 1, 9, 7, 4, 1, 9, 2, 5, 1},
+		"overturned":       {1, 1, 2, 1, 4, 1, 6, 1, 7, 1, 8, 2, 5, 1},
+		"upheld":           {1, 1, 2, 1, 4, 1, 6, 1, 7, 1, 8, 1},
+		"sold twice":       {1, 1, 1, 1, 2, 1, 4, 1, 5, 1, 2, 2, 4, 2, 5, 2},
+	}
+	for name, calls := range programs {
+		p := newProgram(t)
+		if err := p.run(calls); err != nil {
+			t.Error(name, err)
+		}
+		if p.owner("LAND1") != "C2" && name != "upheld" {
+			t.Error(name, "did not transfer LAND1")
+		}
+		if request := p.request("T2"); name == "sold twice" && (!request.Complete || !request.Declined) {
+			t.Error(name, "left T2 of LAND1 C1 no longer owns open", request)
+		}
+	}
+}
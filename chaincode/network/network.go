@@ -0,0 +1,178 @@
+// Package network runs the five chaincodes of the land registry in one process, the way a peer
+// would for tests: each chaincode on a MockStub linked to the others on contract.Channel,
+// transactions proposed by synthetic X.509 identities, and the writes of a transaction
+// to every chaincode it reaches held back until it ends, so it reads only committed state,
+// then committed if it succeeds and discarded if it fails.
+package network
+
+import (
+	"strconv"
+
+	"example.org/blro_cc/blro"
+	"example.org/land_cc/land"
+	"example.org/lawyer_cc/lawyer"
+	"example.org/lib/contract"
+	"example.org/lib/envelope"
+	"example.org/lib/identitytest"
+	"example.org/registryoffice_cc/registryoffice"
+	"example.org/transfer_cc/transfer"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	sc "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Chaincodes of the network, in the order they are instantiated
+var Chaincodes = []string{"lawyer_cc", "registryoffice_cc", "blro_cc", "land_cc", "transfer_cc"}
+
+// Identity is a member of an organization of the World, enrolled by its CA
+type Identity = identitytest.Identity
+
+// World is a network of the chaincodes, each with a ledger of its own
+type World struct {
+	stubs map[string]*identitytest.Stub
+	txs   int
+	// Transactions committed, as the height of a chain grows
+	height int
+	// Chaincode events set by the transactions committed
+	events []Event
+	// CAs of the organizations members are enrolled by
+	ids *identitytest.Factory
+	// Admin licensing the professionals the World onboards, enrolled on first use
+	admin *Identity
+}
+
+// Event is the chaincode event a committed transaction set, as a peer delivers it to listeners.
+// Each transaction of the World is a block of its own, the first being Block 1.
+type Event struct {
+	Block     uint64
+	TxID      string
+	Chaincode string
+	Name      string
+	Payload   []byte
+}
+
+// New World running every chaincode, instantiated with their default identity mapping
+func New() (*World, error) {
+	constructors := map[string]func() (*contract.Chaincode, error){
+		"lawyer_cc":         lawyer.New,
+		"registryoffice_cc": registryoffice.New,
+		"blro_cc":           blro.New,
+		"land_cc":           land.New,
+		"transfer_cc":       transfer.New,
+	}
+
+	w := &World{stubs: map[string]*identitytest.Stub{}, ids: identitytest.NewFactory()}
+	for _, name := range Chaincodes {
+		cc, err := constructors[name]()
+		if err != nil {
+			return nil, err
+		}
+		stub := identitytest.NewStub(name, cc)
+		stub.Invoker = w.invoker(stub)
+		stub.Hold = true
+		w.stubs[name] = stub
+	}
+
+	for _, name := range Chaincodes {
+		res := w.stubs[name].MockInitAs(nil, w.txID(), [][]byte{[]byte("init")})
+		if res.Status != shim.OK {
+			return nil, envelope.Internal(name + " failed to instantiate: " + res.Message)
+		}
+		w.end(true)
+	}
+	return w, nil
+}
+
+// Stub of the chaincode called name, nil if the World does not run it
+func (w *World) Stub(name string) *identitytest.Stub {
+	return w.stubs[name]
+}
+
+// Enroll Name as a member of the organization of MSP, its certificate carrying attributes, e.g. role => blro
+func (w *World) Enroll(MSP string, Name string, attributes map[string]string) (*Identity, error) {
+	return w.ids.Enroll(MSP, Name, attributes)
+}
+
+// EnrollBy enrolls Name as a member of the organization of MSP through the CA with issuer CN CA
+func (w *World) EnrollBy(CA string, MSP string, Name string, attributes map[string]string) (*Identity, error) {
+	return w.ids.EnrollBy(CA, MSP, Name, attributes)
+}
+
+// Submit a transaction calling fcn of chaincode with args, proposed by id.
+// The writes of a transaction that fails are discarded on every chaincode, as they never reach the ledger.
+func (w *World) Submit(id *Identity, chaincode string, fcn string, args ...string) sc.Response {
+	return w.propose(id, chaincode, fcn, args, true)
+}
+
+// Evaluate a transaction calling fcn of chaincode with args, proposed by id, as a query: its writes are discarded
+func (w *World) Evaluate(id *Identity, chaincode string, fcn string, args ...string) sc.Response {
+	return w.propose(id, chaincode, fcn, args, false)
+}
+
+// Height of the World, the number of transactions committed to it
+func (w *World) Height() int {
+	return w.height
+}
+
+// Events set by the transactions committed to the World, in the order they were committed
+func (w *World) Events() []Event {
+	return w.events
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// ID of the next transaction
+func (w *World) txID() string {
+	w.txs++
+	return "tx" + strconv.Itoa(w.txs)
+}
+
+// Invoker of the chaincodes stub calls, running them on its transaction, as its creator, at its time
+func (w *World) invoker(stub *identitytest.Stub) func(string, [][]byte, string) sc.Response {
+	return func(chaincodeName string, args [][]byte, channel string) sc.Response {
+		other, ok := w.stubs[chaincodeName]
+		if !ok || channel != contract.Channel {
+			return shim.Error("chaincode " + chaincodeName + " not found on channel " + channel)
+		}
+
+		creator, _ := stub.GetCreator()
+		proposal, _ := stub.GetSignedProposal()
+		return other.Transact(stub.TxID, args, creator, proposal, stub.TxTimestamp)
+	}
+}
+
+// Run a transaction calling fcn of chaincode with args, proposed by id, committing it if it succeeds and commit is set
+func (w *World) propose(id *Identity, chaincode string, fcn string, args []string, commit bool) sc.Response {
+	stub, ok := w.stubs[chaincode]
+	if !ok {
+		return shim.Error(envelope.NotFound("Chaincode does not exist!", "Chaincode", chaincode).Error())
+	}
+
+	byteArgs := [][]byte{[]byte(fcn)}
+	for _, arg := range args {
+		byteArgs = append(byteArgs, []byte(arg))
+	}
+
+	res := stub.MockInvokeAs(id, w.txID(), byteArgs)
+	committed := commit && res.Status == shim.OK
+	w.end(committed)
+	if committed {
+		w.height++
+		if e := stub.Event; e != nil {
+			w.events = append(w.events, Event{uint64(w.height), e.TxId, e.ChaincodeId, e.EventName, e.Payload})
+		}
+	}
+	return res
+}
+
+// End the transaction on every chaincode it reached, committing its writes or discarding them
+func (w *World) end(commit bool) {
+	for _, stub := range w.stubs {
+		if commit {
+			stub.Commit()
+		} else {
+			stub.Discard()
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package network
+
+import (
+	"testing"
+
+	"example.org/lib/envelope"
+)
+
+// Office Land and professionals of the tests belong to
+var office = Office{"KA", "BLR", "SRO1"}
+
+// World with Land LAND1 of citizen C1, two licensed professionals of each kind over its office, and
+// TransferRequest T1 of LAND1 to C2, yet to be requested, through L1, R1 and B1
+type fixture struct {
+	*World
+	admin            *Identity
+	citizen, buyer   *Identity
+	lawyers          []*Identity
+	registryOfficers []*Identity
+	blros            []*Identity
+	transfer         Transfer
+}
+
+func newFixture(t *testing.T) *fixture {
+	w, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fixture{World: w}
+
+	f.admin, err = w.Admin()
+	check(t, err)
+	f.citizen, err = w.Citizen("C1")
+	check(t, err)
+	f.buyer, err = w.Citizen("C2")
+	check(t, err)
+	for _, ID := range []string{"1", "2"} {
+		lawyer, err := w.Lawyer("L" + ID)
+		check(t, err)
+		registryOfficer, err := w.RegistryOfficer("R"+ID, office)
+		check(t, err)
+		blro, err := w.BLRO("B"+ID, office)
+		check(t, err)
+		f.lawyers = append(f.lawyers, lawyer)
+		f.registryOfficers = append(f.registryOfficers, registryOfficer)
+		f.blros = append(f.blros, blro)
+	}
+
+	// Both BLROs may review the appeals of the other's declines
+	for _, blro := range f.blros {
+		_, err = w.Call(f.admin, "blro_cc", "setBLRORank", map[string]string{"ID": blro.Name, "Rank": "senior"})
+		check(t, err)
+	}
+
+	check(t, w.CreateLand(f.blros[0], "LAND1", "C1", office))
+	f.transfer = Transfer{"T1", "LAND1", "C2", f.citizen, f.lawyers[0], f.registryOfficers[0], f.blros[0]}
+	return f
+}
+
+// Fail the test on err
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Fail the test unless err is an envelope with code
+func expectCode(t *testing.T, err error, code string, action string) {
+	t.Helper()
+	if err == nil {
+		t.Error(action, "succeeded, expected", code)
+	} else if envelope.CodeOf(err) != code {
+		t.Error(action, "expected", code, "got", err)
+	}
+}
+
+// Definition of the fields of a TransferRequest the tests check
+type transferRequest struct {
+	To              string
+	Requester       string
+	Appeal          string
+	Assignees       map[string]string
+	Stage           string
+	Complete        bool
+	Declined        bool
+	Lawyer          string
+	RegistryOfficer string
+	BLRO            string
+	Reassignments   []struct{ Role, From, To string }
+	StatusHistory   []struct{ Status string }
+}
+
+// Definition of the fields of a Land the tests check
+type parcel struct {
+	Owner   string
+	History []struct {
+		PreviousOwner   string
+		CurrentOwner    string
+		TransferRequest string
+	}
+}
+
+func (f *fixture) transferRequest(t *testing.T, ID string) transferRequest {
+	t.Helper()
+	var request transferRequest
+	check(t, f.Read(f.citizen, "transfer_cc", "readTransferRequest", ID, &request))
+	return request
+}
+
+func (f *fixture) parcel(t *testing.T, ID string) parcel {
+	t.Helper()
+	var l parcel
+	check(t, f.Read(f.citizen, "land_cc", "readLand", ID, &l))
+	return l
+}
+
+// Cases of the professional id, from the chaincode holding their Profile
+func (f *fixture) cases(t *testing.T, id *Identity) (active []string, completed []string) {
+	t.Helper()
+	readers := map[string][]string{
+		"LawyerMSP":         {"lawyer_cc", "readLawyer"},
+		"RegistryOfficeMSP": {"registryoffice_cc", "readRegistryOfficer"},
+		"BLROMSP":           {"blro_cc", "readBLRO"},
+	}
+	var profile struct {
+		ActiveCases    []string
+		CompletedCases []string
+	}
+	check(t, f.Read(f.citizen, readers[id.MSP][0], readers[id.MSP][1], id.Name, &profile))
+	return profile.ActiveCases, profile.CompletedCases
+}
+
+// Fail the test unless the professional id has exactly the active and completed cases given
+func (f *fixture) expectCases(t *testing.T, id *Identity, active []string, completed []string) {
+	t.Helper()
+	gotActive, gotCompleted := f.cases(t, id)
+	if !equal(gotActive, active) || !equal(gotCompleted, completed) {
+		t.Error("cases of", id.Name, "expected", active, completed, "got", gotActive, gotCompleted)
+	}
+}
+
+func equal(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(a []string, s string) bool {
+	for _, e := range a {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCompleteTransfer(t *testing.T) {
+	f := newFixture(t)
+	check(t, f.RequestTransfer(f.transfer))
+
+	request := f.transferRequest(t, "T1")
+	if request.Stage != "blro" || request.Lawyer != "L1" || request.RegistryOfficer != "R1" || request.BLRO != "B1" {
+		t.Error("TransferRequest not forwarded to B1", request)
+	}
+	for _, id := range []*Identity{f.lawyers[0], f.registryOfficers[0], f.blros[0]} {
+		f.expectCases(t, id, []string{"T1"}, nil)
+	}
+
+	// The BLRO approves in one transaction, completing the case of every professional and moving the Land
+	check(t, f.ApproveTransfer(f.transfer))
+
+	request = f.transferRequest(t, "T1")
+	if !request.Complete || len(request.StatusHistory) != 4 {
+		t.Error("TransferRequest not complete", request)
+	}
+	l := f.parcel(t, "LAND1")
+	last := l.History[len(l.History)-1]
+	if l.Owner != "C2" || last.CurrentOwner != "C2" || last.TransferRequest != "T1" {
+		t.Error("Land not transferred", l)
+	}
+	for _, id := range []*Identity{f.lawyers[0], f.registryOfficers[0], f.blros[0]} {
+		f.expectCases(t, id, nil, []string{"T1"})
+	}
+
+	// The new owner can sell the Land on
+	f.transfer = Transfer{"T2", "LAND1", "C1", f.buyer, f.lawyers[1], f.registryOfficers[1], f.blros[1]}
+	check(t, f.CompleteTransfer(f.transfer))
+	if l := f.parcel(t, "LAND1"); l.Owner != "C1" || len(l.History) != 3 {
+		t.Error("Land not transferred back", l)
+	}
+}
+
+func TestApprovalIsAtomic(t *testing.T) {
+	f := newFixture(t)
+	check(t, f.RequestTransfer(f.transfer))
+
+	// land_cc fails last, once every case has been completed
+	delete(f.Stub("land_cc").State, "land-LAND1")
+	err := f.ApproveTransfer(f.transfer)
+	expected := `{"code":"NOT_FOUND","message":"Land does not exist!","details":{"ID":"LAND1","Chaincode":"land_cc"}}`
+	if err == nil || err.Error() != expected {
+		t.Fatal("approval of a missing Land not rejected", err)
+	}
+
+	// None of the writes made before it reach any ledger
+	if f.transferRequest(t, "T1").Complete {
+		t.Error("TransferRequest completed by a failed approval")
+	}
+	for _, id := range []*Identity{f.lawyers[0], f.registryOfficers[0], f.blros[0]} {
+		f.expectCases(t, id, []string{"T1"}, nil)
+	}
+}
+
+func TestAutoTransfer2RegistryOfficer(t *testing.T) {
+	f := newFixture(t)
+	check(t, f.CreateLand(f.blros[0], "LAND2", "C1", office))
+
+	// R1 already holds T0, so T1 goes to R2
+	busy := Transfer{"T0", "LAND2", "C2", f.citizen, f.lawyers[0], f.registryOfficers[0], f.blros[0]}
+	check(t, f.RequestTransfer(busy))
+	_, err := f.Call(f.citizen, "transfer_cc", "createTransferRequest", map[string]interface{}{"ID": "T1", "To": "C2", "LandID": "LAND1", "Assignee": "L1", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.lawyers[0], "transfer_cc", "autoTransfer2RegistryOfficer", map[string]interface{}{"ID": "T1", "Date": f.Date()})
+	check(t, err)
+
+	if request := f.transferRequest(t, "T1"); request.Stage != "registry" || request.RegistryOfficer != "R2" {
+		t.Error("TransferRequest not forwarded to the least busy RegistryOfficer", request)
+	}
+	f.expectCases(t, f.registryOfficers[1], []string{"T1"}, nil)
+}
+
+func TestReassignProfessionals(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.Call(f.citizen, "transfer_cc", "createTransferRequest", map[string]interface{}{"ID": "T1", "To": "C2", "LandID": "LAND1", "Assignee": "L1", "Date": f.Date()})
+	check(t, err)
+
+	// The requesting citizen moves the case to L2, who forwards it instead of L1
+	_, err = f.Call(f.citizen, "transfer_cc", "reassignLawyer", map[string]interface{}{"ID": "T1", "Lawyer": "L2", "Date": f.Date()})
+	check(t, err)
+	f.expectCases(t, f.lawyers[0], nil, nil)
+	f.expectCases(t, f.lawyers[1], []string{"T1"}, nil)
+	_, err = f.Call(f.lawyers[1], "transfer_cc", "transfer2RegistryOfficer", map[string]interface{}{"ID": "T1", "RegistryOfficer": "R1", "Date": f.Date()})
+	check(t, err)
+
+	// The registry office and the BLROs hand the case over within their jurisdiction
+	_, err = f.Call(f.registryOfficers[0], "transfer_cc", "reassignRegistryOfficer", map[string]interface{}{"ID": "T1", "RegistryOfficer": "R2", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.registryOfficers[1], "transfer_cc", "transfer2BLRO", map[string]interface{}{"ID": "T1", "BLRO": "B1", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.blros[0], "transfer_cc", "reassignBLRO", map[string]interface{}{"ID": "T1", "BLRO": "B2", "Date": f.Date()})
+	check(t, err)
+
+	request := f.transferRequest(t, "T1")
+	if len(request.Reassignments) != 3 || request.Lawyer != "L2" || request.RegistryOfficer != "R2" || request.BLRO != "B2" {
+		t.Error("professionals not reassigned", request)
+	}
+
+	// Only the new BLRO approves, completing the cases of the professionals it was reassigned to
+	f.transfer.BLRO = f.blros[0]
+	expectCode(t, f.ApproveTransfer(f.transfer), envelope.CodeAccessDenied, "approval by the previous BLRO")
+	f.transfer.BLRO = f.blros[1]
+	check(t, f.ApproveTransfer(f.transfer))
+	for _, id := range []*Identity{f.lawyers[1], f.registryOfficers[1], f.blros[1]} {
+		f.expectCases(t, id, nil, []string{"T1"})
+	}
+	for _, id := range []*Identity{f.lawyers[0], f.registryOfficers[0], f.blros[0]} {
+		f.expectCases(t, id, nil, nil)
+	}
+}
+
+func TestAppealOverturned(t *testing.T) {
+	f := newFixture(t)
+	check(t, f.RequestTransfer(f.transfer))
+	_, err := f.Call(f.blros[0], "transfer_cc", "declineTransferRequest", map[string]interface{}{"ID": "T1", "Reason": "Unpaid stamp duty", "Date": f.Date()})
+	check(t, err)
+	expectCode(t, f.ApproveTransfer(f.transfer), envelope.CodeInvalidState, "approval of a declined request")
+
+	// B2 reviews the appeal of the citizen and reopens the request, taking it over from B1 to approve
+	_, err = f.Call(f.citizen, "transfer_cc", "fileAppeal", map[string]interface{}{"ID": "A1", "TransferRequestID": "T1", "Grounds": "Duty paid", "Documents": []string{"receipt"}, "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.blros[0], "transfer_cc", "assignAppealReviewer", map[string]interface{}{"ID": "A1", "Reviewer": "B2", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.blros[1], "transfer_cc", "decideAppeal", map[string]interface{}{"ID": "A1", "Outcome": "overturned", "Date": f.Date()})
+	check(t, err)
+
+	if request := f.transferRequest(t, "T1"); request.Declined || request.Complete || request.Stage != "blro" || request.BLRO != "B2" {
+		t.Error("TransferRequest not reopened for the Reviewer", request)
+	}
+	f.expectCases(t, f.blros[0], nil, nil)
+	f.expectCases(t, f.blros[1], []string{"T1"}, nil)
+	expectCode(t, f.ApproveTransfer(f.transfer), envelope.CodeAccessDenied, "approval by the BLRO who declined")
+	f.transfer.BLRO = f.blros[1]
+	check(t, f.ApproveTransfer(f.transfer))
+	if l := f.parcel(t, "LAND1"); l.Owner != "C2" {
+		t.Error("Land not transferred", l)
+	}
+}
+
+func TestLandSoldTwice(t *testing.T) {
+	f := newFixture(t)
+	check(t, f.RequestTransfer(f.transfer))
+	second := Transfer{"T2", "LAND1", "C2", f.citizen, f.lawyers[1], f.registryOfficers[1], f.blros[1]}
+	check(t, f.RequestTransfer(second))
+	_, err := f.Call(f.blros[1], "transfer_cc", "declineTransferRequest", map[string]interface{}{"ID": "T2", "Reason": "Unpaid stamp duty", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.citizen, "transfer_cc", "fileAppeal", map[string]interface{}{"ID": "A1", "TransferRequestID": "T2", "Grounds": "Duty paid", "Date": f.Date()})
+	check(t, err)
+
+	// Approving T1 declines and closes T2, withdrawing its Appeal, so LAND1 changes hands once
+	check(t, f.ApproveTransfer(f.transfer))
+	if request := f.transferRequest(t, "T2"); !request.Complete || !request.Declined {
+		t.Error("TransferRequest of the sold Land left open", request)
+	}
+	var appeal struct{ Outcome string }
+	check(t, f.Read(f.citizen, "transfer_cc", "readAppeal", "A1", &appeal))
+	if appeal.Outcome != "withdrawn" {
+		t.Error("Appeal of the closed request not withdrawn", appeal)
+	}
+	for _, id := range []*Identity{f.lawyers[1], f.registryOfficers[1], f.blros[1]} {
+		f.expectCases(t, id, nil, []string{"T2"})
+	}
+	_, err = f.Call(f.blros[1], "transfer_cc", "assignAppealReviewer", map[string]interface{}{"ID": "A1", "Reviewer": "B1", "Date": f.Date()})
+	expectCode(t, err, envelope.CodeInvalidState, "review of a withdrawn Appeal")
+	if l := f.parcel(t, "LAND1"); l.Owner != "C2" || len(l.History) != 2 {
+		t.Error("Land not transferred once", l)
+	}
+}
+
+func TestAppealReviewerMustBeSenior(t *testing.T) {
+	f := newFixture(t)
+	junior, err := f.BLRO("B3", office)
+	check(t, err)
+	check(t, f.RequestTransfer(f.transfer))
+	_, err = f.Call(f.blros[0], "transfer_cc", "declineTransferRequest", map[string]interface{}{"ID": "T1", "Reason": "Unpaid stamp duty", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.citizen, "transfer_cc", "fileAppeal", map[string]interface{}{"ID": "A1", "TransferRequestID": "T1", "Grounds": "Duty paid", "Date": f.Date()})
+	check(t, err)
+
+	// B3 reviews only once an admin, not B3, ranks them senior
+	assignment := map[string]interface{}{"ID": "A1", "Reviewer": "B3", "Date": f.Date()}
+	_, err = f.Call(f.blros[0], "transfer_cc", "assignAppealReviewer", assignment)
+	expectCode(t, err, envelope.CodeInvalidArgument, "assignment of a junior Reviewer")
+	_, err = f.Call(junior, "blro_cc", "setBLRORank", map[string]string{"ID": "B3", "Rank": "senior"})
+	expectCode(t, err, envelope.CodeAccessDenied, "setBLRORank by the BLRO")
+	_, err = f.Call(f.admin, "blro_cc", "setBLRORank", map[string]string{"ID": "B3", "Rank": "senior"})
+	check(t, err)
+	_, err = f.Call(f.blros[0], "transfer_cc", "assignAppealReviewer", assignment)
+	check(t, err)
+}
+
+func TestDeclinedRequestClosedWithoutAppeal(t *testing.T) {
+	f := newFixture(t)
+	check(t, f.RequestTransfer(f.transfer))
+	_, err := f.Call(f.blros[0], "transfer_cc", "declineTransferRequest", map[string]interface{}{"ID": "T1", "Reason": "Disputed boundary", "Date": f.Date()})
+	check(t, err)
+
+	// The BLRO waits out the appeal deadline, while the citizen may waive the appeal at once
+	closing := map[string]interface{}{"ID": "T1", "Date": f.Date()}
+	_, err = f.Call(f.blros[0], "transfer_cc", "closeTransferRequest", closing)
+	expectCode(t, err, envelope.CodeInvalidState, "closing before the appeal deadline")
+	_, err = f.Call(f.citizen, "transfer_cc", "closeTransferRequest", closing)
+	check(t, err)
+
+	if request := f.transferRequest(t, "T1"); !request.Complete {
+		t.Error("TransferRequest not closed", request)
+	}
+	for _, id := range []*Identity{f.lawyers[0], f.registryOfficers[0], f.blros[0]} {
+		f.expectCases(t, id, nil, []string{"T1"})
+	}
+	_, err = f.Call(f.citizen, "transfer_cc", "fileAppeal", map[string]interface{}{"ID": "A1", "TransferRequestID": "T1", "Grounds": "Survey attached", "Date": f.Date()})
+	expectCode(t, err, envelope.CodeInvalidState, "appeal of a closed request")
+}
+
+func TestAppealUpheld(t *testing.T) {
+	f := newFixture(t)
+	check(t, f.RequestTransfer(f.transfer))
+	_, err := f.Call(f.blros[0], "transfer_cc", "declineTransferRequest", map[string]interface{}{"ID": "T1", "Reason": "Disputed boundary", "Date": f.Date()})
+	check(t, err)
+
+	// Cases stay active until the appeal closes the request, without moving the Land
+	f.expectCases(t, f.blros[0], []string{"T1"}, nil)
+	_, err = f.Call(f.citizen, "transfer_cc", "fileAppeal", map[string]interface{}{"ID": "A1", "TransferRequestID": "T1", "Grounds": "Survey attached", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.blros[0], "transfer_cc", "assignAppealReviewer", map[string]interface{}{"ID": "A1", "Reviewer": "B2", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.blros[1], "transfer_cc", "decideAppeal", map[string]interface{}{"ID": "A1", "Outcome": "upheld", "Date": f.Date()})
+	check(t, err)
+
+	if request := f.transferRequest(t, "T1"); !request.Complete {
+		t.Error("TransferRequest not closed", request)
+	}
+	if l := f.parcel(t, "LAND1"); l.Owner != "C1" {
+		t.Error("Land transferred on a declined request", l)
+	}
+	for _, id := range []*Identity{f.lawyers[0], f.registryOfficers[0], f.blros[0]} {
+		f.expectCases(t, id, nil, []string{"T1"})
+	}
+}
+
+func TestClarificationBlocksForwarding(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.Call(f.citizen, "transfer_cc", "createTransferRequest", map[string]interface{}{"ID": "T1", "To": "C2", "LandID": "LAND1", "Assignee": "L1", "Date": f.Date()})
+	check(t, err)
+
+	_, err = f.Call(f.lawyers[0], "transfer_cc", "requestClarification", map[string]interface{}{"TransferRequestID": "T1", "ID": "Q1", "Message": "Sale deed?", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.citizen, "transfer_cc", "replyComment", map[string]interface{}{"TransferRequestID": "T1", "ID": "Q1R", "Message": "Attached", "Document": "deed", "ReplyTo": "Q1", "Date": f.Date()})
+	check(t, err)
+	forward := map[string]interface{}{"ID": "T1", "RegistryOfficer": "R1", "Date": f.Date()}
+	_, err = f.Call(f.lawyers[0], "transfer_cc", "transfer2RegistryOfficer", forward)
+	expectCode(t, err, envelope.CodeInvalidState, "forwarding with an open clarification")
+
+	_, err = f.Call(f.lawyers[0], "transfer_cc", "resolveClarification", map[string]interface{}{"TransferRequestID": "T1", "ID": "Q1", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.lawyers[0], "transfer_cc", "transfer2RegistryOfficer", forward)
+	check(t, err)
+}
+
+func TestWorkflowArtefacts(t *testing.T) {
+	f := newFixture(t)
+	stages := []map[string]interface{}{
+		{"Name": "lawyer", "Title": "Lawyer", "MSP": "LawyerMSP", "CA": "ca.lawyer.lran.com", "Role": "lawyer", "Chaincode": "lawyer_cc", "Artefacts": []string{"deed"}},
+		{"Name": "blro", "Title": "BLRO", "MSP": "BLROMSP", "CA": "ca.blro.lran.com", "Role": "blro", "Chaincode": "blro_cc"},
+	}
+	_, err := f.Call(f.blros[0], "transfer_cc", "createWorkflow", map[string]interface{}{"ID": "direct", "Description": "Lawyer, then BLRO.", "Stages": stages})
+	check(t, err)
+	_, err = f.Call(f.citizen, "transfer_cc", "createTransferRequest", map[string]interface{}{"ID": "T1", "To": "C2", "LandID": "LAND1", "Assignee": "L1", "Date": f.Date(), "WorkflowID": "direct"})
+	check(t, err)
+
+	// The lawyer stage cannot be left without the deed
+	advance := map[string]interface{}{"ID": "T1", "Assignee": "B1", "Date": f.Date()}
+	_, err = f.Call(f.lawyers[0], "transfer_cc", "advanceTransferRequest", advance)
+	expectCode(t, err, envelope.CodeInvalidState, "advancing without the deed")
+	_, err = f.Call(f.lawyers[0], "transfer_cc", "addArtefact", map[string]interface{}{"ID": "T1", "Name": "deed", "Reference": "sha256:00", "Date": f.Date()})
+	check(t, err)
+	_, err = f.Call(f.lawyers[0], "transfer_cc", "advanceTransferRequest", advance)
+	check(t, err)
+
+	check(t, f.ApproveTransfer(f.transfer))
+	f.expectCases(t, f.lawyers[0], nil, []string{"T1"})
+	f.expectCases(t, f.registryOfficers[0], nil, nil)
+	if l := f.parcel(t, "LAND1"); l.Owner != "C2" {
+		t.Error("Land not transferred", l)
+	}
+}
@@ -0,0 +1,164 @@
+// Package peer calls the chaincodes of a network through fabric-sdk-go, as a user of one of its organizations:
+// transactions are endorsed by the peers of the channel, ordered, and waited for until the peers commit them.
+package peer
+
+import (
+	"encoding/json"
+	"flag"
+
+	"example.org/lib/envelope"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+)
+
+// Config of a Gateway: the fabric-sdk-go configuration of the network at Path, holding the credentials of
+// User of Org, the client organization of the configuration if empty, and the Channel of the chaincodes
+type Config struct {
+	Path    string
+	Org     string
+	User    string
+	Channel string
+}
+
+// Flags adds the flags configuring a Gateway to flags
+func Flags(flags *flag.FlagSet) *Config {
+	c := &Config{}
+	flags.StringVar(&c.Path, "config", "", "fabric-sdk-go configuration of the network, with the credentials of the user")
+	flags.StringVar(&c.Org, "org", "", "organization of the user, the client organization of the configuration if empty")
+	flags.StringVar(&c.User, "user", "Admin", "user the transactions are proposed by")
+	flags.StringVar(&c.Channel, "channel", "mainchannel", "channel the chaincodes are instantiated on")
+	return c
+}
+
+// Gateway calls the chaincodes of the channel as the user it was connected as
+type Gateway struct {
+	sdk     *fabsdk.FabricSDK
+	channel context.ChannelProvider
+	client  *channel.Client
+	ledger  *ledger.Client
+}
+
+// Connect to the channel as the user of the Config
+func (c *Config) Connect() (*Gateway, error) {
+	sdk, err := fabsdk.New(config.FromFile(c.Path))
+	if err != nil {
+		return nil, err
+	}
+	options := []fabsdk.ContextOption{fabsdk.WithUser(c.User)}
+	if c.Org != "" {
+		options = append(options, fabsdk.WithOrg(c.Org))
+	}
+	g := &Gateway{sdk: sdk, channel: sdk.ChannelContext(c.Channel, options...)}
+
+	g.client, err = channel.New(g.channel)
+	if err == nil {
+		g.ledger, err = ledger.New(g.channel)
+	}
+	if err != nil {
+		sdk.Close()
+		return nil, err
+	}
+	return g, nil
+}
+
+// Close the connections of the Gateway
+func (g *Gateway) Close() {
+	g.sdk.Close()
+}
+
+// Channel the Gateway is connected to, e.g. for the event client of an indexer.EventClient
+func (g *Gateway) Channel() context.ChannelProvider {
+	return g.channel
+}
+
+// Query evaluates fcn of chaincode with payload on an endorsing peer, without submitting a transaction
+func (g *Gateway) Query(chaincode string, fcn string, payload interface{}) ([]byte, error) {
+	request, err := newRequest(chaincode, fcn, payload)
+	if err != nil {
+		return nil, err
+	}
+	response, err := g.client.Query(request)
+	if err != nil {
+		return nil, rejected(err)
+	}
+	return response.Payload, nil
+}
+
+// Submit fcn of chaincode with payload, waiting for the transaction to commit, giving the payload of its response
+func (g *Gateway) Submit(chaincode string, fcn string, payload interface{}) ([]byte, error) {
+	request, err := newRequest(chaincode, fcn, payload)
+	if err != nil {
+		return nil, err
+	}
+	response, err := g.client.Execute(request)
+	if err != nil {
+		return nil, rejected(err)
+	}
+	return response.Payload, nil
+}
+
+// Height of the channel, as a peer of it has committed it
+func (g *Gateway) Height() (uint64, error) {
+	info, err := g.ledger.QueryInfo()
+	if err != nil {
+		return 0, err
+	}
+	return info.BCI.Height, nil
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Request calling fcn of chaincode with payload as its only argument, as JSON, or with no argument if payload is nil
+func newRequest(chaincode string, fcn string, payload interface{}) (channel.Request, error) {
+	request := channel.Request{ChaincodeID: chaincode, Fcn: fcn}
+	if payload != nil {
+		payloadJSONasBytes, err := json.Marshal(payload)
+		if err != nil {
+			return request, err
+		}
+		request.Args = [][]byte{payloadJSONasBytes}
+	}
+	return request, nil
+}
+
+// Error of a failed request: the envelope the chaincode rejected the proposal with, or else err itself
+func rejected(err error) error {
+	if e := rejection(err); e != nil {
+		return e
+	}
+	return err
+}
+
+// Envelope a chaincode rejected a proposal with, as the status of the endorsement failure holds it, or the
+// first of them if several endorsers failed; nil if err is not a rejection by the chaincode
+func rejection(err error) *envelope.Error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	if s.Group == status.ClientStatus && s.Code == status.MultipleErrors.ToInt32() {
+		for _, detail := range s.Details {
+			if err, ok := detail.(error); ok {
+				if e := rejection(err); e != nil {
+					return e
+				}
+			}
+		}
+		return nil
+	} else if s.Group != status.ChaincodeStatus {
+		return nil
+	}
+
+	e := envelope.Parse(s.Message)
+	if e.Code == envelope.CodeInternal && e.Message == s.Message {
+		// Not an envelope, e.g. a failure of the chaincode's shim
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,56 @@
+package peer
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/pkg/errors"
+)
+
+func TestRejection(t *testing.T) {
+	message := `{"code":"NOT_FOUND","message":"Land does not exist!","details":{"ID":"L9"}}`
+	rejectedBy := func(message string) error {
+		return status.New(status.ChaincodeStatus, 500, message, nil)
+	}
+
+	// The envelope of a chaincode, whether the SDK wraps its status or several endorsers failed
+	for _, err := range []error{
+		rejectedBy(message),
+		errors.WithMessage(rejectedBy(message), "Query failed"),
+		multi.New(status.New(status.EndorserClientStatus, int32(status.ConnectionFailed), "connection refused", nil), rejectedBy(message)),
+	} {
+		if e := rejection(err); e == nil || e.Code != "NOT_FOUND" || e.Detail("ID") != "L9" {
+			t.Error("unexpected rejection of", err, e)
+		}
+	}
+
+	for _, err := range []error{
+		errors.New("endorser client failed to connect"),
+		status.New(status.EndorserClientStatus, int32(status.ConnectionFailed), message, nil),
+		rejectedBy("make sure the chaincode land_cc has been successfully instantiated"),
+	} {
+		if e := rejection(err); e != nil {
+			t.Error("rejection parsed from", err, e)
+		}
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	for payload, want := range map[interface{}][]string{
+		nil:        nil,
+		"L1":       {`"L1"`},
+		struct{}{}: {`{}`},
+	} {
+		request, err := newRequest("land_cc", "fcn", payload)
+		if err != nil || request.ChaincodeID != "land_cc" || request.Fcn != "fcn" || len(request.Args) != len(want) {
+			t.Errorf("request of %v: %v, %v", payload, request, err)
+			continue
+		}
+		for i := range want {
+			if string(request.Args[i]) != want[i] {
+				t.Errorf("request of %v: %s", payload, request.Args[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package network
+
+import (
+	"encoding/json"
+
+	"example.org/lib/envelope"
+	"example.org/lib/registry"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	sc "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Office is a registry office, within the state => district => office hierarchy Land and professionals belong to
+type Office struct {
+	State    string
+	District string
+	OfficeID string
+}
+
+// Licence every professional onboarded by the World is granted, from the epoch until 2100
+var Licence = registry.LicenceArgs{LicenceNumber: "LIC-1", IssuingBody: "Registration Department", ValidFrom: 0, ValidUntil: 4102444800}
+
+// Transfer is a TransferRequest of Land LandID to the citizen To, requested by Citizen through the
+// default Workflow: first to Lawyer, then to RegistryOfficer, then to BLRO for approval
+type Transfer struct {
+	ID              string
+	LandID          string
+	To              string
+	Citizen         *Identity
+	Lawyer          *Identity
+	RegistryOfficer *Identity
+	BLRO            *Identity
+}
+
+// Call fcn of chaincode with payload marshalled as its only argument, none if nil, proposed by id.
+// A rejected transaction gives its envelope as error.
+func (w *World) Call(id *Identity, chaincode string, fcn string, payload interface{}) ([]byte, error) {
+	return w.call(w.Submit, id, chaincode, fcn, payload)
+}
+
+// Query evaluates fcn of chaincode with payload as Call does, discarding its writes
+func (w *World) Query(id *Identity, chaincode string, fcn string, payload interface{}) ([]byte, error) {
+	return w.call(w.Evaluate, id, chaincode, fcn, payload)
+}
+
+// Read the record with ID through fcn of chaincode, e.g. readLand of land_cc, into v
+func (w *World) Read(id *Identity, chaincode string, fcn string, ID string, v interface{}) error {
+	recordAsBytes, err := w.Query(id, chaincode, fcn, map[string]string{"ID": ID})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(recordAsBytes, v) //unmarshal it aka JSON.parse()
+}
+
+// Session is a member of the World calling its chaincodes, as a client of the network does
+type Session struct {
+	w  *World
+	id *Identity
+}
+
+// Session of the World as id
+func (w *World) Session(id *Identity) *Session {
+	return &Session{w, id}
+}
+
+// Query evaluates fcn of chaincode with payload, its writes discarded
+func (s *Session) Query(chaincode string, fcn string, payload interface{}) ([]byte, error) {
+	return s.w.Query(s.id, chaincode, fcn, payload)
+}
+
+// Submit a transaction calling fcn of chaincode with payload, giving its response
+func (s *Session) Submit(chaincode string, fcn string, payload interface{}) ([]byte, error) {
+	return s.w.Call(s.id, chaincode, fcn, payload)
+}
+
+// Height of the World
+func (s *Session) Height() (uint64, error) {
+	return uint64(s.w.Height()), nil
+}
+
+// Date of the next transaction, later than that of every transaction before it
+func (w *World) Date() int {
+	return w.txs + 1
+}
+
+// ---------------------------------------------
+// Members
+// ---------------------------------------------
+
+// Admin of the BLROMSP, licensing professionals and configuring the chaincodes
+func (w *World) Admin() (*Identity, error) {
+	if w.admin == nil {
+		admin, err := w.Enroll("BLROMSP", "admin", map[string]string{"role": "admin"})
+		if err != nil {
+			return nil, err
+		}
+		w.admin = admin
+	}
+	return w.admin, nil
+}
+
+// Citizen enrolled for the citizen Profile with ID
+func (w *World) Citizen(ID string) (*Identity, error) {
+	return w.Enroll("CitizenMSP", ID, map[string]string{"role": "citizen", "profileID": ID})
+}
+
+// Lawyer enrolled for the Profile with ID, created and licensed in lawyer_cc
+func (w *World) Lawyer(ID string) (*Identity, error) {
+	id, err := w.Enroll("LawyerMSP", ID, map[string]string{"role": "lawyer", "profileID": ID})
+	if err != nil {
+		return nil, err
+	}
+	return id, w.onboard(id, "lawyer_cc", "Lawyer", map[string]string{"ID": ID, "Name": "Lawyer " + ID, "CitizenID": "C-" + ID})
+}
+
+// RegistryOfficer enrolled for the Profile with ID at office o, created and licensed in registryoffice_cc
+func (w *World) RegistryOfficer(ID string, o Office) (*Identity, error) {
+	id, err := w.Enroll("RegistryOfficeMSP", ID, map[string]string{"role": "registryofficer", "profileID": ID, "state": o.State, "district": o.District, "officeID": o.OfficeID})
+	if err != nil {
+		return nil, err
+	}
+	return id, w.onboard(id, "registryoffice_cc", "RegistryOfficer", map[string]string{"ID": ID, "Name": "Registry Officer " + ID, "CitizenID": "C-" + ID})
+}
+
+// BLRO enrolled for the Profile with ID over the district of office o, created and licensed in blro_cc
+func (w *World) BLRO(ID string, o Office) (*Identity, error) {
+	id, err := w.Enroll("BLROMSP", ID, map[string]string{"role": "blro", "profileID": ID, "state": o.State, "district": o.District})
+	if err != nil {
+		return nil, err
+	}
+	return id, w.onboard(id, "blro_cc", "BLRO", map[string]string{"ID": ID, "Name": "BLRO " + ID, "Description": "BLRO of " + o.District})
+}
+
+// ---------------------------------------------
+// Transfers
+// ---------------------------------------------
+
+// CreateLand registers Land with ID at office o to the citizen Owner, by blro
+func (w *World) CreateLand(blro *Identity, ID string, Owner string, o Office) error {
+	land := map[string]interface{}{
+		"ID":       ID,
+		"Address":  ID + ", " + o.District,
+		"Owner":    Owner,
+		"Date":     w.Date(),
+		"State":    o.State,
+		"District": o.District,
+		"OfficeID": o.OfficeID,
+	}
+	_, err := w.Call(blro, "land_cc", "createLand", land)
+	return err
+}
+
+// RequestTransfer creates the TransferRequest t and forwards it by each of its professionals until it reaches its BLRO
+func (w *World) RequestTransfer(t Transfer) error {
+	steps := []struct {
+		by      *Identity
+		fcn     string
+		payload map[string]interface{}
+	}{
+		{t.Citizen, "createTransferRequest", map[string]interface{}{"ID": t.ID, "To": t.To, "LandID": t.LandID, "Assignee": t.Lawyer.Name}},
+		{t.Lawyer, "transfer2RegistryOfficer", map[string]interface{}{"ID": t.ID, "RegistryOfficer": t.RegistryOfficer.Name}},
+		{t.RegistryOfficer, "transfer2BLRO", map[string]interface{}{"ID": t.ID, "BLRO": t.BLRO.Name}},
+	}
+	for _, step := range steps {
+		step.payload["Date"] = w.Date()
+		_, err := w.Call(step.by, "transfer_cc", step.fcn, step.payload)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApproveTransfer approves the TransferRequest t by its BLRO, moving its Land to t.To
+func (w *World) ApproveTransfer(t Transfer) error {
+	_, err := w.Call(t.BLRO, "transfer_cc", "approveTransferRequest", map[string]interface{}{"ID": t.ID, "Date": w.Date()})
+	return err
+}
+
+// CompleteTransfer requests the TransferRequest t and approves it
+func (w *World) CompleteTransfer(t Transfer) error {
+	err := w.RequestTransfer(t)
+	if err != nil {
+		return err
+	}
+	return w.ApproveTransfer(t)
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Run fcn of chaincode with payload marshalled as its only argument, or with no argument if payload is nil,
+// through run, Submit or Evaluate
+func (w *World) call(run func(*Identity, string, string, ...string) sc.Response, id *Identity, chaincode string, fcn string, payload interface{}) ([]byte, error) {
+	var args []string
+	if payload != nil {
+		payloadJSONasBytes, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, string(payloadJSONasBytes))
+	}
+
+	res := run(id, chaincode, fcn, args...)
+	if res.Status != shim.OK {
+		return nil, envelope.Parse(res.Message)
+	}
+	return res.Payload, nil
+}
+
+// Create the Profile of professional id in chaincode through createName with profile, and license it by the Admin
+func (w *World) onboard(id *Identity, chaincode string, Name string, profile map[string]string) error {
+	_, err := w.Call(id, chaincode, "create"+Name, profile)
+	if err != nil {
+		return err
+	}
+
+	admin, err := w.Admin()
+	if err != nil {
+		return err
+	}
+	licence := Licence
+	licence.ID = id.Name
+	_, err = w.Call(admin, chaincode, "license"+Name, licence)
+	return err
+}
@@ -0,0 +1,150 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"example.org/lib/envelope"
+	"gopkg.in/yaml.v2"
+)
+
+// Scenario is a run of registry traffic: the members taking part, and the actions they take in order
+type Scenario struct {
+	Members []Member `json:"Members"`
+	Actions []Action `json:"Actions"`
+}
+
+// Member of a Scenario, enrolled with ID as its Role: admin, citizen, lawyer, registryofficer or blro.
+// Professionals are onboarded with an active Licence, registry officers at Office and BLROs over its district.
+type Member struct {
+	ID     string `json:"ID"`
+	Role   string `json:"Role"`
+	Office Office `json:"Office"`
+}
+
+// Action of a Scenario: the member By calls Function of Chaincode with Args as its payload.
+// Expect is the code the transaction is rejected with, e.g. ACCESS_DENIED, empty if it succeeds.
+type Action struct {
+	By        string                 `json:"By"`
+	Chaincode string                 `json:"Chaincode"`
+	Function  string                 `json:"Function"`
+	Args      map[string]interface{} `json:"Args"`
+	Expect    string                 `json:"Expect"`
+}
+
+// ParseScenario reads a Scenario written in YAML, or JSON as a subset of it
+func ParseScenario(scenarioAsBytes []byte) (Scenario, error) {
+	var s Scenario
+	var document interface{}
+	err := yaml.Unmarshal(scenarioAsBytes, &document)
+	if err != nil {
+		return s, err
+	}
+
+	// YAML maps decode with keys of any type, so go through JSON to get the Scenario, and Args JSON can marshal
+	scenarioJSONasBytes, err := json.Marshal(jsonValue(document))
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(scenarioJSONasBytes, &s) //unmarshal it aka JSON.parse()
+	return s, err
+}
+
+// Replay the Scenario on the World, enrolling its members then taking its actions in order, each reported to out.
+// Replay stops at the first action not ending as expected.
+func (w *World) Replay(s Scenario, out io.Writer) error {
+	members := map[string]*Identity{}
+	for _, m := range s.Members {
+		id, err := w.member(m)
+		if err != nil {
+			return fmt.Errorf("enrolling %s as %s: %v", m.ID, m.Role, err)
+		}
+		members[m.ID] = id
+		fmt.Fprintf(out, "enrolled %s as %s\n", m.ID, m.Role)
+	}
+
+	for i, a := range s.Actions {
+		id, ok := members[a.By]
+		if !ok {
+			return fmt.Errorf("action %d: %s is not a member of the scenario", i+1, a.By)
+		}
+		args := a.Args
+		if args == nil {
+			args = map[string]interface{}{}
+		}
+
+		_, err := w.Call(id, a.Chaincode, a.Function, args)
+		outcome, code := "ok", ""
+		if err != nil {
+			outcome, code = err.Error(), envelope.CodeOf(err)
+		}
+		fmt.Fprintf(out, "%d. %s %s.%s: %s\n", i+1, a.By, a.Chaincode, a.Function, outcome)
+		if code != a.Expect {
+			expected := a.Expect
+			if expected == "" {
+				expected = "success"
+			}
+			return fmt.Errorf("action %d: %s %s.%s expected %s", i+1, a.By, a.Chaincode, a.Function, expected)
+		}
+	}
+	return nil
+}
+
+// State of the World: the records of each chaincode by key, without the composite keys indexing them
+func (w *World) State() map[string]map[string]json.RawMessage {
+	state := map[string]map[string]json.RawMessage{}
+	for name, stub := range w.stubs {
+		records := map[string]json.RawMessage{}
+		for key, value := range stub.State {
+			if strings.HasPrefix(key, "\x00") {
+				continue
+			}
+			if !json.Valid(value) {
+				value, _ = json.Marshal(string(value))
+			}
+			records[key] = value
+		}
+		state[name] = records
+	}
+	return state
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Enroll the member m of a Scenario as its Role, onboarding professionals
+func (w *World) member(m Member) (*Identity, error) {
+	switch m.Role {
+	case "admin":
+		return w.Enroll("BLROMSP", m.ID, map[string]string{"role": "admin"})
+	case "citizen":
+		return w.Citizen(m.ID)
+	case "lawyer":
+		return w.Lawyer(m.ID)
+	case "registryofficer":
+		return w.RegistryOfficer(m.ID, m.Office)
+	case "blro":
+		return w.BLRO(m.ID, m.Office)
+	}
+	return nil, fmt.Errorf("unknown role %q", m.Role)
+}
+
+// Value decoded from YAML with its maps keyed by strings, as JSON has them
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = jsonValue(value)
+		}
+	}
+	return v
+}
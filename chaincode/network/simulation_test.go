@@ -0,0 +1,71 @@
+package network
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestReplayScenario(t *testing.T) {
+	scenarioAsBytes, err := ioutil.ReadFile("testdata/transfer.yaml")
+	check(t, err)
+	scenario, err := ParseScenario(scenarioAsBytes)
+	check(t, err)
+	w, err := New()
+	check(t, err)
+
+	var out strings.Builder
+	check(t, w.Replay(scenario, &out))
+	if !strings.Contains(out.String(), "2. C2 transfer_cc.createTransferRequest: "+`{"code":"ACCESS_DENIED"`) {
+		t.Error("refusal not reported", out.String())
+	}
+
+	// The exported state holds the records, not the composite keys indexing them
+	state := w.State()
+	var l parcel
+	check(t, json.Unmarshal(state["land_cc"]["land-LAND1"], &l))
+	if l.Owner != "C2" {
+		t.Error("LAND1 not transferred", l.Owner)
+	}
+	for name, records := range state {
+		for key := range records {
+			if strings.HasPrefix(key, "\x00") {
+				t.Error(name, "exports composite key", key)
+			}
+		}
+	}
+	if _, err := json.Marshal(state); err != nil {
+		t.Error("state does not marshal", err)
+	}
+}
+
+func TestReplayStopsOnUnexpectedOutcome(t *testing.T) {
+	scenario, err := ParseScenario([]byte(`{
+		"Members": [{"ID": "C1", "Role": "citizen"}],
+		"Actions": [
+			{"By": "C1", "Chaincode": "land_cc", "Function": "createLand", "Args": {"ID": "LAND1", "Address": "A", "Owner": "C1", "Date": 1, "State": "KA", "District": "BLR", "OfficeID": "SRO1"}},
+			{"By": "C1", "Chaincode": "land_cc", "Function": "readLand", "Args": {"ID": "LAND1"}}
+		]
+	}`))
+	check(t, err)
+	w, err := New()
+	check(t, err)
+
+	var out strings.Builder
+	err = w.Replay(scenario, &out)
+	if err == nil || !strings.HasPrefix(err.Error(), "action 1:") {
+		t.Error("citizen creating Land replayed", err)
+	}
+	if strings.Contains(out.String(), "2. ") {
+		t.Error("replay went on after action 1", out.String())
+	}
+
+	scenario.Actions[0].Expect = "ACCESS_DENIED"
+	scenario.Actions = scenario.Actions[:1]
+	w, err = New()
+	check(t, err)
+	if err := w.Replay(scenario, &out); err != nil {
+		t.Error("expected refusal not replayed", err)
+	}
+}
@@ -4,10 +4,17 @@
 
 package main
 
-import "github.com/hyperledger/fabric/core/chaincode/shim"
+import (
+	"example.org/registryoffice_cc/registryoffice"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
 
 func main() {
-	err := shim.Start(new(Chaincode))
+	cc, err := registryoffice.New()
+	if err != nil {
+		panic(err)
+	}
+	err = shim.Start(cc)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,199 @@
+// Package registryoffice is the registryoffice_cc chaincode, which holds the Profiles of RegistryOfficers and the cases they work on.
+package registryoffice
+
+import (
+	"encoding/json"
+
+	"example.org/lib/cases"
+	"example.org/lib/contract"
+	"example.org/lib/envelope"
+	"example.org/lib/identity"
+	"example.org/lib/query"
+	"example.org/lib/registry"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Chaincode is the definition of the chaincode structure.
+type Chaincode struct {
+	contract.Contract
+	contract.Mapping
+	contract.Ledger
+}
+
+// New routes the transactions of registryoffice_cc
+func New() (*contract.Chaincode, error) {
+	return contract.New("registryoffice_cc", &Chaincode{Ledger: registryOfficers.Ledger()})
+}
+
+// Definition of the RegistryOfficer structure
+// State, District and OfficeID are the office the RegistryOfficer's certificate was enrolled for.
+type RegistryOfficer struct {
+	registry.Professional
+	CitizenID string `json:"CitizenID"`
+	State     string `json:"State"`
+	District  string `json:"District"`
+	OfficeID  string `json:"OfficeID"`
+}
+
+// Registry of RegistryOfficers, stored with Key => registryofficer-ID
+var registryOfficers = registry.New(registry.Config{
+	Name:   "RegistryOfficer",
+	Prefix: "registryofficer",
+	Role:   "registryofficer",
+	New: func() registry.Profile {
+		return &RegistryOfficer{}
+	},
+	Fill: func(stub shim.ChaincodeStubInterface, p registry.Profile, fields []string) error {
+		// RegistryOfficers belong to the office their certificate was enrolled for, within its state and district
+		State, District, OfficeID, err := identity.GetTxCreatorJurisdiction(stub, "state", "district", "officeID")
+		if err != nil {
+			return err
+		}
+
+		r := p.(*RegistryOfficer)
+		r.CitizenID = fields[0]
+		r.State = State
+		r.District = District
+		r.OfficeID = OfficeID
+		return nil
+	},
+})
+
+// Payload of createRegistryOfficer
+type CreateRegistryOfficerArgs struct {
+	ID        string `json:"ID" validate:"required,key"`
+	Name      string `json:"Name" validate:"required"`
+	CitizenID string `json:"CitizenID" validate:"required,key"`
+}
+
+// Payload of getLeastBusyRegistryOfficer, an office given by State, District and OfficeID, or nothing
+type OfficeArgs struct {
+	State    string `json:"State"`
+	District string `json:"District"`
+	OfficeID string `json:"OfficeID"`
+}
+
+// Init stores the role => identity mapping the chaincode authenticates against
+func (cc *Chaincode) Init(ctx *contract.TransactionContext) error {
+	return identity.InitMapping(ctx.GetStub())
+}
+
+// BeforeTransaction authenticates the caller against the roles and chaincodes allowed to call the transaction
+func (cc *Chaincode) BeforeTransaction(ctx *contract.TransactionContext) error {
+	return registryOfficers.Authorize(ctx)
+}
+
+// Function to create new registryofficer (C of CRUD)
+func (cc *Chaincode) CreateRegistryOfficer(ctx *contract.TransactionContext, args CreateRegistryOfficerArgs) error {
+	return registryOfficers.Create(ctx, args.ID, args.Name, args.CitizenID)
+}
+
+// Function to read a registryofficer (R of CRUD)
+func (cc *Chaincode) ReadRegistryOfficer(ctx *contract.TransactionContext, args contract.IDArgs) (*RegistryOfficer, error) {
+	profile, err := registryOfficers.Get(ctx.GetStub(), args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return profile.(*RegistryOfficer), nil
+}
+
+// Function to bind a registryofficer to a renewed certificate (U of CRUD)
+func (cc *Chaincode) RekeyRegistryOfficer(ctx *contract.TransactionContext, args registry.RekeyArgs) error {
+	return registryOfficers.Rekey(ctx, args)
+}
+
+// Function to record the licence of a registryofficer (U of CRUD)
+func (cc *Chaincode) LicenseRegistryOfficer(ctx *contract.TransactionContext, args registry.LicenceArgs) error {
+	return registryOfficers.License(ctx, args)
+}
+
+// Function to suspend, reinstate or revoke a registryofficer (U of CRUD)
+func (cc *Chaincode) SetRegistryOfficerStatus(ctx *contract.TransactionContext, args registry.StatusArgs) error {
+	return registryOfficers.SetStatus(ctx, args)
+}
+
+// Function to add a case to a registryofficer (U of CRUD)
+func (cc *Chaincode) AddCase(ctx *contract.TransactionContext, args cases.Args) error {
+	return registryOfficers.AddCase(ctx, args)
+}
+
+// Function to complete a case of a registryofficer (U of CRUD)
+func (cc *Chaincode) CompleteCase(ctx *contract.TransactionContext, args cases.Args) error {
+	return registryOfficers.CompleteCase(ctx, args)
+}
+
+// Function to remove a case from a registryofficer on reassignment (U of CRUD)
+func (cc *Chaincode) RemoveCase(ctx *contract.TransactionContext, args cases.Args) error {
+	return registryOfficers.RemoveCase(ctx, args)
+}
+
+// Function to apply the changes to the cases of a registryofficer in one write (U of CRUD)
+func (cc *Chaincode) UpdateCases(ctx *contract.TransactionContext, args cases.UpdateArgs) error {
+	return registryOfficers.UpdateCases(ctx, args)
+}
+
+// Function to pick the licensed registryofficer, bound to a certificate, with the fewest ActiveCases (R of CRUD)
+// Ties are broken by the lowest ID so every endorsing peer picks the same officer.
+// An office given in full picks only from the officers of that office
+func (cc *Chaincode) GetLeastBusyRegistryOfficer(ctx *contract.TransactionContext, args OfficeArgs) (*RegistryOfficer, error) {
+	// Check the office is given in full, or not at all
+	byOffice := args != OfficeArgs{}
+	if byOffice {
+		var missing []string
+		for _, field := range [][]string{{"State", args.State}, {"District", args.District}, {"OfficeID", args.OfficeID}} {
+			if field[1] == "" {
+				missing = append(missing, field[0], "is required to pick from an office")
+			}
+		}
+		if len(missing) > 0 {
+			return nil, contract.InvalidArguments(missing...)
+		}
+	}
+
+	now, err := registry.TxTime(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+
+	// Range over every key with prefix "registryofficer-"
+	resultsIterator, err := query.ByPrefix(ctx.GetStub(), "registryofficer-")
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var leastBusy *RegistryOfficer
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		// Create new RegistryOfficer Variable
+		candidate := RegistryOfficer{}
+		err = json.Unmarshal(queryResponse.Value, &candidate) //unmarshal it aka JSON.parse()
+		if err != nil {
+			return nil, err
+		}
+
+		// Only officers licensed at the time of the transaction, and able to sign for their cases, can take them
+		if !candidate.Active(now) || candidate.KeyHash == "" {
+			continue
+		}
+		if byOffice && (candidate.State != args.State || candidate.District != args.District || candidate.OfficeID != args.OfficeID) {
+			continue
+		}
+
+		// Keys are returned in order, so only a strictly smaller workload replaces the pick
+		if leastBusy == nil || len(candidate.ActiveCases) < len(leastBusy.ActiveCases) {
+			leastBusy = &candidate
+		}
+	}
+
+	if leastBusy == nil {
+		return nil, envelope.NotFound("No RegistryOfficer available!")
+	}
+
+	// Returned on successful execution of the function
+	return leastBusy, nil
+}
@@ -0,0 +1,120 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package registryoffice
+
+import (
+	"encoding/json"
+	"testing"
+
+	"example.org/lib/identitytest"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+func TestInit(t *testing.T) {
+	stub := identitytest.Chaincode(t, "registryoffice_cc", New)
+	res := stub.MockInit("1", [][]byte{[]byte("initFunc")})
+	if res.Status != shim.OK {
+		t.Error("Init failed", res.Status, res.Message)
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	stub := identitytest.Chaincode(t, "registryoffice_cc", New)
+	res := stub.MockInit("1", [][]byte{[]byte("initFunc")})
+	if res.Status != shim.OK {
+		t.Error("Init failed", res.Status, res.Message)
+	}
+	res = stub.MockInvoke("2", [][]byte{[]byte("invokeFunc")})
+	expected := `{"code":"INVALID_ARGUMENT","message":"Received unknown function invocation!","details":{"Function":"invokeFunc"}}`
+	if res.Status == shim.OK || res.Message != expected {
+		t.Error("unknown function expected", expected, "got", res.Status, res.Message)
+	}
+}
+
+func TestGetLeastBusyRegistryOfficer(t *testing.T) {
+	stub := identitytest.Chaincode(t, "registryoffice_cc", New)
+
+	res := stub.MockInvoke("1", [][]byte{[]byte("getLeastBusyRegistryOfficer")})
+	if res.Status == shim.OK {
+		t.Error("getLeastBusyRegistryOfficer succeeded without any RegistryOfficer")
+	}
+
+	licence := `"KeyHash":"hash","Status":"active","ValidFrom":0,"ValidUntil":4102444800`
+	stub.MockTransactionStart("2")
+	stub.PutState("registryofficer-R3", []byte(`{"ID":"R3","ActiveCases":["T1"],`+licence+`}`))
+	stub.PutState("registryofficer-R2", []byte(`{"ID":"R2","ActiveCases":[],`+licence+`}`))
+	stub.PutState("registryofficer-R1", []byte(`{"ID":"R1","ActiveCases":["T2","T3"],`+licence+`}`))
+	stub.PutState("registryofficer-R4", []byte(`{"ID":"R4","ActiveCases":null,`+licence+`}`))
+	stub.PutState("registryofficer-R0", []byte(`{"ID":"R0","ActiveCases":[],"Status":"suspended","ValidFrom":0,"ValidUntil":4102444800}`))
+	stub.PutState("registryofficer-R00", []byte(`{"ID":"R00","ActiveCases":[],"KeyHash":"hash","Status":"active","ValidFrom":0,"ValidUntil":1}`))
+	// Licensed with the fewest cases, but bound to no certificate to act on them
+	stub.PutState("registryofficer-R01", []byte(`{"ID":"R01","ActiveCases":[],"Status":"active","ValidFrom":0,"ValidUntil":4102444800}`))
+	stub.MockTransactionEnd("2")
+
+	res = stub.MockInvoke("3", [][]byte{[]byte("getLeastBusyRegistryOfficer")})
+	if res.Status != shim.OK {
+		t.Fatal("getLeastBusyRegistryOfficer failed", res.Message)
+	}
+	picked := RegistryOfficer{}
+	if err := json.Unmarshal(res.Payload, &picked); err != nil {
+		t.Fatal(err)
+	}
+	if picked.ID != "R2" {
+		t.Error("expected R2, got", picked.ID)
+	}
+
+	stub.MockTransactionStart("4")
+	stub.PutState("registryofficer-R5", []byte(`{"ID":"R5","ActiveCases":["T4"],"State":"KA","District":"Mysuru","OfficeID":"SRO1",`+licence+`}`))
+	stub.MockTransactionEnd("4")
+
+	res = stub.MockInvoke("5", [][]byte{[]byte("getLeastBusyRegistryOfficer"), []byte(`{"State":"KA","District":"Mysuru","OfficeID":"SRO1"}`)})
+	if res.Status != shim.OK {
+		t.Fatal("getLeastBusyRegistryOfficer failed", res.Message)
+	}
+	if err := json.Unmarshal(res.Payload, &picked); err != nil {
+		t.Fatal(err)
+	}
+	if picked.ID != "R5" {
+		t.Error("expected R5 of office SRO1, got", picked.ID)
+	}
+
+	res = stub.MockInvoke("6", [][]byte{[]byte("getLeastBusyRegistryOfficer"), []byte(`{"State":"KA"}`)})
+	if res.Status == shim.OK || res.Message != `{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"District":"is required to pick from an office","OfficeID":"is required to pick from an office"}}` {
+		t.Error("office given in part not rejected", res.Message)
+	}
+}
+
+func TestCreateRegistryOfficerAtEnrolledOffice(t *testing.T) {
+	stub, ids := identitytest.Instantiate(t, "registryoffice_cc", New)
+	officer, err := ids.Enroll("RegistryOfficeMSP", "R1", map[string]string{"role": "registryofficer", "profileID": "R1", "state": "KA", "district": "BLR", "officeID": "SRO1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unplaced, err := ids.Enroll("RegistryOfficeMSP", "R2", map[string]string{"role": "registryofficer", "profileID": "R2", "state": "KA", "district": "BLR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The office comes from the certificate, not the payload
+	res := stub.MockInvokeAs(officer, "1", [][]byte{[]byte("createRegistryOfficer"), []byte(`{"ID":"R1","Name":"Registry Officer","CitizenID":"C9"}`)})
+	if res.Status != shim.OK {
+		t.Fatal("createRegistryOfficer failed", res.Message)
+	}
+	read := RegistryOfficer{}
+	if err := json.Unmarshal(stub.State["registryofficer-R1"], &read); err != nil || read.State != "KA" || read.District != "BLR" || read.OfficeID != "SRO1" || read.CitizenID != "C9" {
+		t.Error("unexpected RegistryOfficer", string(stub.State["registryofficer-R1"]))
+	}
+
+	res = stub.MockInvokeAs(unplaced, "2", [][]byte{[]byte("createRegistryOfficer"), []byte(`{"ID":"R2","Name":"Registry Officer","CitizenID":"C8"}`)})
+	if res.Status == shim.OK || res.Message != `{"code":"ACCESS_DENIED","message":"Error: Certificate has no officeID attribute!"}` {
+		t.Error("RegistryOfficer enrolled without an office not refused", res.Message)
+	}
+
+	// Pending officers are not picked until an admin licenses them
+	res = stub.MockInvokeAs(officer, "3", [][]byte{[]byte("getLeastBusyRegistryOfficer"), []byte(`{"State":"KA","District":"BLR","OfficeID":"SRO1"}`)})
+	if res.Status == shim.OK {
+		t.Error("pending RegistryOfficer picked", string(res.Payload))
+	}
+}
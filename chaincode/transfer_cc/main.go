@@ -4,10 +4,17 @@
 
 package main
 
-import "github.com/hyperledger/fabric/core/chaincode/shim"
+import (
+	"example.org/transfer_cc/transfer"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
 
 func main() {
-	err := shim.Start(new(Chaincode))
+	cc, err := transfer.New()
+	if err != nil {
+		panic(err)
+	}
+	err = shim.Start(cc)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,435 @@
+package transfer
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"example.org/lib/cases"
+	"example.org/lib/contract"
+	"example.org/lib/envelope"
+	"example.org/lib/events"
+	"example.org/lib/identity"
+	"example.org/lib/registry"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Definition of the Appeal structure
+// Outcome is "pending" until the Reviewer decides "upheld" or "overturned", or "withdrawn" once
+// another request has transferred the Land.
+type Appeal struct {
+	ID                string          `json:"ID"`
+	TransferRequestID string          `json:"TransferRequestID"`
+	Appellant         string          `json:"Appellant"`
+	Grounds           string          `json:"Grounds"`
+	Documents         []string        `json:"Documents"`
+	Reviewer          string          `json:"Reviewer"`
+	Outcome           string          `json:"Outcome"`
+	StatusHistory     []StatusHistory `json:"StatusHistory"`
+	Type              string          `json:"Type"`
+}
+
+// Time a declined transferRequest can be appealed for, in seconds
+const appealWindow = 30 * 24 * 60 * 60
+
+// Payload of declineTransferRequest
+type DeclineArgs struct {
+	ID     string `json:"ID" validate:"required,key"`
+	Reason string `json:"Reason" validate:"required"`
+	Date   int    `json:"Date" validate:"required,min=1"`
+}
+
+// Payload of fileAppeal, Documents being the hashes of the documents supporting the Grounds
+type FileAppealArgs struct {
+	ID                string   `json:"ID" validate:"required,key"`
+	TransferRequestID string   `json:"TransferRequestID" validate:"required,key"`
+	Grounds           string   `json:"Grounds" validate:"required"`
+	Documents         []string `json:"Documents"`
+	Date              int      `json:"Date" validate:"required,min=1"`
+}
+
+// Payload of assignAppealReviewer
+type ReviewerArgs struct {
+	ID       string `json:"ID" validate:"required,key"`
+	Reviewer string `json:"Reviewer" validate:"required,key"`
+	Date     int    `json:"Date" validate:"required,min=1"`
+}
+
+// Payload of closeTransferRequest
+type CloseArgs struct {
+	ID   string `json:"ID" validate:"required,key"`
+	Date int    `json:"Date" validate:"required,min=1"`
+}
+
+// Payload of decideAppeal
+type DecideAppealArgs struct {
+	ID      string `json:"ID" validate:"required,key"`
+	Outcome string `json:"Outcome" validate:"required,oneof=upheld overturned"`
+	Date    int    `json:"Date" validate:"required,min=1"`
+}
+
+// Function to decline a transferRequest at the last stage of its Workflow (U of CRUD)
+func (cc *Chaincode) DeclineTransferRequest(ctx *contract.TransactionContext, args DeclineArgs) error {
+	transferRequestToUpdate, current, workflow, err := getOpenTransferRequest(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	}
+	if !authenticateStage(ctx.GetStub(), current, ctx.MSP, ctx.CA) || !authorizeAssignee(ctx.GetStub(), transferRequestToUpdate, current) {
+		return ctx.AccessDenied()
+	}
+
+	// Only the last stage of the Workflow can decline, as only it can approve
+	if current.Name != workflow.Stages[len(workflow.Stages)-1].Name {
+		return envelope.InvalidState("TransferRequest is not at its last stage!", "Stage", current.Name)
+	}
+
+	// Generate StatusHistory
+	status := StatusHistory{"Transfer Request Declined by " + current.Title + ": " + args.Reason, ctx.Creator, args.Date}
+	transferRequestToUpdate.StatusHistory = append(transferRequestToUpdate.StatusHistory, status)
+
+	// Update transferRequest.Declined => true, appealable until the window closes
+	// Cases stay active until an Appeal is decided or the request is closed, as it may yet be reopened
+	now, err := registry.TxTime(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	transferRequestToUpdate.Declined = true
+	transferRequestToUpdate.DeclineReason = args.Reason
+	transferRequestToUpdate.AppealDeadline = now + appealWindow
+
+	// Put updated State of the TransferRequest
+	err = putTransferRequest(ctx.GetStub(), transferRequestToUpdate)
+	if err != nil {
+		return err
+	}
+	return ctx.Emit(events.StageChanged{
+		TransferRequestID: args.ID,
+		PreviousStage:     current.Name,
+		Stage:             current.Name,
+		Status:            "declined",
+		Assignee:          transferRequestToUpdate.Assignees[current.Name],
+		Reason:            args.Reason,
+		Date:              args.Date,
+	})
+}
+
+// Function to create new appeal against a declined transferRequest (C of CRUD)
+func (cc *Chaincode) FileAppeal(ctx *contract.TransactionContext, args FileAppealArgs) error {
+	key := "appeal-" + args.ID
+
+	// Check if Appeal exists with Key => key
+	appealAsBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return envelope.Internal("Failed to check if Appeal exists!")
+	} else if appealAsBytes != nil {
+		return envelope.Conflict("Appeal Already Exists!", "ID", args.ID)
+	}
+
+	// Only a declined request without a pending Appeal can be appealed
+	transferRequestToUpdate, err := getTransferRequest(ctx.GetStub(), args.TransferRequestID)
+	if err != nil {
+		return err
+	}
+	if !transferRequestToUpdate.Declined || transferRequestToUpdate.Complete {
+		return envelope.InvalidState("TransferRequest is not declined!", "ID", args.TransferRequestID)
+	}
+	if transferRequestToUpdate.Requester != ctx.Creator {
+		return ctx.AccessDenied("Requester", transferRequestToUpdate.Requester)
+	}
+	now, err := registry.TxTime(ctx.GetStub())
+	if err != nil {
+		return err
+	} else if transferRequestToUpdate.AppealDeadline != 0 && now > transferRequestToUpdate.AppealDeadline {
+		return envelope.InvalidState("Appeal deadline has passed!", "AppealDeadline", strconv.Itoa(transferRequestToUpdate.AppealDeadline))
+	}
+	if transferRequestToUpdate.Appeal != "" {
+		pending, err := getAppeal(ctx.GetStub(), transferRequestToUpdate.Appeal)
+		if err != nil {
+			return err
+		} else if pending.Outcome == "pending" {
+			return envelope.Conflict("TransferRequest already has a pending Appeal!", "Appeal", pending.ID)
+		}
+	}
+
+	// Generate Appeal from params provided
+	status := StatusHistory{"Appeal Filed.", ctx.Creator, args.Date}
+	appeal := Appeal{args.ID, args.TransferRequestID, ctx.Creator, args.Grounds, args.Documents, "", "pending", []StatusHistory{status}, "APPEAL"}
+	err = putAppeal(ctx.GetStub(), appeal)
+	if err != nil {
+		return err
+	}
+
+	// Link the Appeal to the TransferRequest
+	status = StatusHistory{"Appeal " + args.ID + " filed against decline.", ctx.Creator, args.Date}
+	transferRequestToUpdate.StatusHistory = append(transferRequestToUpdate.StatusHistory, status)
+	transferRequestToUpdate.Appeal = args.ID
+	err = putTransferRequest(ctx.GetStub(), transferRequestToUpdate)
+	if err != nil {
+		return err
+	}
+	return ctx.Emit(events.AppealFiled{AppealID: args.ID, TransferRequestID: args.TransferRequestID, Appellant: ctx.Creator, Date: args.Date})
+}
+
+// Function to read an appeal (R of CRUD)
+func (cc *Chaincode) ReadAppeal(ctx *contract.TransactionContext, args contract.IDArgs) (Appeal, error) {
+	return getAppeal(ctx.GetStub(), args.ID)
+}
+
+// Function to assign a senior BLRO, other than the one who declined, to review an appeal (U of CRUD)
+func (cc *Chaincode) AssignAppealReviewer(ctx *contract.TransactionContext, args ReviewerArgs) error {
+	appealToUpdate, err := getAppeal(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	} else if appealToUpdate.Outcome != "pending" {
+		return envelope.InvalidState("Appeal has already been decided!", "Outcome", appealToUpdate.Outcome)
+	}
+
+	transferRequestToRead, err := getTransferRequest(ctx.GetStub(), appealToUpdate.TransferRequestID)
+	if err != nil {
+		return err
+	}
+	workflow, err := getWorkflow(ctx.GetStub(), transferRequestToRead.Workflow)
+	if err != nil {
+		return err
+	}
+	if transferRequestToRead.Assignees[workflow.Stages[len(workflow.Stages)-1].Name] == args.Reviewer {
+		return contract.InvalidArguments("Reviewer", "must not be the BLRO who declined the TransferRequest")
+	}
+
+	// Check the args.Reviewer is an active, senior BLRO over the land, in the caller's district
+	land, err := getLand(ctx.GetStub(), transferRequestToRead.LandID)
+	if err != nil {
+		return err
+	}
+	err = checkAssignable(ctx.GetStub(), "blro_cc", args.Reviewer, land)
+	if err != nil {
+		return err
+	}
+	reviewer, err := getProfessional(ctx.GetStub(), "blro_cc", args.Reviewer)
+	if err != nil {
+		return err
+	} else if reviewer.Rank != "senior" {
+		return envelope.InvalidArgument("Reviewer "+args.Reviewer+" is not a senior BLRO!", "Reviewer", args.Reviewer, "Rank", reviewer.Rank)
+	}
+	authorized, err := authorizeJurisdiction(ctx.GetStub(), "blro_cc", "readBLRO", args.Reviewer, "district", "District")
+	if err != nil {
+		return err
+	} else if !authorized {
+		return ctx.AccessDenied("District", args.Reviewer)
+	}
+
+	// Generate StatusHistory
+	status := StatusHistory{"Appeal assigned to Reviewer " + args.Reviewer + ".", ctx.Creator, args.Date}
+	appealToUpdate.StatusHistory = append(appealToUpdate.StatusHistory, status)
+
+	// Update appeal.Reviewer => args.Reviewer
+	appealToUpdate.Reviewer = args.Reviewer
+
+	// Put updated State of the Appeal
+	err = putAppeal(ctx.GetStub(), appealToUpdate)
+	if err != nil {
+		return err
+	}
+	return ctx.Emit(events.AppealReviewerAssigned{
+		AppealID:          args.ID,
+		TransferRequestID: appealToUpdate.TransferRequestID,
+		Reviewer:          args.Reviewer,
+		AssignedBy:        ctx.Creator,
+		Date:              args.Date,
+	})
+}
+
+// Function to decide an appeal; overturning reopens the transferRequest at its last stage, assigned to the Reviewer (U of CRUD)
+func (cc *Chaincode) DecideAppeal(ctx *contract.TransactionContext, args DecideAppealArgs) error {
+	appealToUpdate, err := getAppeal(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	} else if appealToUpdate.Outcome != "pending" {
+		return envelope.InvalidState("Appeal has already been decided!", "Outcome", appealToUpdate.Outcome)
+	} else if appealToUpdate.Reviewer == "" {
+		return envelope.InvalidState("Appeal has no Reviewer yet!", "ID", args.ID)
+	}
+
+	// Only the assigned Reviewer can decide the Appeal
+	if !identity.AuthorizeProfile(ctx.GetStub(), appealToUpdate.Reviewer) || !authorizeProfessional(ctx.GetStub(), "blro_cc", appealToUpdate.Reviewer) {
+		return ctx.AccessDenied("Reviewer", appealToUpdate.Reviewer)
+	}
+
+	transferRequestToUpdate, err := getTransferRequest(ctx.GetStub(), appealToUpdate.TransferRequestID)
+	if err != nil {
+		return err
+	}
+	workflow, err := getWorkflow(ctx.GetStub(), transferRequestToUpdate.Workflow)
+	if err != nil {
+		return err
+	}
+
+	// Generate StatusHistory of the Appeal
+	status := StatusHistory{"Appeal " + args.Outcome + ".", ctx.Creator, args.Date}
+	appealToUpdate.StatusHistory = append(appealToUpdate.StatusHistory, status)
+	appealToUpdate.Outcome = args.Outcome
+
+	err = putAppeal(ctx.GetStub(), appealToUpdate)
+	if err != nil {
+		return err
+	}
+	err = ctx.Emit(events.AppealDecided{AppealID: args.ID, TransferRequestID: appealToUpdate.TransferRequestID, Outcome: args.Outcome, Reviewer: appealToUpdate.Reviewer, Date: args.Date})
+	if err != nil {
+		return err
+	}
+
+	previousStage := transferRequestToUpdate.Stage
+	last := workflow.Stages[len(workflow.Stages)-1]
+	decliner := transferRequestToUpdate.Assignees[last.Name]
+	if args.Outcome == "overturned" {
+		// Reopen the TransferRequest at its last stage, taken over from the BLRO who declined by the Reviewer
+		status = StatusHistory{"Decline overturned on Appeal " + args.ID + ", request reopened for " + appealToUpdate.Reviewer + ".", ctx.Creator, args.Date}
+		transferRequestToUpdate.Declined = false
+		transferRequestToUpdate.Stage = last.Name
+		record := Reassignment{"BLRO", decliner, appealToUpdate.Reviewer, ctx.Creator, args.Date}
+		transferRequestToUpdate.Reassignments = append(transferRequestToUpdate.Reassignments, record)
+		assign(&transferRequestToUpdate, last, appealToUpdate.Reviewer)
+	} else {
+		// The decline is final, so the request is closed without transferring the Land
+		status = StatusHistory{"Decline upheld on Appeal " + args.ID + ", request closed.", ctx.Creator, args.Date}
+		transferRequestToUpdate.Complete = true
+	}
+	transferRequestToUpdate.StatusHistory = append(transferRequestToUpdate.StatusHistory, status)
+
+	err = putTransferRequest(ctx.GetStub(), transferRequestToUpdate)
+	if err != nil {
+		return err
+	}
+	changed := events.StageChanged{
+		TransferRequestID: transferRequestToUpdate.ID,
+		PreviousStage:     previousStage,
+		Stage:             transferRequestToUpdate.Stage,
+		Status:            "open",
+		Assignee:          transferRequestToUpdate.Assignees[transferRequestToUpdate.Stage],
+		Reason:            "Appeal " + args.ID + " " + args.Outcome,
+		Date:              args.Date,
+	}
+	if transferRequestToUpdate.Complete {
+		changed.Status = "closed"
+	}
+	err = ctx.Emit(changed)
+	if err != nil {
+		return err
+	}
+
+	// Set complete to the cases of a closed request, or hand the reopened one over to the Reviewer
+	if transferRequestToUpdate.Complete {
+		changes := &cases.Changes{}
+		err = completeCases(ctx, changes, transferRequestToUpdate, workflow, args.Date)
+		if err != nil {
+			return err
+		}
+		return changes.Apply(ctx.GetStub())
+	}
+	err = removeCase(ctx, last, decliner, transferRequestToUpdate.ID, args.Date)
+	if err != nil {
+		return err
+	}
+	return addCase(ctx, last, appealToUpdate.Reviewer, transferRequestToUpdate.ID, args.Date)
+}
+
+// Function to close a declined transferRequest without an Appeal, by its Requester waiving the appeal
+// or by the BLRO who declined it once the appeal deadline has passed (U of CRUD)
+func (cc *Chaincode) CloseTransferRequest(ctx *contract.TransactionContext, args CloseArgs) error {
+	transferRequestToUpdate, err := getTransferRequest(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	}
+	if !transferRequestToUpdate.Declined || transferRequestToUpdate.Complete {
+		return envelope.InvalidState("TransferRequest is not declined!", "ID", args.ID)
+	}
+	if transferRequestToUpdate.Appeal != "" {
+		pending, err := getAppeal(ctx.GetStub(), transferRequestToUpdate.Appeal)
+		if err != nil {
+			return err
+		} else if pending.Outcome == "pending" {
+			return envelope.Conflict("TransferRequest has a pending Appeal!", "Appeal", pending.ID)
+		}
+	}
+	workflow, err := getWorkflow(ctx.GetStub(), transferRequestToUpdate.Workflow)
+	if err != nil {
+		return err
+	}
+	last := workflow.Stages[len(workflow.Stages)-1]
+
+	// The Requester can waive the appeal at any time, the BLRO who declined only once it can no longer be filed
+	if transferRequestToUpdate.Requester != ctx.Creator {
+		if !authenticateStage(ctx.GetStub(), last, ctx.MSP, ctx.CA) || !authorizeAssignee(ctx.GetStub(), transferRequestToUpdate, last) {
+			return ctx.AccessDenied()
+		}
+		now, err := registry.TxTime(ctx.GetStub())
+		if err != nil {
+			return err
+		} else if now <= transferRequestToUpdate.AppealDeadline {
+			return envelope.InvalidState("Appeal deadline has not passed yet!", "AppealDeadline", strconv.Itoa(transferRequestToUpdate.AppealDeadline))
+		}
+	}
+
+	// Generate StatusHistory
+	status := StatusHistory{"Declined request closed without appeal.", ctx.Creator, args.Date}
+	transferRequestToUpdate.StatusHistory = append(transferRequestToUpdate.StatusHistory, status)
+
+	// Update transferRequest.Complete => true
+	transferRequestToUpdate.Complete = true
+
+	// Put updated State of the TransferRequest
+	err = putTransferRequest(ctx.GetStub(), transferRequestToUpdate)
+	if err != nil {
+		return err
+	}
+	err = ctx.Emit(events.StageChanged{
+		TransferRequestID: args.ID,
+		PreviousStage:     transferRequestToUpdate.Stage,
+		Stage:             transferRequestToUpdate.Stage,
+		Status:            "closed",
+		Assignee:          transferRequestToUpdate.Assignees[transferRequestToUpdate.Stage],
+		Reason:            transferRequestToUpdate.DeclineReason,
+		Date:              args.Date,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Set complete to the cases of the closed request
+	changes := &cases.Changes{}
+	err = completeCases(ctx, changes, transferRequestToUpdate, workflow, args.Date)
+	if err != nil {
+		return err
+	}
+	return changes.Apply(ctx.GetStub())
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Get Appeal with ID
+func getAppeal(stub shim.ChaincodeStubInterface, ID string) (Appeal, error) {
+	appealToRead := Appeal{}
+
+	// Get State of Appeal with Key => appeal-ID
+	appealAsBytes, err := stub.GetState("appeal-" + ID)
+	if err != nil {
+		return appealToRead, envelope.Internal("Failed to get state for " + ID)
+	} else if appealAsBytes == nil {
+		return appealToRead, envelope.NotFound("Appeal does not exist!", "ID", ID)
+	}
+
+	err = json.Unmarshal(appealAsBytes, &appealToRead) //unmarshal it aka JSON.parse()
+	return appealToRead, err
+}
+
+// Put State of Appeal with Key => appeal-ID
+func putAppeal(stub shim.ChaincodeStubInterface, a Appeal) error {
+	// Convert to Byte[]
+	appealJSONasBytes, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	return stub.PutState("appeal-"+a.ID, appealJSONasBytes)
+}
@@ -0,0 +1,1007 @@
+// Package transfer is the transfer_cc chaincode, which moves TransferRequests through their Workflow, from the request of a citizen to the approval of a BLRO.
+package transfer
+
+import (
+	"encoding/json"
+	"example.org/lib/cases"
+	"example.org/lib/contract"
+	"example.org/lib/envelope"
+	"example.org/lib/events"
+	"example.org/lib/identity"
+	"example.org/lib/registry"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"strconv"
+)
+
+// Chaincode is the definition of the chaincode structure.
+type Chaincode struct {
+	contract.Contract
+	contract.Mapping
+	contract.Ledger
+}
+
+// New routes the transactions of transfer_cc
+func New() (*contract.Chaincode, error) {
+	return contract.New("transfer_cc", &Chaincode{Ledger: contract.Ledger{CompositeKeys: compositeKeys(), Records: records}})
+}
+
+// Records transfer_cc stores, by the prefix of their keys or the object type of their composite keys
+var records = map[string]interface{}{
+	"transferRequest-": TransferRequest{},
+	"appeal-":          Appeal{},
+	"workflow-":        Workflow{},
+	commentIndex:       Comment{},
+}
+
+// Definition of status of TransferRequest
+type StatusHistory struct {
+	Status        string `json:"Status"`
+	StatusCreator string `json:"StatusCreator"`
+	Date          int    `json:"Date"`
+}
+
+// Definition of a reassignment of a professional on a TransferRequest
+type Reassignment struct {
+	Role         string `json:"Role"`
+	From         string `json:"From"`
+	To           string `json:"To"`
+	AuthorizedBy string `json:"AuthorizedBy"`
+	Date         int    `json:"Date"`
+}
+
+// Definition of the TransferRequest structure
+// Lawyer, RegistryOfficer and BLRO mirror the Assignees of stages tracked in their chaincodes.
+// OpenClarifications counts the clarifications in the Comment thread not yet resolved.
+// From is the Owner of the Land when the transfer was requested, who must still own it on approval.
+// AppealDeadline is the Unix time until which a declined request can be appealed.
+type TransferRequest struct {
+	ID                 string            `json:"ID"`
+	From               string            `json:"From"`
+	To                 string            `json:"To"`
+	LandID             string            `json:"LandID"`
+	Lawyer             string            `json:"Lawyer"`
+	RegistryOfficer    string            `json:"RegistryOfficer"`
+	BLRO               string            `json:"BLRO"`
+	Stage              string            `json:"Stage"`
+	StatusHistory      []StatusHistory   `json:"StatusHistory"`
+	Complete           bool              `json:"Complete"`
+	Reassignments      []Reassignment    `json:"Reassignments"`
+	Workflow           string            `json:"Workflow"`
+	Assignees          map[string]string `json:"Assignees"`
+	Artefacts          map[string]string `json:"Artefacts"`
+	Declined           bool              `json:"Declined"`
+	DeclineReason      string            `json:"DeclineReason"`
+	AppealDeadline     int               `json:"AppealDeadline"`
+	Appeal             string            `json:"Appeal"`
+	OpenClarifications int               `json:"OpenClarifications"`
+	Requester          string            `json:"Requester"`
+}
+
+// Payload of createTransferRequest
+// Assignee is the professional of the first stage, and WorkflowID optionally selects a Workflow other than the default
+type CreateTransferRequestArgs struct {
+	ID         string `json:"ID" validate:"required,key"`
+	To         string `json:"To" validate:"required,key"`
+	LandID     string `json:"LandID" validate:"required,key"`
+	Assignee   string `json:"Assignee" validate:"required,key"`
+	Date       int    `json:"Date" validate:"required,min=1"`
+	WorkflowID string `json:"WorkflowID,omitempty" validate:"key"`
+}
+
+// Payload of transactions acting on a transferRequest at Date
+type DateArgs struct {
+	ID   string `json:"ID" validate:"required,key"`
+	Date int    `json:"Date" validate:"required,min=1"`
+}
+
+// Payload of advanceTransferRequest
+type AssigneeArgs struct {
+	ID       string `json:"ID" validate:"required,key"`
+	Assignee string `json:"Assignee" validate:"required,key"`
+	Date     int    `json:"Date" validate:"required,min=1"`
+}
+
+// Payload of reassignLawyer
+type LawyerArgs struct {
+	ID     string `json:"ID" validate:"required,key"`
+	Lawyer string `json:"Lawyer" validate:"required,key"`
+	Date   int    `json:"Date" validate:"required,min=1"`
+}
+
+// Payload of transfer2RegistryOfficer and reassignRegistryOfficer
+type RegistryOfficerArgs struct {
+	ID              string `json:"ID" validate:"required,key"`
+	RegistryOfficer string `json:"RegistryOfficer" validate:"required,key"`
+	Date            int    `json:"Date" validate:"required,min=1"`
+}
+
+// Payload of transfer2BLRO and reassignBLRO
+type BLROArgs struct {
+	ID   string `json:"ID" validate:"required,key"`
+	BLRO string `json:"BLRO" validate:"required,key"`
+	Date int    `json:"Date" validate:"required,min=1"`
+}
+
+// Payload of addArtefact, Reference being the hash or location of the artefact
+type ArtefactArgs struct {
+	ID        string `json:"ID" validate:"required,key"`
+	Name      string `json:"Name" validate:"required"`
+	Reference string `json:"Reference" validate:"required"`
+	Date      int    `json:"Date" validate:"required,min=1"`
+}
+
+// Roles allowed to call each transaction, checked before it runs.
+// Transactions acted on by a Workflow stage authenticate the stage's actor themselves.
+var access = map[string][]string{
+	"createTransferRequest":   {"citizen"},
+	"reassignLawyer":          {"citizen"},
+	"reassignRegistryOfficer": {"registryofficer"},
+	"reassignBLRO":            {"blro"},
+	"fileAppeal":              {"citizen"},
+	"assignAppealReviewer":    {"blro"},
+	"decideAppeal":            {"blro"},
+	"closeTransferRequest":    {"citizen", "blro"},
+	"createWorkflow":          {"blro"},
+}
+
+// Init stores the role => identity mapping the chaincode authenticates against, and the default Workflow
+func (cc *Chaincode) Init(ctx *contract.TransactionContext) error {
+	err := identity.InitMapping(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+
+	// Store the default Workflow on the ledger if it is not there yet
+	workflowAsBytes, err := ctx.GetStub().GetState("workflow-" + defaultWorkflowID)
+	if err != nil {
+		return envelope.Internal("Failed to check if Workflow exists!")
+	} else if workflowAsBytes != nil {
+		return nil
+	}
+
+	workflowJSONasBytes, err := json.Marshal(defaultWorkflow)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState("workflow-"+defaultWorkflowID, workflowJSONasBytes)
+}
+
+// BeforeTransaction authenticates the caller against the roles allowed to call the transaction
+func (cc *Chaincode) BeforeTransaction(ctx *contract.TransactionContext) error {
+	return ctx.Authorize(access)
+}
+
+// Function to create new transferRequest (C of CRUD)
+func (cc *Chaincode) CreateTransferRequest(ctx *contract.TransactionContext, args CreateTransferRequestArgs) error {
+	key := "transferRequest-" + args.ID
+	selected := args.WorkflowID
+	if selected == "" {
+		selected = defaultWorkflowID
+	}
+	var History []StatusHistory
+	Complete := false
+
+	// Check if TransferRequest exists with Key => key
+	transferRequestAsBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return envelope.Internal("Failed to check if TransferRequest exists!")
+	} else if transferRequestAsBytes != nil {
+		return envelope.Conflict("TransferRequest Already Exists!", "ID", args.ID)
+	}
+
+	// Citizens can only request the transfer of land registered to their own profile
+	land, err := getLand(ctx.GetStub(), args.LandID)
+	if err != nil {
+		return err
+	}
+	if !identity.AuthorizeProfile(ctx.GetStub(), land.Owner) {
+		return ctx.AccessDenied("LandID", args.LandID, "Owner", land.Owner)
+	}
+
+	// Get the Workflow the TransferRequest will follow
+	workflow, err := getWorkflow(ctx.GetStub(), selected)
+	if err != nil {
+		return err
+	}
+	first := workflow.Stages[0]
+
+	// The first stage's Assignee must hold an active licence covering the land
+	err = checkAssignable(ctx.GetStub(), first.Chaincode, args.Assignee, land)
+	if err != nil {
+		return err
+	}
+
+	// Generate StatusHistory
+	status := StatusHistory{"Transfer Request Created.", ctx.Creator, args.Date}
+	History = append(History, status)
+
+	// Generate TransferRequest from params provided
+	transferRequest := TransferRequest{
+		ID:            args.ID,
+		From:          land.Owner,
+		To:            args.To,
+		LandID:        args.LandID,
+		Stage:         first.Name,
+		StatusHistory: History,
+		Complete:      Complete,
+		Workflow:      workflow.ID,
+		Requester:     ctx.Creator,
+	}
+	assign(&transferRequest, first, args.Assignee)
+
+	// Put State of newly generated TransferRequest with Key => key
+	err = putTransferRequest(ctx.GetStub(), transferRequest)
+	if err != nil {
+		return err
+	}
+	err = ctx.Emit(events.TransferRequestCreated{
+		TransferRequestID: args.ID,
+		LandID:            args.LandID,
+		From:              land.Owner,
+		To:                args.To,
+		Requester:         ctx.Creator,
+		Workflow:          workflow.ID,
+		Stage:             first.Name,
+		Assignee:          args.Assignee,
+		Date:              args.Date,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Add TransferRequestID to the Profile of the first stage's Assignee
+	return addCase(ctx, first, args.Assignee, args.ID, args.Date)
+}
+
+// Function to read an transferRequest (R of CRUD)
+func (cc *Chaincode) ReadTransferRequest(ctx *contract.TransactionContext, args contract.IDArgs) (TransferRequest, error) {
+	return getTransferRequest(ctx.GetStub(), args.ID)
+}
+
+// Function to forward a transferRequest to the next stage of its Workflow (U of CRUD)
+func (cc *Chaincode) AdvanceTransferRequest(ctx *contract.TransactionContext, args AssigneeArgs) error {
+	return forwardTransferRequest(ctx, args.ID, args.Assignee, args.Date, "")
+}
+
+// Function to forward a transferRequest to the RegistryOfficer (U of CRUD)
+func (cc *Chaincode) Transfer2RegistryOfficer(ctx *contract.TransactionContext, args RegistryOfficerArgs) error {
+	return forwardTransferRequest(ctx, args.ID, args.RegistryOfficer, args.Date, "registry")
+}
+
+// Function to forward to the RegistryOfficer with the fewest ActiveCases (U of CRUD)
+func (cc *Chaincode) AutoTransfer2RegistryOfficer(ctx *contract.TransactionContext, args DateArgs) error {
+	// Only the current stage's actor can pick the next Assignee
+	transferRequestToRead, current, _, err := getOpenTransferRequest(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	}
+	if !authenticateStage(ctx.GetStub(), current, ctx.MSP, ctx.CA) || !authorizeAssignee(ctx.GetStub(), transferRequestToRead, current) {
+		return ctx.AccessDenied()
+	}
+
+	// Ask registryoffice_cc for the least busy RegistryOfficer of the land's office, ties broken by ID
+	land, err := getLand(ctx.GetStub(), transferRequestToRead.LandID)
+	if err != nil {
+		return err
+	}
+	office := map[string]string{"State": land.State, "District": land.District, "OfficeID": land.OfficeID}
+	registryOfficerAsBytes, err := contract.Invoke(ctx.GetStub(), "registryoffice_cc", "getLeastBusyRegistryOfficer", office)
+	if err != nil {
+		return err
+	}
+
+	var registryOfficer struct {
+		ID string `json:"ID"`
+	}
+	err = json.Unmarshal(registryOfficerAsBytes, &registryOfficer)
+	if err != nil {
+		return err
+	}
+
+	// Forward exactly as if the RegistryOfficer had been picked by hand
+	return forwardTransferRequest(ctx, args.ID, registryOfficer.ID, args.Date, "registry")
+}
+
+// Function to forward a transferRequest to the BLRO (U of CRUD)
+func (cc *Chaincode) Transfer2BLRO(ctx *contract.TransactionContext, args BLROArgs) error {
+	return forwardTransferRequest(ctx, args.ID, args.BLRO, args.Date, "blro")
+}
+
+// Function to attach an artefact required by the current stage (U of CRUD)
+func (cc *Chaincode) AddArtefact(ctx *contract.TransactionContext, args ArtefactArgs) error {
+	transferRequestToUpdate, current, _, err := getOpenTransferRequest(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	}
+	if !authenticateStage(ctx.GetStub(), current, ctx.MSP, ctx.CA) || !authorizeAssignee(ctx.GetStub(), transferRequestToUpdate, current) {
+		return ctx.AccessDenied()
+	}
+
+	// Only artefacts the current stage asks for can be attached
+	required := false
+	for _, a := range current.Artefacts {
+		if a == args.Name {
+			required = true
+		}
+	}
+	if !required {
+		return contract.InvalidArguments("Name", "is not an artefact required by stage "+current.Name)
+	}
+
+	// Generate StatusHistory
+	status := StatusHistory{"Artefact " + args.Name + " submitted.", ctx.Creator, args.Date}
+	transferRequestToUpdate.StatusHistory = append(transferRequestToUpdate.StatusHistory, status)
+
+	// Update transferRequest.Artefacts[Name] => Reference
+	if transferRequestToUpdate.Artefacts == nil {
+		transferRequestToUpdate.Artefacts = map[string]string{}
+	}
+	transferRequestToUpdate.Artefacts[args.Name] = args.Reference
+
+	// Put updated State of the TransferRequest
+	err = putTransferRequest(ctx.GetStub(), transferRequestToUpdate)
+	if err != nil {
+		return err
+	}
+	return ctx.Emit(events.ArtefactAdded{TransferRequestID: args.ID, Stage: current.Name, Name: args.Name, Reference: args.Reference, Date: args.Date})
+}
+
+// Function to complete a case, add to StatusHistory, remove from Complete (U of CRUD)
+func (cc *Chaincode) ApproveTransferRequest(ctx *contract.TransactionContext, args DateArgs) error {
+	transferRequestToUpdate, current, workflow, err := getOpenTransferRequest(ctx.GetStub(), args.ID)
+	if err != nil {
+		return err
+	}
+	if !authenticateStage(ctx.GetStub(), current, ctx.MSP, ctx.CA) || !authorizeAssignee(ctx.GetStub(), transferRequestToUpdate, current) {
+		return ctx.AccessDenied()
+	}
+
+	// Only the last stage of the Workflow can approve
+	if current.Name != workflow.Stages[len(workflow.Stages)-1].Name {
+		return envelope.InvalidState("TransferRequest is not at its last stage!", "Stage", current.Name)
+	}
+	if missing := missingArtefact(transferRequestToUpdate, current); missing != "" {
+		return envelope.InvalidState("Artefact "+missing+" is required by stage "+current.Name+"!", "Stage", current.Name, "Artefact", missing)
+	}
+	if transferRequestToUpdate.OpenClarifications > 0 {
+		return envelope.InvalidState("TransferRequest has open clarifications!", "OpenClarifications", strconv.Itoa(transferRequestToUpdate.OpenClarifications))
+	}
+
+	// The Land must still be owned by the citizen who requested its transfer, not sold since by another request
+	land, err := getLand(ctx.GetStub(), transferRequestToUpdate.LandID)
+	if err != nil {
+		return err
+	}
+	if transferRequestToUpdate.From != "" && land.Owner != transferRequestToUpdate.From {
+		return envelope.InvalidState("Land has changed hands since the TransferRequest was made!", "LandID", transferRequestToUpdate.LandID, "Owner", land.Owner)
+	}
+
+	// Generate StatusHistory
+	status := StatusHistory{"Transfer Request Approved by " + current.Title + ".", ctx.Creator, args.Date}
+	transferRequestToUpdate.StatusHistory = append(transferRequestToUpdate.StatusHistory, status)
+
+	// Update transferRequest.Complete => true
+	transferRequestToUpdate.Complete = true
+
+	// Put updated State of the TransferRequest
+	err = putTransferRequest(ctx.GetStub(), transferRequestToUpdate)
+	if err != nil {
+		return err
+	}
+	err = ctx.Emit(events.RequestApproved{
+		TransferRequestID: args.ID,
+		LandID:            transferRequestToUpdate.LandID,
+		From:              transferRequestToUpdate.From,
+		To:                transferRequestToUpdate.To,
+		Approver:          transferRequestToUpdate.Assignees[current.Name],
+		Date:              args.Date,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Set complete to case with ID
+	changes := &cases.Changes{}
+	err = completeCases(ctx, changes, transferRequestToUpdate, workflow, args.Date)
+	if err != nil {
+		return err
+	}
+
+	// Transfer Land
+	transfer := map[string]interface{}{
+		"ID":                transferRequestToUpdate.LandID,
+		"CurrentOwner":      transferRequestToUpdate.To,
+		"TransferDate":      args.Date,
+		"TransferRequestID": args.ID,
+	}
+	_, err = contract.Invoke(ctx.GetStub(), "land_cc", "transferLand", transfer)
+	if err != nil {
+		return err
+	}
+
+	// land_cc is called within the transaction, so the transfer is announced here
+	err = ctx.Emit(events.LandTransferred{
+		LandID:            transferRequestToUpdate.LandID,
+		PreviousOwner:     land.Owner,
+		CurrentOwner:      transferRequestToUpdate.To,
+		TransferRequestID: args.ID,
+		Date:              args.Date,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The other requests to transfer the Land can no longer be approved, so they are declined and closed
+	err = supersedeTransferRequests(ctx, changes, transferRequestToUpdate, args.Date)
+	if err != nil {
+		return err
+	}
+
+	// Write the Profile of each professional once, with the cases of every request closed
+	return changes.Apply(ctx.GetStub())
+}
+
+// Function to reassign the Lawyer of an open transferRequest (U of CRUD)
+func (cc *Chaincode) ReassignLawyer(ctx *contract.TransactionContext, args LawyerArgs) error {
+	return reassignProfessional(ctx, args.ID, args.Lawyer, args.Date, "Lawyer")
+}
+
+// Function to reassign the RegistryOfficer of an open transferRequest (U of CRUD)
+func (cc *Chaincode) ReassignRegistryOfficer(ctx *contract.TransactionContext, args RegistryOfficerArgs) error {
+	return reassignProfessional(ctx, args.ID, args.RegistryOfficer, args.Date, "RegistryOfficer")
+}
+
+// Function to reassign the BLRO of an open transferRequest (U of CRUD)
+func (cc *Chaincode) ReassignBLRO(ctx *contract.TransactionContext, args BLROArgs) error {
+	return reassignProfessional(ctx, args.ID, args.BLRO, args.Date, "BLRO")
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// TransferRequest State
+// +++++++++++++++++++++
+
+// Get TransferRequest with ID
+// Requests created before Workflows existed are read as following the default Workflow,
+// and those created before Requester was recorded as requested by their first StatusCreator.
+func getTransferRequest(stub shim.ChaincodeStubInterface, ID string) (TransferRequest, error) {
+	transferRequestToRead := TransferRequest{}
+
+	// Get State of TransferRequest with Key => transferRequest-ID
+	transferRequestAsBytes, err := stub.GetState("transferRequest-" + ID)
+	if err != nil {
+		return transferRequestToRead, envelope.Internal("Failed to get state for " + ID)
+	} else if transferRequestAsBytes == nil {
+		return transferRequestToRead, envelope.NotFound("TransferRequest does not exist!", "ID", ID)
+	}
+
+	err = json.Unmarshal(transferRequestAsBytes, &transferRequestToRead) //unmarshal it aka JSON.parse()
+	if err != nil {
+		return transferRequestToRead, err
+	}
+
+	if transferRequestToRead.Requester == "" && len(transferRequestToRead.StatusHistory) > 0 {
+		transferRequestToRead.Requester = transferRequestToRead.StatusHistory[0].StatusCreator
+	}
+	if transferRequestToRead.Workflow == "" {
+		transferRequestToRead.Workflow = defaultWorkflowID
+		legacy := map[string]string{
+			"lawyer":   transferRequestToRead.Lawyer,
+			"registry": transferRequestToRead.RegistryOfficer,
+			"blro":     transferRequestToRead.BLRO,
+		}
+		transferRequestToRead.Assignees = map[string]string{}
+		for name, professional := range legacy {
+			if professional != "" {
+				transferRequestToRead.Assignees[name] = professional
+			}
+		}
+	}
+	return transferRequestToRead, nil
+}
+
+// Get an incomplete, undeclined TransferRequest with ID, along with its current stage and Workflow
+func getOpenTransferRequest(stub shim.ChaincodeStubInterface, ID string) (TransferRequest, WorkflowStage, Workflow, error) {
+	transferRequestToRead, err := getTransferRequest(stub, ID)
+	if err != nil {
+		return transferRequestToRead, WorkflowStage{}, Workflow{}, err
+	}
+	if transferRequestToRead.Complete {
+		return transferRequestToRead, WorkflowStage{}, Workflow{}, envelope.InvalidState("TransferRequest is already complete!", "ID", ID)
+	} else if transferRequestToRead.Declined {
+		return transferRequestToRead, WorkflowStage{}, Workflow{}, envelope.InvalidState("TransferRequest has been declined!", "ID", ID)
+	}
+
+	workflowToFollow, err := getWorkflow(stub, transferRequestToRead.Workflow)
+	if err != nil {
+		return transferRequestToRead, WorkflowStage{}, workflowToFollow, err
+	}
+
+	i := stageIndex(workflowToFollow, transferRequestToRead.Stage)
+	if i < 0 {
+		return transferRequestToRead, WorkflowStage{}, workflowToFollow, envelope.InvalidState("Stage "+transferRequestToRead.Stage+" is not part of Workflow "+workflowToFollow.ID+"!", "Stage", transferRequestToRead.Stage, "Workflow", workflowToFollow.ID)
+	}
+	return transferRequestToRead, workflowToFollow.Stages[i], workflowToFollow, nil
+}
+
+// Put State of TransferRequest with Key => transferRequest-ID, keeping its query indexes in step
+func putTransferRequest(stub shim.ChaincodeStubInterface, t TransferRequest) error {
+	var previous *TransferRequest
+	previousAsBytes, err := stub.GetState("transferRequest-" + t.ID)
+	if err != nil {
+		return err
+	} else if previousAsBytes != nil {
+		previousToIndex, err := getTransferRequest(stub, t.ID)
+		if err != nil {
+			return err
+		}
+		previous = &previousToIndex
+	}
+
+	err = updateIndexes(stub, previous, t)
+	if err != nil {
+		return err
+	}
+
+	// Convert to Byte[]
+	transferRequestJSONasBytes, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	return stub.PutState("transferRequest-"+t.ID, transferRequestJSONasBytes)
+}
+
+// Workflow Transitions
+// ++++++++++++++++++++
+
+// Record professional as the Assignee of stage s
+func assign(t *TransferRequest, s WorkflowStage, professional string) {
+	if t.Assignees == nil {
+		t.Assignees = map[string]string{}
+	}
+	t.Assignees[s.Name] = professional
+
+	switch s.Chaincode {
+	case "lawyer_cc":
+		t.Lawyer = professional
+	case "registryoffice_cc":
+		t.RegistryOfficer = professional
+	case "blro_cc":
+		t.BLRO = professional
+	}
+}
+
+// First artefact required by stage s that the TransferRequest is missing, empty if none
+func missingArtefact(t TransferRequest, s WorkflowStage) string {
+	for _, a := range s.Artefacts {
+		if t.Artefacts[a] == "" {
+			return a
+		}
+	}
+	return ""
+}
+
+// Move a transferRequest from its current stage to the next stage of its Workflow, assigning it to Assignee.
+// nextStage pins the expected next stage, empty for any
+func forwardTransferRequest(ctx *contract.TransactionContext, ID string, Assignee string, Date int, nextStage string) error {
+	transferRequestToUpdate, current, workflow, err := getOpenTransferRequest(ctx.GetStub(), ID)
+	if err != nil {
+		return err
+	}
+
+	// Only the stage's actor can move the request on
+	if !authenticateStage(ctx.GetStub(), current, ctx.MSP, ctx.CA) || !authorizeAssignee(ctx.GetStub(), transferRequestToUpdate, current) {
+		return ctx.AccessDenied()
+	}
+
+	i := stageIndex(workflow, current.Name)
+	if i == len(workflow.Stages)-1 {
+		return envelope.InvalidState("TransferRequest is at its last stage!", "Stage", current.Name)
+	}
+	next := workflow.Stages[i+1]
+	if nextStage != "" && next.Name != nextStage {
+		return envelope.InvalidState("Next stage of TransferRequest is "+next.Name+"!", "Stage", next.Name)
+	}
+	if missing := missingArtefact(transferRequestToUpdate, current); missing != "" {
+		return envelope.InvalidState("Artefact "+missing+" is required by stage "+current.Name+"!", "Stage", current.Name, "Artefact", missing)
+	}
+	if transferRequestToUpdate.OpenClarifications > 0 {
+		return envelope.InvalidState("TransferRequest has open clarifications!", "OpenClarifications", strconv.Itoa(transferRequestToUpdate.OpenClarifications))
+	}
+	land, err := getLand(ctx.GetStub(), transferRequestToUpdate.LandID)
+	if err != nil {
+		return err
+	}
+	err = checkAssignable(ctx.GetStub(), next.Chaincode, Assignee, land)
+	if err != nil {
+		return err
+	}
+
+	// Generate StatusHistory
+	status := StatusHistory{"Request forwarded to " + next.Title + ".", ctx.Creator, Date}
+	transferRequestToUpdate.StatusHistory = append(transferRequestToUpdate.StatusHistory, status)
+
+	// Update Assignee and Stage
+	assign(&transferRequestToUpdate, next, Assignee)
+	transferRequestToUpdate.Stage = next.Name
+
+	// Put updated State of the TransferRequest
+	err = putTransferRequest(ctx.GetStub(), transferRequestToUpdate)
+	if err != nil {
+		return err
+	}
+	err = ctx.Emit(events.StageChanged{TransferRequestID: ID, PreviousStage: current.Name, Stage: next.Name, Status: "open", Assignee: Assignee, Date: Date})
+	if err != nil {
+		return err
+	}
+
+	// Add TransferRequestID to the Profile of the next stage's Assignee
+	return addCase(ctx, next, Assignee, ID, Date)
+}
+
+// Set complete to the case of every Assignee at Date, from the last stage back to the first, in changes
+func completeCases(ctx *contract.TransactionContext, changes *cases.Changes, t TransferRequest, w Workflow, Date int) error {
+	for i := len(w.Stages) - 1; i >= 0; i-- {
+		s := w.Stages[i]
+		professional := t.Assignees[s.Name]
+		if s.Chaincode == "" || professional == "" {
+			continue
+		}
+
+		changes.Complete(s.Chaincode, professional, t.ID)
+		err := ctx.Emit(events.CaseCompleted{TransferRequestID: t.ID, Chaincode: s.Chaincode, Professional: professional, Stage: s.Name, Date: Date})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decline and close the open requests to transfer the Land the approved TransferRequest transferred, at Date,
+// withdrawing their pending Appeals and completing their cases in changes
+func supersedeTransferRequests(ctx *contract.TransactionContext, changes *cases.Changes, approved TransferRequest, Date int) error {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(transferRequestIndexes["land"], []string{approved.LandID, "false"})
+	if err != nil {
+		return err
+	}
+	var IDs []string
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			resultsIterator.Close()
+			return err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			resultsIterator.Close()
+			return err
+		}
+		if ID := attributes[len(attributes)-1]; ID != approved.ID {
+			IDs = append(IDs, ID)
+		}
+	}
+	resultsIterator.Close()
+
+	for _, ID := range IDs {
+		transferRequestToUpdate, err := getTransferRequest(ctx.GetStub(), ID)
+		if err != nil {
+			return err
+		}
+		workflow, err := getWorkflow(ctx.GetStub(), transferRequestToUpdate.Workflow)
+		if err != nil {
+			return err
+		}
+
+		// A pending Appeal has nothing left to decide
+		if transferRequestToUpdate.Appeal != "" {
+			appealToUpdate, err := getAppeal(ctx.GetStub(), transferRequestToUpdate.Appeal)
+			if err != nil {
+				return err
+			}
+			if appealToUpdate.Outcome == "pending" {
+				status := StatusHistory{"Appeal withdrawn, Land transferred by TransferRequest " + approved.ID + ".", ctx.Creator, Date}
+				appealToUpdate.StatusHistory = append(appealToUpdate.StatusHistory, status)
+				appealToUpdate.Outcome = "withdrawn"
+				err = putAppeal(ctx.GetStub(), appealToUpdate)
+				if err != nil {
+					return err
+				}
+				err = ctx.Emit(events.AppealDecided{AppealID: appealToUpdate.ID, TransferRequestID: transferRequestToUpdate.ID, Outcome: "withdrawn", Reviewer: appealToUpdate.Reviewer, Date: Date})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Generate StatusHistory
+		reason := "Land transferred by TransferRequest " + approved.ID
+		status := StatusHistory{"Transfer Request Declined: " + reason + ".", ctx.Creator, Date}
+		transferRequestToUpdate.StatusHistory = append(transferRequestToUpdate.StatusHistory, status)
+
+		// Update transferRequest.Declined and Complete => true
+		transferRequestToUpdate.Declined = true
+		transferRequestToUpdate.DeclineReason = reason
+		transferRequestToUpdate.Complete = true
+
+		// Put updated State of the TransferRequest
+		err = putTransferRequest(ctx.GetStub(), transferRequestToUpdate)
+		if err != nil {
+			return err
+		}
+		err = ctx.Emit(events.StageChanged{
+			TransferRequestID: ID,
+			PreviousStage:     transferRequestToUpdate.Stage,
+			Stage:             transferRequestToUpdate.Stage,
+			Status:            "closed",
+			Assignee:          transferRequestToUpdate.Assignees[transferRequestToUpdate.Stage],
+			Reason:            reason,
+			Date:              Date,
+		})
+		if err != nil {
+			return err
+		}
+		err = completeCases(ctx, changes, transferRequestToUpdate, workflow, Date)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add the TransferRequest with ID to the cases of professional, the Assignee of stage s, at Date
+func addCase(ctx *contract.TransactionContext, s WorkflowStage, professional string, ID string, Date int) error {
+	if s.Chaincode == "" {
+		return nil
+	}
+
+	err := cases.Add(ctx.GetStub(), s.Chaincode, professional, ID)
+	if err != nil {
+		return err
+	}
+	return ctx.Emit(events.CaseAssigned{TransferRequestID: ID, Chaincode: s.Chaincode, Professional: professional, Stage: s.Name, Date: Date})
+}
+
+// Remove the TransferRequest with ID from the cases of professional, no longer the Assignee of stage s, at Date
+func removeCase(ctx *contract.TransactionContext, s WorkflowStage, professional string, ID string, Date int) error {
+	err := cases.Remove(ctx.GetStub(), s.Chaincode, professional, ID)
+	if err != nil {
+		return err
+	}
+	return ctx.Emit(events.CaseRemoved{TransferRequestID: ID, Chaincode: s.Chaincode, Professional: professional, Stage: s.Name, Date: Date})
+}
+
+// Professionals
+// +++++++++++++
+
+// Definition of the fields of a professional Profile transfer_cc relies on, Rank being a BLRO's
+type professional struct {
+	registry.Professional
+	State    string `json:"State"`
+	District string `json:"District"`
+	OfficeID string `json:"OfficeID"`
+	Rank     string `json:"Rank,omitempty"`
+}
+
+// Definition of the fields of a Land transfer_cc relies on
+type parcel struct {
+	Owner    string `json:"Owner"`
+	State    string `json:"State"`
+	District string `json:"District"`
+	OfficeID string `json:"OfficeID"`
+}
+
+// Levels of the state => district => office hierarchy the professionals of each chaincode are bound to
+var jurisdictionLevels = map[string]int{
+	"registryoffice_cc": 3,
+	"blro_cc":           2,
+}
+
+// Function reading a Profile, by the chaincode holding it
+var profileReaders = map[string]string{
+	"lawyer_cc":         "readLawyer",
+	"registryoffice_cc": "readRegistryOfficer",
+	"blro_cc":           "readBLRO",
+}
+
+// Get the Profile with ID from chaincode
+func getProfessional(stub shim.ChaincodeStubInterface, chaincode string, ID string) (professional, error) {
+	profile := professional{}
+
+	profileAsBytes, err := contract.Invoke(stub, chaincode, profileReaders[chaincode], contract.IDArgs{ID: ID})
+	if err != nil {
+		return profile, err
+	}
+
+	err = json.Unmarshal(profileAsBytes, &profile) //unmarshal it aka JSON.parse()
+	return profile, err
+}
+
+// Check the licence of the professional with ID is active at the time of the transaction
+func checkActive(stub shim.ChaincodeStubInterface, ID string, p professional) error {
+	now, err := registry.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	if p.Status != "active" {
+		return envelope.InvalidState("Professional "+ID+" is not active!", "Status", p.Status)
+	} else if !p.Active(now) {
+		return envelope.InvalidState("Professional "+ID+" is not active!", "Status", "expired")
+	}
+	return nil
+}
+
+// Check the professional with ID can be assigned cases tracked in chaincode concerning Land l
+func checkAssignable(stub shim.ChaincodeStubInterface, chaincode string, ID string, l parcel) error {
+	if _, ok := profileReaders[chaincode]; !ok {
+		return nil
+	}
+
+	profile, err := getProfessional(stub, chaincode, ID)
+	if err != nil {
+		return err
+	}
+	if !covers(profile, chaincode, l) {
+		return envelope.InvalidArgument("Professional "+ID+" has no jurisdiction over the Land!", "State", l.State, "District", l.District, "OfficeID", l.OfficeID)
+	}
+	return checkActive(stub, ID, profile)
+}
+
+// Check the jurisdiction of a professional of chaincode covers Land l, down to the levels they are bound to
+func covers(p professional, chaincode string, l parcel) bool {
+	professionalLevels := []string{p.State, p.District, p.OfficeID}
+	landLevels := []string{l.State, l.District, l.OfficeID}
+	for i := 0; i < jurisdictionLevels[chaincode]; i++ {
+		if professionalLevels[i] == "" || professionalLevels[i] != landLevels[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the Land with ID from land_cc
+func getLand(stub shim.ChaincodeStubInterface, ID string) (parcel, error) {
+	l := parcel{}
+
+	landAsBytes, err := contract.Invoke(stub, "land_cc", "readLand", contract.IDArgs{ID: ID})
+	if err != nil {
+		return l, err
+	}
+
+	err = json.Unmarshal(landAsBytes, &l) //unmarshal it aka JSON.parse()
+	return l, err
+}
+
+// Reassignment
+// ++++++++++++
+
+// Chaincode holding the profiles of each reassignable role
+var roleChaincode = map[string]string{
+	"Lawyer":          "lawyer_cc",
+	"RegistryOfficer": "registryoffice_cc",
+	"BLRO":            "blro_cc",
+}
+
+// Profile read function, certificate attribute and Profile field bounding who a role may be reassigned to
+var roleJurisdiction = map[string][]string{
+	"RegistryOfficer": {"readRegistryOfficer", "officeID", "OfficeID"},
+	"BLRO":            {"readBLRO", "district", "District"},
+}
+
+// Move an open transferRequest from the current professional of a role to NewProfessional
+func reassignProfessional(ctx *contract.TransactionContext, ID string, NewProfessional string, Date int, role string) error {
+	// Only open requests can be reassigned
+	transferRequestToUpdate, _, workflow, err := getOpenTransferRequest(ctx.GetStub(), ID)
+	if err != nil {
+		return err
+	}
+
+	// Pick the stage whose professional holds the role
+	i := -1
+	for j, s := range workflow.Stages {
+		if s.Chaincode == roleChaincode[role] {
+			i = j
+		}
+	}
+	if i < 0 {
+		return envelope.InvalidState("Workflow "+workflow.ID+" has no "+role+" stage!", "Workflow", workflow.ID)
+	}
+	s := workflow.Stages[i]
+
+	// Citizens only reassign the Lawyer of their own requests
+	if role == "Lawyer" && transferRequestToUpdate.Requester != ctx.Creator {
+		return ctx.AccessDenied("Requester", transferRequestToUpdate.Requester)
+	}
+
+	OldProfessional := transferRequestToUpdate.Assignees[s.Name]
+	if OldProfessional == "" {
+		return envelope.InvalidState("No " + role + " assigned to TransferRequest yet!")
+	} else if OldProfessional == NewProfessional {
+		return envelope.Conflict(role+" is already assigned to TransferRequest!", role, NewProfessional)
+	}
+	land, err := getLand(ctx.GetStub(), transferRequestToUpdate.LandID)
+	if err != nil {
+		return err
+	}
+	err = checkAssignable(ctx.GetStub(), s.Chaincode, NewProfessional, land)
+	if err != nil {
+		return err
+	}
+
+	// Registry offices and BLROs only reassign to professionals within their own jurisdiction
+	if j, ok := roleJurisdiction[role]; ok {
+		authorized, err := authorizeJurisdiction(ctx.GetStub(), s.Chaincode, j[0], NewProfessional, j[1], j[2])
+		if err != nil {
+			return err
+		} else if !authorized {
+			return envelope.AccessDenied()
+		}
+	}
+
+	// Generate StatusHistory and Reassignment record
+	status := StatusHistory{role + " reassigned from " + OldProfessional + " to " + NewProfessional + ".", ctx.Creator, Date}
+	transferRequestToUpdate.StatusHistory = append(transferRequestToUpdate.StatusHistory, status)
+	record := Reassignment{role, OldProfessional, NewProfessional, ctx.Creator, Date}
+	transferRequestToUpdate.Reassignments = append(transferRequestToUpdate.Reassignments, record)
+
+	// Update the role => NewProfessional
+	assign(&transferRequestToUpdate, s, NewProfessional)
+
+	// Put updated State of the TransferRequest
+	err = putTransferRequest(ctx.GetStub(), transferRequestToUpdate)
+	if err != nil {
+		return err
+	}
+
+	// Remove TransferRequestID from the old Profile
+	err = removeCase(ctx, s, OldProfessional, ID, Date)
+	if err != nil {
+		return err
+	}
+
+	// Add TransferRequestID to the new Profile
+	return addCase(ctx, s, NewProfessional, ID, Date)
+}
+
+// Authentication
+// ++++++++++++++
+
+// Authorize => the certificate the Profile with ID in chaincode is bound to, while its licence is active.
+// Profiles created before certificates were bound are refused until an admin binds them with rekeyX.
+func authorizeProfessional(stub shim.ChaincodeStubInterface, chaincode string, ID string) bool {
+	if _, ok := profileReaders[chaincode]; !ok {
+		return true
+	}
+
+	profile, err := getProfessional(stub, chaincode, ID)
+	if err != nil || checkActive(stub, ID, profile) != nil || profile.KeyHash == "" {
+		return false
+	}
+
+	return identity.AuthorizeKeyHash(stub, profile.KeyHash)
+}
+
+// Authorize => a professional whose Profile, read with fcn from chaincode, has field equal to
+// the caller's certificate attribute, e.g. a RegistryOfficer of the caller's officeID
+func authorizeJurisdiction(stub shim.ChaincodeStubInterface, chaincode string, fcn string, ID string, attribute string, field string) (bool, error) {
+	value, found, err := cid.GetAttributeValue(stub, attribute)
+	if err != nil || !found {
+		return false, nil
+	}
+
+	profileAsBytes, err := contract.Invoke(stub, chaincode, fcn, contract.IDArgs{ID: ID})
+	if err != nil {
+		return false, err
+	}
+
+	var profile map[string]interface{}
+	err = json.Unmarshal(profileAsBytes, &profile)
+	if err != nil {
+		return false, err
+	}
+	return profile[field] == value, nil
+}
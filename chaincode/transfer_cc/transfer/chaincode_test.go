@@ -0,0 +1,709 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package transfer
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"example.org/lib/cases"
+	"example.org/lib/contract"
+	"example.org/lib/envelope"
+	"example.org/lib/identity"
+	"example.org/lib/identitytest"
+	"example.org/lib/registry"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/metadata"
+)
+
+func TestInit(t *testing.T) {
+	stub := identitytest.Chaincode(t, "transfer_cc", New)
+	res := stub.MockInit("1", [][]byte{[]byte("initFunc")})
+	if res.Status != shim.OK {
+		t.Error("Init failed", res.Status, res.Message)
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	stub := identitytest.Chaincode(t, "transfer_cc", New)
+	res := stub.MockInit("1", [][]byte{[]byte("initFunc")})
+	if res.Status != shim.OK {
+		t.Error("Init failed", res.Status, res.Message)
+	}
+	res = stub.MockInvoke("2", [][]byte{[]byte("invokeFunc")})
+	expected := `{"code":"INVALID_ARGUMENT","message":"Received unknown function invocation!","details":{"Function":"invokeFunc"}}`
+	if res.Status == shim.OK || res.Message != expected {
+		t.Error("unknown function expected", expected, "got", res.Status, res.Message)
+	}
+}
+
+func TestReadDefaultWorkflow(t *testing.T) {
+	stub := identitytest.Chaincode(t, "transfer_cc", New)
+	res := stub.MockInit("1", [][]byte{[]byte("initFunc")})
+	if res.Status != shim.OK {
+		t.Fatal("Init failed", res.Status, res.Message)
+	}
+
+	res = stub.MockInvoke("2", [][]byte{[]byte("readWorkflow"), []byte(`{"ID":"default"}`)})
+	if res.Status != shim.OK {
+		t.Fatal("readWorkflow failed", res.Message)
+	}
+	read := Workflow{}
+	if err := json.Unmarshal(res.Payload, &read); err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Stages) != 3 || read.Stages[0].Name != "lawyer" || read.Stages[2].Name != "blro" {
+		t.Error("unexpected default workflow", read.Stages)
+	}
+
+	res = stub.MockInvoke("3", [][]byte{[]byte("readWorkflow"), []byte(`{"ID":"gift"}`)})
+	if res.Status == shim.OK {
+		t.Error("readWorkflow found a workflow that was never created")
+	}
+}
+
+func TestValidateWorkflow(t *testing.T) {
+	blro := WorkflowStage{"blro", "BLRO", "BLROMSP", "ca.blro.lran.com", "blro", "blro_cc", nil}
+	registry := WorkflowStage{"registry", "Registry Officer", "RegistryOfficeMSP", "ca.registryoffice.lran.com", "registryofficer", "registryoffice_cc", []string{"deed"}}
+	revenue := WorkflowStage{"revenue", "Revenue Officer", "RevenueMSP", "ca.revenue.lran.com", "revenueofficer", "", []string{"mutation"}}
+
+	valid := []Workflow{
+		{ID: "gift", Stages: []WorkflowStage{registry, blro}},
+		{ID: "agricultural", Stages: []WorkflowStage{registry, revenue, blro}},
+	}
+	for _, w := range valid {
+		if err := validateWorkflow(&w); err != nil {
+			t.Error(w.ID, "rejected:", err)
+		}
+	}
+
+	invalid := []Workflow{
+		{ID: "empty"},
+		{ID: "duplicate", Stages: []WorkflowStage{blro, blro}},
+		{ID: "not-blro-last", Stages: []WorkflowStage{blro, registry}},
+		{ID: "no-role", Stages: []WorkflowStage{{"x", "X", "LawyerMSP", "ca.lawyer.lran.com", "", "", nil}, blro}},
+		{ID: "unknown-chaincode", Stages: []WorkflowStage{{"x", "X", "LawyerMSP", "ca.lawyer.lran.com", "lawyer", "x_cc", nil}, blro}},
+	}
+	for _, w := range invalid {
+		if err := validateWorkflow(&w); err == nil {
+			t.Error(w.ID, "accepted")
+		}
+	}
+}
+
+func TestCommentsRequireParty(t *testing.T) {
+	stub := identitytest.Chaincode(t, "transfer_cc", New)
+	stub.MockTransactionStart("1")
+	putTransferRequest(stub, TransferRequest{ID: "TR1", Stage: "lawyer", Workflow: defaultWorkflowID, Assignees: map[string]string{"lawyer": "L1"}})
+	stub.MockTransactionEnd("1")
+
+	res := stub.MockInvoke("2", [][]byte{[]byte("requestClarification"), []byte(`{"TransferRequestID":"TR1","ID":"C1","Message":"Which survey number?","Date":1580000000}`)})
+	if res.Status == shim.OK {
+		t.Error("requestClarification succeeded without a party to the request")
+	}
+
+	res = stub.MockInvoke("3", [][]byte{[]byte("listComments"), []byte(`{"TransferRequestID":"TR1","PageSize":10}`)})
+	if res.Status == shim.OK {
+		t.Error("listComments succeeded without a party to the request")
+	}
+}
+
+func TestClarificationResolvedAfterReassignment(t *testing.T) {
+	f := newTransferFixture(t)
+	f.put(t, TransferRequest{ID: "TR1", Stage: "registry"})
+	citizen := f.enroll(t, "CitizenMSP", "C1", map[string]string{"role": "citizen", "profileID": "C1"})
+	lawyer := f.enroll(t, "LawyerMSP", "L1", map[string]string{"role": "lawyer", "profileID": "L1"})
+	other := f.enroll(t, "LawyerMSP", "L2", map[string]string{"role": "lawyer", "profileID": "L2"})
+	officer := f.enroll(t, "RegistryOfficeMSP", "R1", map[string]string{"role": "registryofficer", "profileID": "R1", "officeID": "SRO1"})
+
+	f.invoke(t, lawyer, "requestClarification", `{"TransferRequestID":"TR1","ID":"Q1","Message":"Which survey number?","Date":1580000000}`)
+	f.invoke(t, lawyer, "requestClarification", `{"TransferRequestID":"TR1","ID":"Q2","Message":"Which boundary?","Date":1580000000}`)
+	f.invoke(t, citizen, "reassignLawyer", `{"ID":"TR1","Lawyer":"L2","Date":1580000001}`)
+
+	// Neither a party holding another stage nor a professional no longer party resolves it
+	resolve := [][]byte{[]byte("resolveClarification"), []byte(`{"TransferRequestID":"TR1","ID":"Q1","Date":1580000002}`)}
+	for name, id := range map[string]*identitytest.Identity{"another stage": officer, "no one": nil} {
+		if res := f.stub.MockInvokeAs(id, "1", resolve); envelope.CodeOf(errors.New(res.Message)) != envelope.CodeAccessDenied {
+			t.Error("resolveClarification by", name, "not refused", res.Message)
+		}
+	}
+
+	// The Lawyer now holding the stage and the requester can
+	f.invoke(t, other, "resolveClarification", `{"TransferRequestID":"TR1","ID":"Q1","Date":1580000002}`)
+	f.invoke(t, citizen, "resolveClarification", `{"TransferRequestID":"TR1","ID":"Q2","Date":1580000002}`)
+	if read := f.read(t, "TR1"); read.OpenClarifications != 0 {
+		t.Error("clarifications still open", read.OpenClarifications)
+	}
+}
+
+func TestListCommentsPages(t *testing.T) {
+	f := newTransferFixture(t)
+	f.put(t, TransferRequest{ID: "TR1", Stage: "lawyer"})
+	citizen := f.enroll(t, "CitizenMSP", "C1", map[string]string{"role": "citizen", "profileID": "C1"})
+	other := f.enroll(t, "LawyerMSP", "L2", map[string]string{"role": "lawyer", "profileID": "L2"})
+	for _, ID := range []string{"M1", "M2", "M3"} {
+		f.invoke(t, citizen, "postComment", `{"TransferRequestID":"TR1","ID":"`+ID+`","Message":"Hello","Date":1580000000}`)
+	}
+
+	res := f.stub.MockInvokeAs(other, "1", [][]byte{[]byte("listComments"), []byte(`{"TransferRequestID":"TR1","PageSize":10}`)})
+	if envelope.CodeOf(errors.New(res.Message)) != envelope.CodeAccessDenied {
+		t.Error("listComments by a Lawyer not assigned not refused", res.Message)
+	}
+
+	// Each page continues from the Bookmark of the one before
+	var IDs []string
+	bookmark := ""
+	for page := 0; page == 0 || bookmark != ""; page++ {
+		payload, _ := json.Marshal(ThreadArgs{TransferRequestID: "TR1", PageSize: 2, Bookmark: bookmark})
+		res := f.stub.MockInvokeAs(citizen, "1", [][]byte{[]byte("listComments"), payload})
+		if res.Status != shim.OK || page > 1 {
+			t.Fatal("listComments failed", res.Message)
+		}
+		var result struct {
+			Records  []Comment
+			Count    int
+			Bookmark string
+		}
+		if err := json.Unmarshal(res.Payload, &result); err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range result.Records {
+			IDs = append(IDs, c.ID)
+		}
+		bookmark = result.Bookmark
+	}
+	if strings.Join(IDs, ",") != "M1,M2,M3" {
+		t.Error("unexpected comments", IDs)
+	}
+}
+
+func TestCommentNotOverwritten(t *testing.T) {
+	f := newTransferFixture(t)
+	f.put(t, TransferRequest{ID: "TR1", Stage: "lawyer"})
+	citizen := f.enroll(t, "CitizenMSP", "C1", map[string]string{"role": "citizen", "profileID": "C1"})
+
+	// A Comment stored unreadable is neither taken for missing nor replaced
+	f.stub.MockTransactionStart("put")
+	key, _ := f.stub.CreateCompositeKey(commentIndex, []string{"TR1", "M1"})
+	f.stub.PutState(key, []byte("{"))
+	f.stub.MockTransactionEnd("put")
+	comment := []byte(`{"TransferRequestID":"TR1","ID":"M1","Message":"Hello","Date":1580000000}`)
+	res := f.stub.MockInvokeAs(citizen, "1", [][]byte{[]byte("postComment"), comment})
+	if res.Status == shim.OK || string(f.stub.State[key]) != "{" {
+		t.Error("unreadable Comment overwritten", res.Message)
+	}
+
+	f.invoke(t, citizen, "postComment", `{"TransferRequestID":"TR1","ID":"M2","Message":"Hello","Date":1580000000}`)
+	res = f.stub.MockInvokeAs(citizen, "2", [][]byte{[]byte("postComment"), []byte(`{"TransferRequestID":"TR1","ID":"M2","Message":"Again","Date":1580000001}`)})
+	if envelope.CodeOf(errors.New(res.Message)) != envelope.CodeConflict {
+		t.Error("existing Comment not reported", res.Message)
+	}
+}
+
+func TestQueryTransferRequestsPayload(t *testing.T) {
+	stub := identitytest.Chaincode(t, "transfer_cc", New)
+	rejected := map[string]string{
+		`{"By":"lawyer","Value":"L1","Complete":"all","PageSize":0}`:     `{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"PageSize":"is required"}}`,
+		`{"By":"lawyer","Value":"L1","Complete":"all","PageSize":-1}`:    `{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"PageSize":"must be at least 1"}}`,
+		`{"By":"lawyer","Value":"L1","Complete":"all","PageSize":101}`:   `{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"PageSize":"must be at most 100"}}`,
+		`{"By":"owner","Value":"L1","Complete":"maybe","PageSize":10}`:   `{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"By":"must be one of stage, lawyer, registryOfficer, blro, requester, to, land","Complete":"must be one of true, false, all"}}`,
+		`{"By":"lawyer","Value":"L1","Complete":"all","PageSize":"ten"}`: `{"code":"INVALID_ARGUMENT","message":"Invalid Arguments!","details":{"PageSize":"must be an integer"}}`,
+	}
+	for payload, expected := range rejected {
+		res := stub.MockInvoke("1", [][]byte{[]byte("queryTransferRequests"), []byte(payload)})
+		if res.Status == shim.OK || res.Message != expected {
+			t.Error(payload, "expected", expected, "got", res.Message)
+		}
+	}
+}
+
+func TestQueryTransferRequestsListsPartiesOnly(t *testing.T) {
+	f := newTransferFixture(t)
+	f.put(t, TransferRequest{ID: "TR1", Stage: "registry"})
+	ids := map[string]*identitytest.Identity{
+		"C1": f.enroll(t, "CitizenMSP", "C1", map[string]string{"role": "citizen", "profileID": "C1"}),
+		"C2": f.enroll(t, "CitizenMSP", "C2", map[string]string{"role": "citizen", "profileID": "C2"}),
+		"C3": f.enroll(t, "CitizenMSP", "C3", map[string]string{"role": "citizen", "profileID": "C3"}),
+		"L1": f.enroll(t, "LawyerMSP", "L1", map[string]string{"role": "lawyer", "profileID": "L1"}),
+		"L2": f.enroll(t, "LawyerMSP", "L2", map[string]string{"role": "lawyer", "profileID": "L2"}),
+		"R1": f.enroll(t, "RegistryOfficeMSP", "R1", map[string]string{"role": "registryofficer", "profileID": "R1", "officeID": "SRO1"}),
+		"B1": f.enroll(t, "BLROMSP", "B1", map[string]string{"role": "blro", "profileID": "B1", "district": "BLR"}),
+	}
+
+	// The requester, the buyer and the Assignees so far list the request, no one else does
+	expected := map[string]int{"C1": 1, "C2": 1, "C3": 0, "L1": 1, "L2": 0, "R1": 1, "B1": 0}
+	for name, count := range expected {
+		res := f.stub.MockInvokeAs(ids[name], "1", [][]byte{[]byte("queryTransferRequests"), []byte(`{"By":"land","Value":"L1","Complete":"all","PageSize":10}`)})
+		if res.Status != shim.OK {
+			t.Fatal("queryTransferRequests failed", res.Message)
+		}
+		var page struct{ Count int }
+		if err := json.Unmarshal(res.Payload, &page); err != nil || page.Count != count {
+			t.Error(name, "listed", string(res.Payload), "expected", count)
+		}
+	}
+}
+
+func TestTransferRequestIndexes(t *testing.T) {
+	stub := identitytest.Chaincode(t, "transfer_cc", New)
+	request := TransferRequest{ID: "TR1", To: "C2", LandID: "L1", Lawyer: "LW1", Stage: "lawyer", Workflow: defaultWorkflowID, Requester: "C1"}
+
+	stub.MockTransactionStart("1")
+	if err := putTransferRequest(stub, request); err != nil {
+		t.Fatal(err)
+	}
+	stub.MockTransactionEnd("1")
+
+	listed := func(objectType string, attributes ...string) bool {
+		key, _ := stub.CreateCompositeKey(objectType, attributes)
+		return stub.State[key] != nil
+	}
+	if !listed("stage~complete~id", "lawyer", "false", "TR1") || !listed("requester~complete~id", "C1", "false", "TR1") || !listed("land~complete~id", "L1", "false", "TR1") {
+		t.Error("TransferRequest missing from its indexes")
+	}
+	if listed("blro~complete~id", "", "false", "TR1") {
+		t.Error("TransferRequest indexed under an unassigned BLRO")
+	}
+
+	request.Stage = "registry"
+	request.RegistryOfficer = "R1"
+	request.Complete = true
+	stub.MockTransactionStart("2")
+	if err := putTransferRequest(stub, request); err != nil {
+		t.Fatal(err)
+	}
+	stub.MockTransactionEnd("2")
+
+	if listed("stage~complete~id", "lawyer", "false", "TR1") || listed("lawyer~complete~id", "LW1", "false", "TR1") {
+		t.Error("stale index entries left behind")
+	}
+	if !listed("stage~complete~id", "registry", "true", "TR1") || !listed("registryofficer~complete~id", "R1", "true", "TR1") {
+		t.Error("TransferRequest missing from its updated indexes")
+	}
+}
+
+func TestCheckActive(t *testing.T) {
+	stub := identitytest.Chaincode(t, "transfer_cc", New)
+	stub.MockTransactionStart("1")
+	defer stub.MockTransactionEnd("1")
+
+	timestamp, _ := stub.GetTxTimestamp()
+	now := int(timestamp.Seconds)
+
+	if err := checkActive(stub, "L1", professional{Professional: registry.Professional{Status: "active", ValidFrom: now - 60, ValidUntil: now + 60}}); err != nil {
+		t.Error("active licence refused:", err)
+	}
+
+	inactive := map[string]professional{
+		"pending":   {Professional: registry.Professional{Status: "pending"}},
+		"suspended": {Professional: registry.Professional{Status: "suspended", ValidFrom: now - 60, ValidUntil: now + 60}},
+		"revoked":   {Professional: registry.Professional{Status: "revoked", ValidFrom: now - 60, ValidUntil: now + 60}},
+		"expired":   {Professional: registry.Professional{Status: "active", ValidFrom: now - 120, ValidUntil: now - 60}},
+		"not yet":   {Professional: registry.Professional{Status: "active", ValidFrom: now + 60, ValidUntil: now + 120}},
+	}
+	for name, p := range inactive {
+		if err := checkActive(stub, "L1", p); err == nil {
+			t.Error(name, "licence accepted")
+		}
+	}
+}
+
+func TestCovers(t *testing.T) {
+	land := parcel{Owner: "C1", State: "KA", District: "Mysuru", OfficeID: "SRO1"}
+
+	cases := []struct {
+		name      string
+		p         professional
+		chaincode string
+		covers    bool
+	}{
+		{"officer of the office", professional{State: "KA", District: "Mysuru", OfficeID: "SRO1"}, "registryoffice_cc", true},
+		{"officer of another office", professional{State: "KA", District: "Mysuru", OfficeID: "SRO2"}, "registryoffice_cc", false},
+		{"officer without an office", professional{State: "KA", District: "Mysuru"}, "registryoffice_cc", false},
+		{"BLRO of the district", professional{State: "KA", District: "Mysuru"}, "blro_cc", true},
+		{"BLRO of another district", professional{State: "KA", District: "Mandya"}, "blro_cc", false},
+		{"BLRO of the district in another state", professional{State: "TN", District: "Mysuru"}, "blro_cc", false},
+		{"lawyer", professional{}, "lawyer_cc", true},
+	}
+	for _, c := range cases {
+		if covers(c.p, c.chaincode, land) != c.covers {
+			t.Error(c.name, "expected covers =", c.covers)
+		}
+	}
+}
+
+func TestTransactionNames(t *testing.T) {
+	cc, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The names the middleware and the other chaincodes call transactions by
+	names := map[string]bool{}
+	for _, name := range cc.Transactions() {
+		names[name] = true
+	}
+	for _, name := range []string{"createTransferRequest", "readTransferRequest", "advanceTransferRequest", "addArtefact",
+		"transfer2RegistryOfficer", "autoTransfer2RegistryOfficer", "transfer2BLRO", "approveTransferRequest",
+		"reassignLawyer", "reassignRegistryOfficer", "reassignBLRO", "declineTransferRequest", "fileAppeal", "readAppeal",
+		"assignAppealReviewer", "decideAppeal", "closeTransferRequest", "postComment", "replyComment", "requestClarification", "resolveClarification",
+		"listComments", "queryTransferRequests", "createWorkflow", "readWorkflow", "updateIdentityMapping", "readIdentityMapping", "dumpState", "importState"} {
+		if !names[name] {
+			t.Error("transaction", name, "missing")
+		}
+	}
+	if len(names) != 29 {
+		t.Error("unexpected transactions", cc.Transactions())
+	}
+
+	stub := shimtest.NewMockStub("chaincode", cc)
+	res := stub.MockInvoke("1", [][]byte{[]byte(contract.MetadataFunction)})
+	if res.Status != shim.OK {
+		t.Fatal("metadata not served", res.Message)
+	}
+	chaincodeMetadata := metadata.ContractChaincodeMetadata{}
+	if err := json.Unmarshal(res.Payload, &chaincodeMetadata); err != nil {
+		t.Fatal(err)
+	}
+	for _, tx := range chaincodeMetadata.Contracts["transfer_cc"].Transactions {
+		if tx.Name == "CreateWorkflow" && len(tx.Parameters) != 1 {
+			t.Error("unexpected createWorkflow metadata", tx)
+		}
+	}
+	if _, ok := chaincodeMetadata.Components.Schemas["WorkflowStage"].Properties["Chaincode"]; !ok {
+		t.Error("unexpected createWorkflow payload", chaincodeMetadata.Components.Schemas["CreateWorkflowArgs"])
+	}
+}
+
+func TestCreateWorkflowByBLRO(t *testing.T) {
+	stub, ids := identitytest.Instantiate(t, "transfer_cc", New)
+	attributes := map[string]string{"role": "blro", "profileID": "B1"}
+	blro, err := ids.Enroll("BLROMSP", "B1", attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rogue, err := ids.EnrollBy("ca.rogue.example.com", "BLROMSP", "B1", attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	citizen, err := ids.Enroll("CitizenMSP", "C1", map[string]string{"role": "citizen", "profileID": "C1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := []byte(`{"ID":"direct","Description":"Lawyer, then BLRO.","Stages":[` +
+		`{"Name":"lawyer","Title":"Lawyer","MSP":"LawyerMSP","CA":"ca.lawyer.lran.com","Role":"lawyer","Chaincode":"lawyer_cc"},` +
+		`{"Name":"blro","Title":"BLRO","MSP":"BLROMSP","CA":"ca.blro.lran.com","Role":"blro","Chaincode":"blro_cc"}]}`)
+	for name, id := range map[string]*identitytest.Identity{"citizen": citizen, "BLRO of an untrusted CA": rogue} {
+		res := stub.MockInvokeAs(id, "1", [][]byte{[]byte("createWorkflow"), payload})
+		if res.Status == shim.OK {
+			t.Error("createWorkflow by", name, "succeeded")
+		}
+	}
+	res := stub.MockInvokeAs(blro, "2", [][]byte{[]byte("createWorkflow"), payload})
+	if res.Status != shim.OK {
+		t.Fatal("createWorkflow failed", res.Message)
+	}
+
+	read := Workflow{}
+	if err := json.Unmarshal(stub.State["workflow-direct"], &read); err != nil || len(read.Stages) != 2 {
+		t.Error("unexpected workflow", string(stub.State["workflow-direct"]))
+	}
+}
+
+// Transfer of the land L1 of C1, in KA/BLR/SRO1, on the chaincode, the land and the professionals
+// over it, L1 and L2, R1 and R2, B1 and B2, held by the Peer standing in for their chaincodes
+type transferFixture struct {
+	stub *identitytest.Stub
+	ids  *identitytest.Factory
+	peer *identitytest.Peer
+}
+
+func newTransferFixture(t *testing.T) *transferFixture {
+	stub, ids := identitytest.Instantiate(t, "transfer_cc", New)
+	peer := identitytest.NewPeer()
+	stub.Invoker = peer.Invoke
+
+	peer.Put("land_cc", "L1", parcel{Owner: "C1", State: "KA", District: "BLR", OfficeID: "SRO1"})
+	for chaincode, IDs := range map[string][]string{"lawyer_cc": {"L1", "L2"}, "registryoffice_cc": {"R1", "R2"}, "blro_cc": {"B1", "B2"}} {
+		for _, ID := range IDs {
+			profile := &professional{State: "KA", District: "BLR", OfficeID: "SRO1"}
+			profile.ID, profile.Status, profile.ValidUntil = ID, "active", math.MaxInt32
+			if ID == "B2" {
+				profile.Rank = "senior"
+			}
+			peer.Put(chaincode, ID, profile)
+		}
+	}
+	return &transferFixture{stub, ids, peer}
+}
+
+// Enroll Name in MSP, binding the Profile of profileID, if the Peer holds one, to its key
+func (f *transferFixture) enroll(t *testing.T, MSP string, Name string, attributes map[string]string) *identitytest.Identity {
+	id, err := f.ids.Enroll(MSP, Name, attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, records := range f.peer.Records {
+		if profile, ok := records[attributes["profileID"]].(*professional); ok {
+			profile.KeyHash = identity.KeyHash(id.Certificate)
+		}
+	}
+	return id
+}
+
+// Put a TransferRequest of L1 at stage, assigned up to it to L1, R1 and B1
+func (f *transferFixture) put(t *testing.T, request TransferRequest) {
+	f.stub.MockTransactionStart("put")
+	defer f.stub.MockTransactionEnd("put")
+	assignees := map[string]string{}
+	for _, s := range defaultWorkflow.Stages {
+		assignees[s.Name] = map[string]string{"lawyer": "L1", "registry": "R1", "blro": "B1"}[s.Name]
+		if s.Name == request.Stage {
+			break
+		}
+	}
+	request.LandID, request.From, request.To, request.Requester, request.Workflow, request.Assignees = "L1", "C1", "C2", "C1", defaultWorkflowID, assignees
+	if err := putTransferRequest(f.stub, request); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Read the TransferRequest with ID from the ledger
+func (f *transferFixture) read(t *testing.T, ID string) TransferRequest {
+	read := TransferRequest{}
+	if err := json.Unmarshal(f.stub.State["transferRequest-"+ID], &read); err != nil {
+		t.Fatal(err)
+	}
+	return read
+}
+
+// Invoke fcn with payload as id, reporting a failure on t
+func (f *transferFixture) invoke(t *testing.T, id *identitytest.Identity, fcn string, payload string) {
+	t.Helper()
+	res := f.stub.MockInvokeAs(id, "1", [][]byte{[]byte(fcn), []byte(payload)})
+	if res.Status != shim.OK {
+		t.Error(fcn, "failed", res.Message)
+	}
+}
+
+func TestUnboundProfessionalRefused(t *testing.T) {
+	f := newTransferFixture(t)
+	f.put(t, TransferRequest{ID: "TR1", Stage: "blro"})
+	blro, err := f.ids.Enroll("BLROMSP", "B1", map[string]string{"role": "blro", "profileID": "B1", "district": "BLR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A Profile stored before certificates were bound acts only once rekeyed to its certificate
+	decline := [][]byte{[]byte("declineTransferRequest"), []byte(`{"ID":"TR1","Reason":"Survey number mismatch","Date":1580000000}`)}
+	if res := f.stub.MockInvokeAs(blro, "1", decline); res.Status == shim.OK {
+		t.Error("declineTransferRequest by a Profile bound to no certificate succeeded")
+	}
+	f.peer.Records["blro_cc"]["B1"].(*professional).KeyHash = identity.KeyHash(blro.Certificate)
+	if res := f.stub.MockInvokeAs(blro, "2", decline); res.Status != shim.OK {
+		t.Error("declineTransferRequest by the rekeyed Profile failed", res.Message)
+	}
+}
+
+func TestApprovalClosesRequestsOfTheSameLand(t *testing.T) {
+	f := newTransferFixture(t)
+	f.put(t, TransferRequest{ID: "TR1", Stage: "blro"})
+	f.put(t, TransferRequest{ID: "TR2", Stage: "registry"})
+	blro := f.enroll(t, "BLROMSP", "B1", map[string]string{"role": "blro", "profileID": "B1", "district": "BLR"})
+	officer := f.peer.Records["registryoffice_cc"]["R1"].(*professional)
+	officer.ActiveCases = []string{"TR1", "TR2"}
+
+	f.invoke(t, blro, "approveTransferRequest", `{"ID":"TR1","Date":1580000000}`)
+	if read := f.read(t, "TR1"); !read.Complete || read.Declined {
+		t.Error("TransferRequest not approved", read)
+	}
+
+	// TR2 of the same Land is declined and closed with it, its cases completed
+	read := f.read(t, "TR2")
+	if !read.Complete || !read.Declined || read.DeclineReason != "Land transferred by TransferRequest TR1" {
+		t.Error("TransferRequest of the sold Land left open", read)
+	}
+	// R1 holds both requests. Each call to registryoffice_cc reads the committed Profile, as on a peer,
+	// and the last one written wins, so both cases are completed in one write
+	var written cases.Cases
+	for _, payload := range f.peer.Called("registryoffice_cc", "updateCases") {
+		args := cases.UpdateArgs{}
+		if err := json.Unmarshal(payload, &args); err != nil {
+			t.Fatal(err)
+		}
+		written = cases.Cases{ActiveCases: append([]string{}, officer.ActiveCases...)}
+		for _, change := range args.Changes {
+			if !written.Apply(change) {
+				t.Error("case of R1 not active", change)
+			}
+		}
+	}
+	if len(written.ActiveCases) != 0 || len(written.CompletedCases) != 2 {
+		t.Error("cases of R1 left active", written, f.peer.Calls)
+	}
+}
+
+func TestReassignRequiresAuthorization(t *testing.T) {
+	f := newTransferFixture(t)
+	f.put(t, TransferRequest{ID: "TR1", Stage: "blro"})
+	citizen := f.enroll(t, "CitizenMSP", "C1", map[string]string{"role": "citizen", "profileID": "C1"})
+	officer := f.enroll(t, "RegistryOfficeMSP", "R1", map[string]string{"role": "registryofficer", "profileID": "R1", "officeID": "SRO1"})
+	blro := f.enroll(t, "BLROMSP", "B1", map[string]string{"role": "blro", "profileID": "B1", "district": "BLR"})
+
+	calls := []struct {
+		fcn     string
+		payload string
+		caller  *identitytest.Identity
+		stage   string
+	}{
+		{"reassignLawyer", `{"ID":"TR1","Lawyer":"L2","Date":1580000000}`, citizen, "lawyer"},
+		{"reassignRegistryOfficer", `{"ID":"TR1","RegistryOfficer":"R2","Date":1580000000}`, officer, "registry"},
+		{"reassignBLRO", `{"ID":"TR1","BLRO":"B2","Date":1580000000}`, blro, "blro"},
+	}
+	for _, c := range calls {
+		res := f.stub.MockInvokeAs(nil, "1", [][]byte{[]byte(c.fcn), []byte(c.payload)})
+		if res.Status == shim.OK {
+			t.Error(c.fcn, "succeeded without an authorized creator")
+		}
+	}
+	for _, c := range calls {
+		f.invoke(t, c.caller, c.fcn, c.payload)
+	}
+
+	read := f.read(t, "TR1")
+	if read.Assignees["lawyer"] != "L2" || read.Assignees["registry"] != "R2" || read.Assignees["blro"] != "B2" || len(read.Reassignments) != 3 {
+		t.Error("unexpected reassignments", read.Assignees, read.Reassignments)
+	}
+	if len(f.peer.Called("blro_cc", "removeCase")) != 1 || len(f.peer.Called("blro_cc", "addCase")) != 1 {
+		t.Error("cases not moved to the new BLRO", f.peer.Calls)
+	}
+}
+
+func TestAutoTransfer2RegistryOfficerRequiresAuthorization(t *testing.T) {
+	f := newTransferFixture(t)
+	f.put(t, TransferRequest{ID: "TR1", Stage: "lawyer"})
+	f.peer.Responses["registryoffice_cc/getLeastBusyRegistryOfficer"] = map[string]string{"ID": "R2"}
+	lawyer := f.enroll(t, "LawyerMSP", "L1", map[string]string{"role": "lawyer", "profileID": "L1"})
+	other := f.enroll(t, "LawyerMSP", "L2", map[string]string{"role": "lawyer", "profileID": "L2"})
+
+	payload := `{"ID":"TR1","Date":1580000000}`
+	for name, id := range map[string]*identitytest.Identity{"no one": nil, "a Lawyer not assigned": other} {
+		res := f.stub.MockInvokeAs(id, "1", [][]byte{[]byte("autoTransfer2RegistryOfficer"), []byte(payload)})
+		if res.Status == shim.OK {
+			t.Error("autoTransfer2RegistryOfficer by", name, "succeeded")
+		}
+	}
+	f.invoke(t, lawyer, "autoTransfer2RegistryOfficer", payload)
+
+	read := f.read(t, "TR1")
+	if read.Stage != "registry" || read.Assignees["registry"] != "R2" {
+		t.Error("TransferRequest not forwarded to the least busy RegistryOfficer", read.Stage, read.Assignees)
+	}
+}
+
+func TestAppealRequiresAuthorization(t *testing.T) {
+	f := newTransferFixture(t)
+	f.put(t, TransferRequest{ID: "TR1", Stage: "blro"})
+	citizen := f.enroll(t, "CitizenMSP", "C1", map[string]string{"role": "citizen", "profileID": "C1"})
+	blro := f.enroll(t, "BLROMSP", "B1", map[string]string{"role": "blro", "profileID": "B1", "district": "BLR"})
+	reviewer := f.enroll(t, "BLROMSP", "B2", map[string]string{"role": "blro", "profileID": "B2", "district": "BLR"})
+
+	calls := []struct {
+		fcn     string
+		payload string
+		caller  *identitytest.Identity
+	}{
+		{"declineTransferRequest", `{"ID":"TR1","Reason":"Survey number mismatch","Date":1580000000}`, blro},
+		{"fileAppeal", `{"ID":"A1","TransferRequestID":"TR1","Grounds":"Survey corrected","Documents":["0xabc"],"Date":1580000001}`, citizen},
+		{"assignAppealReviewer", `{"ID":"A1","Reviewer":"B2","Date":1580000002}`, blro},
+		{"decideAppeal", `{"ID":"A1","Outcome":"overturned","Date":1580000003}`, reviewer},
+	}
+	for _, c := range calls {
+		res := f.stub.MockInvokeAs(nil, "1", [][]byte{[]byte(c.fcn), []byte(c.payload)})
+		if res.Status == shim.OK {
+			t.Error(c.fcn, "succeeded without an authorized creator")
+		}
+		f.invoke(t, c.caller, c.fcn, c.payload)
+	}
+
+	appeal := Appeal{}
+	if err := json.Unmarshal(f.stub.State["appeal-A1"], &appeal); err != nil {
+		t.Fatal(err)
+	}
+	if appeal.Reviewer != "B2" || appeal.Outcome != "overturned" || appeal.Appellant != "C1" {
+		t.Error("unexpected appeal", appeal)
+	}
+	if read := f.read(t, "TR1"); read.Declined || read.Complete || read.Appeal != "A1" || read.Assignees["blro"] != "B2" || read.BLRO != "B2" {
+		t.Error("TransferRequest not reopened for the Reviewer", read)
+	}
+	if len(f.peer.Called("blro_cc", "removeCase")) != 1 || len(f.peer.Called("blro_cc", "addCase")) != 1 {
+		t.Error("case not handed over to the Reviewer", f.peer.Calls)
+	}
+}
+
+func TestAppealReviewerMustBeSenior(t *testing.T) {
+	f := newTransferFixture(t)
+	f.put(t, TransferRequest{ID: "TR1", Stage: "blro"})
+	citizen := f.enroll(t, "CitizenMSP", "C1", map[string]string{"role": "citizen", "profileID": "C1"})
+	blro := f.enroll(t, "BLROMSP", "B1", map[string]string{"role": "blro", "profileID": "B1", "district": "BLR"})
+	f.invoke(t, blro, "declineTransferRequest", `{"ID":"TR1","Reason":"Survey number mismatch","Date":1580000000}`)
+	f.invoke(t, citizen, "fileAppeal", `{"ID":"A1","TransferRequestID":"TR1","Grounds":"Survey corrected","Date":1580000001}`)
+
+	// B2 demoted to junior can no longer review
+	f.peer.Records["blro_cc"]["B2"].(*professional).Rank = "junior"
+	res := f.stub.MockInvokeAs(blro, "1", [][]byte{[]byte("assignAppealReviewer"), []byte(`{"ID":"A1","Reviewer":"B2","Date":1580000002}`)})
+	if envelope.CodeOf(errors.New(res.Message)) != envelope.CodeInvalidArgument {
+		t.Error("junior Reviewer assigned", res.Message)
+	}
+}
+
+func TestCloseDeclinedTransferRequest(t *testing.T) {
+	f := newTransferFixture(t)
+	f.put(t, TransferRequest{ID: "TR1", Stage: "blro"})
+	f.put(t, TransferRequest{ID: "TR2", Stage: "blro"})
+	citizen := f.enroll(t, "CitizenMSP", "C1", map[string]string{"role": "citizen", "profileID": "C1"})
+	blro := f.enroll(t, "BLROMSP", "B1", map[string]string{"role": "blro", "profileID": "B1", "district": "BLR"})
+	f.invoke(t, blro, "declineTransferRequest", `{"ID":"TR1","Reason":"Survey number mismatch","Date":1580000000}`)
+	f.invoke(t, blro, "declineTransferRequest", `{"ID":"TR2","Reason":"Survey number mismatch","Date":1580000000}`)
+	deadline := f.read(t, "TR1").AppealDeadline
+	if deadline == 0 {
+		t.Fatal("no appeal deadline set")
+	}
+
+	// The BLRO waits out the appeal deadline, the Requester may waive it
+	closing := [][]byte{[]byte("closeTransferRequest"), []byte(`{"ID":"TR1","Date":1580000001}`)}
+	if res := f.stub.MockInvokeAs(blro, "1", closing); res.Status == shim.OK {
+		t.Error("closeTransferRequest by the BLRO before the deadline succeeded")
+	}
+	f.invoke(t, citizen, "closeTransferRequest", `{"ID":"TR2","Date":1580000001}`)
+	if read := f.read(t, "TR2"); !read.Complete {
+		t.Error("TransferRequest not closed by its Requester", read)
+	}
+
+	// Past the deadline the request can no longer be appealed, only closed
+	after := int64(deadline) + 1
+	res := f.stub.MockInvokeAt(citizen, "2", [][]byte{[]byte("fileAppeal"), []byte(`{"ID":"A1","TransferRequestID":"TR1","Grounds":"Late","Date":1580000002}`)}, after)
+	if envelope.CodeOf(errors.New(res.Message)) != envelope.CodeInvalidState {
+		t.Error("fileAppeal after the deadline", res.Message)
+	}
+	completed := len(f.peer.Called("blro_cc", "updateCases"))
+	if res := f.stub.MockInvokeAt(blro, "3", closing, after); res.Status != shim.OK {
+		t.Fatal("closeTransferRequest after the deadline failed", res.Message)
+	}
+	if read := f.read(t, "TR1"); !read.Complete || !read.Declined {
+		t.Error("TransferRequest not closed", read)
+	}
+	if len(f.peer.Called("blro_cc", "updateCases")) != completed+1 {
+		t.Error("cases not completed on close", f.peer.Calls)
+	}
+	if res := f.stub.MockInvokeAt(blro, "4", closing, after); res.Status == shim.OK {
+		t.Error("closed a TransferRequest twice")
+	}
+}
@@ -0,0 +1,290 @@
+package transfer
+
+import (
+	"encoding/json"
+
+	"example.org/lib/contract"
+	"example.org/lib/envelope"
+	"example.org/lib/events"
+	"example.org/lib/identity"
+	"example.org/lib/query"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Definition of the Comment structure
+// Comments live under composite keys comment~TransferRequestID~ID, outside the TransferRequest.
+// Stage is the stage the Author held when commenting, empty for the citizen who requested the transfer.
+type Comment struct {
+	ID                string `json:"ID"`
+	TransferRequestID string `json:"TransferRequestID"`
+	Author            string `json:"Author"`
+	AuthorMSP         string `json:"AuthorMSP"`
+	Stage             string `json:"Stage,omitempty"`
+	Message           string `json:"Message"`
+	Document          string `json:"Document"`
+	ReplyTo           string `json:"ReplyTo"`
+	Clarification     bool   `json:"Clarification"`
+	Resolved          bool   `json:"Resolved"`
+	Date              int    `json:"Date"`
+	Type              string `json:"Type"`
+}
+
+// Object type of the composite keys Comments are stored under
+const commentIndex = "comment"
+
+// Payload of postComment and requestClarification
+// Document is the hash of an attached document, left out if none
+type CommentArgs struct {
+	TransferRequestID string `json:"TransferRequestID" validate:"required,key"`
+	ID                string `json:"ID" validate:"required,key"`
+	Message           string `json:"Message" validate:"required"`
+	Date              int    `json:"Date" validate:"required,min=1"`
+	Document          string `json:"Document,omitempty"`
+}
+
+// Payload of replyComment, ReplyTo being the ID of the Comment replied to
+type ReplyCommentArgs struct {
+	CommentArgs
+	ReplyTo string `json:"ReplyTo" validate:"required,key"`
+}
+
+// Payload of resolveClarification
+type ResolveClarificationArgs struct {
+	TransferRequestID string `json:"TransferRequestID" validate:"required,key"`
+	ID                string `json:"ID" validate:"required,key"`
+	Date              int    `json:"Date" validate:"required,min=1"`
+}
+
+// Payload of listComments
+// Bookmark optionally continues from a previous page
+type ThreadArgs struct {
+	TransferRequestID string `json:"TransferRequestID" validate:"required,key"`
+	PageSize          int32  `json:"PageSize" validate:"required,min=1,max=100"`
+	Bookmark          string `json:"Bookmark,omitempty"`
+}
+
+// Function to post a comment on a transferRequest, optionally attaching the hash of a Document (C of CRUD)
+func (cc *Chaincode) PostComment(ctx *contract.TransactionContext, args CommentArgs) error {
+	return createComment(ctx, args, "", false)
+}
+
+// Function to reply to a comment on a transferRequest, optionally attaching the hash of a Document (C of CRUD)
+func (cc *Chaincode) ReplyComment(ctx *contract.TransactionContext, args ReplyCommentArgs) error {
+	// Check the Comment replied to exists on the same TransferRequest
+	_, err := getComment(ctx.GetStub(), args.TransferRequestID, args.ReplyTo)
+	if err != nil {
+		return err
+	}
+
+	return createComment(ctx, args.CommentArgs, args.ReplyTo, false)
+}
+
+// Function to ask the other parties of a transferRequest for a clarification, blocking it until resolved (C of CRUD)
+func (cc *Chaincode) RequestClarification(ctx *contract.TransactionContext, args CommentArgs) error {
+	return createComment(ctx, args, "", true)
+}
+
+// Function to resolve a clarification, by the party who asked for it, the professional now holding the stage
+// they asked from, or the citizen who requested the transfer (U of CRUD)
+func (cc *Chaincode) ResolveClarification(ctx *contract.TransactionContext, args ResolveClarificationArgs) error {
+	commentToUpdate, err := getComment(ctx.GetStub(), args.TransferRequestID, args.ID)
+	if err != nil {
+		return err
+	}
+	if !commentToUpdate.Clarification || commentToUpdate.Resolved {
+		return envelope.InvalidState("Comment is not an open clarification!", "ID", args.ID)
+	}
+
+	transferRequestToUpdate, err := getTransferRequest(ctx.GetStub(), args.TransferRequestID)
+	if err != nil {
+		return err
+	}
+	workflow, err := getWorkflow(ctx.GetStub(), transferRequestToUpdate.Workflow)
+	if err != nil {
+		return err
+	}
+
+	// A clarification outlives a reassignment of its Author, so it must not block the request for good
+	if !canResolve(ctx, commentToUpdate, transferRequestToUpdate, workflow) {
+		return ctx.AccessDenied("MSP", ctx.MSP, "Author", commentToUpdate.Author)
+	}
+
+	// Update comment.Resolved => true
+	commentToUpdate.Resolved = true
+	err = putComment(ctx.GetStub(), commentToUpdate)
+	if err != nil {
+		return err
+	}
+
+	// Generate StatusHistory and unblock the TransferRequest
+	status := StatusHistory{"Clarification " + args.ID + " resolved.", ctx.Creator, args.Date}
+	transferRequestToUpdate.StatusHistory = append(transferRequestToUpdate.StatusHistory, status)
+	transferRequestToUpdate.OpenClarifications--
+	err = putTransferRequest(ctx.GetStub(), transferRequestToUpdate)
+	if err != nil {
+		return err
+	}
+	return ctx.Emit(events.ClarificationResolved{TransferRequestID: args.TransferRequestID, CommentID: args.ID, Author: ctx.Creator, Date: args.Date})
+}
+
+// Function to list the comments of a transferRequest, a page at a time, only to those who can read it (R of CRUD)
+func (cc *Chaincode) ListComments(ctx *contract.TransactionContext, args ThreadArgs) (json.RawMessage, error) {
+	transferRequestToRead, err := getTransferRequest(ctx.GetStub(), args.TransferRequestID)
+	if err != nil {
+		return nil, err
+	}
+	workflow, err := getWorkflow(ctx.GetStub(), transferRequestToRead.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	if !canRead(ctx, transferRequestToRead, workflow) {
+		return nil, ctx.AccessDenied()
+	}
+
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(commentIndex, []string{args.TransferRequestID}, args.PageSize, args.Bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	// Page of the Comments of the thread
+	var records [][]byte
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, queryResponse.Value)
+	}
+
+	// Returned on successful execution of the function
+	return query.Page(records, metadata.Bookmark)
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Create a Comment by the Tx Creator, replying to the Comment with ID replyTo, empty if none,
+// or a clarification blocking the TransferRequest.
+func createComment(ctx *contract.TransactionContext, args CommentArgs, replyTo string, clarification bool) error {
+	transferRequestToUpdate, err := getTransferRequest(ctx.GetStub(), args.TransferRequestID)
+	if err != nil {
+		return err
+	}
+	if transferRequestToUpdate.Complete {
+		return envelope.InvalidState("TransferRequest is already complete!", "ID", args.TransferRequestID)
+	}
+	workflow, err := getWorkflow(ctx.GetStub(), transferRequestToUpdate.Workflow)
+	if err != nil {
+		return err
+	}
+
+	// Only parties to the TransferRequest can take part in its thread
+	if !isParty(ctx.GetStub(), transferRequestToUpdate, workflow, ctx.MSP, ctx.CA, ctx.Creator) {
+		return ctx.AccessDenied()
+	}
+	stage := heldStage(ctx.GetStub(), transferRequestToUpdate, workflow, ctx.MSP, ctx.CA)
+
+	// Check if Comment exists, any failure to tell but NOT_FOUND refusing the Comment
+	_, err = getComment(ctx.GetStub(), args.TransferRequestID, args.ID)
+	if err == nil {
+		return envelope.Conflict("Comment Already Exists!", "ID", args.ID)
+	} else if envelope.CodeOf(err) != envelope.CodeNotFound {
+		return err
+	}
+
+	// Generate Comment from params provided
+	comment := Comment{args.ID, args.TransferRequestID, ctx.Creator, ctx.MSP, stage, args.Message, args.Document, replyTo, clarification, false, args.Date, "COMMENT"}
+	err = putComment(ctx.GetStub(), comment)
+	if err != nil {
+		return err
+	}
+
+	// A clarification blocks the TransferRequest until it is resolved
+	if clarification {
+		status := StatusHistory{"Clarification " + args.ID + " requested.", ctx.Creator, args.Date}
+		transferRequestToUpdate.StatusHistory = append(transferRequestToUpdate.StatusHistory, status)
+		transferRequestToUpdate.OpenClarifications++
+		err = putTransferRequest(ctx.GetStub(), transferRequestToUpdate)
+		if err != nil {
+			return err
+		}
+	}
+	return ctx.Emit(events.CommentPosted{
+		TransferRequestID: args.TransferRequestID,
+		CommentID:         args.ID,
+		Author:            ctx.Creator,
+		AuthorMSP:         ctx.MSP,
+		ReplyTo:           replyTo,
+		Clarification:     clarification,
+		Document:          args.Document,
+		Date:              args.Date,
+	})
+}
+
+// Check the creator is the citizen who requested the transfer or the Assignee of one of its stages
+func isParty(stub shim.ChaincodeStubInterface, t TransferRequest, w Workflow, mspID string, certCN string, creator string) bool {
+	if identity.HasRole(stub, "citizen", mspID, certCN) && t.Requester == creator {
+		return true
+	}
+	return heldStage(stub, t, w, mspID, certCN) != ""
+}
+
+// Stage of the TransferRequest whose Assignee is the creator, empty if none
+func heldStage(stub shim.ChaincodeStubInterface, t TransferRequest, w Workflow, mspID string, certCN string) string {
+	for _, s := range w.Stages {
+		if t.Assignees[s.Name] != "" && authenticateStage(stub, s, mspID, certCN) && authorizeAssignee(stub, t, s) {
+			return s.Name
+		}
+	}
+	return ""
+}
+
+// Check the creator asked for the clarification c, requested the transfer, or now holds the stage c was asked from
+func canResolve(ctx *contract.TransactionContext, c Comment, t TransferRequest, w Workflow) bool {
+	if c.Author == ctx.Creator && c.AuthorMSP == ctx.MSP {
+		return true
+	}
+	if ctx.HasRole("citizen") && t.Requester == ctx.Creator {
+		return true
+	}
+	return c.Stage != "" && heldStage(ctx.GetStub(), t, w, ctx.MSP, ctx.CA) == c.Stage
+}
+
+// Get Comment with ID on TransferRequest with TransferRequestID
+func getComment(stub shim.ChaincodeStubInterface, TransferRequestID string, ID string) (Comment, error) {
+	commentToRead := Comment{}
+
+	key, err := stub.CreateCompositeKey(commentIndex, []string{TransferRequestID, ID})
+	if err != nil {
+		return commentToRead, err
+	}
+
+	// Get State of Comment with Key => comment~TransferRequestID~ID
+	commentAsBytes, err := stub.GetState(key)
+	if err != nil {
+		return commentToRead, envelope.Internal("Failed to get state for " + ID)
+	} else if commentAsBytes == nil {
+		return commentToRead, envelope.NotFound("Comment does not exist!", "ID", ID)
+	}
+
+	err = json.Unmarshal(commentAsBytes, &commentToRead) //unmarshal it aka JSON.parse()
+	return commentToRead, err
+}
+
+// Put State of Comment with Key => comment~TransferRequestID~ID
+func putComment(stub shim.ChaincodeStubInterface, c Comment) error {
+	key, err := stub.CreateCompositeKey(commentIndex, []string{c.TransferRequestID, c.ID})
+	if err != nil {
+		return err
+	}
+
+	// Convert to Byte[]
+	commentJSONasBytes, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return stub.PutState(key, commentJSONasBytes)
+}
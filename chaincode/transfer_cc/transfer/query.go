@@ -0,0 +1,208 @@
+package transfer
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"example.org/lib/contract"
+	"example.org/lib/query"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Definition of an index entry of a TransferRequest
+// The entry is stored as the composite key ObjectType~Attributes...~Complete~ID with an empty value.
+type IndexEntry struct {
+	ObjectType string
+	Attributes []string
+}
+
+// Object types of the composite key indexes, by the attribute a query can select on
+var transferRequestIndexes = map[string]string{
+	"stage":           "stage~complete~id",
+	"lawyer":          "lawyer~complete~id",
+	"registryOfficer": "registryofficer~complete~id",
+	"blro":            "blro~complete~id",
+	"requester":       "requester~complete~id",
+	"to":              "to~complete~id",
+	"land":            "land~complete~id",
+}
+
+// Payload of queryTransferRequests
+// By is the attribute selected on, and Bookmark optionally continues from a previous page
+type QueryArgs struct {
+	By       string `json:"By" validate:"required,oneof=stage lawyer registryOfficer blro requester to land"`
+	Value    string `json:"Value" validate:"required"`
+	Complete string `json:"Complete" validate:"required,oneof=true false all"`
+	PageSize int32  `json:"PageSize" validate:"required,min=1,max=100"`
+	Bookmark string `json:"Bookmark,omitempty"`
+}
+
+// Function to list transferRequests by an indexed attribute, a page at a time (R of CRUD).
+// Only the requests the caller is a party to are listed, so a page may hold fewer than PageSize.
+func (cc *Chaincode) QueryTransferRequests(ctx *contract.TransactionContext, args QueryArgs) (json.RawMessage, error) {
+	objectType, ok := transferRequestIndexes[args.By]
+	if !ok {
+		return nil, contract.InvalidArguments("By", "is not an index of TransferRequests")
+	}
+
+	// Complete follows the selected attribute, so "all" simply leaves it out of the partial key
+	keys := []string{args.Value}
+	if args.Complete != "all" {
+		keys = append(keys, args.Complete)
+	}
+
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(objectType, keys, args.PageSize, args.Bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	// Page of the TransferRequests the index entries point to, that the caller is a party to
+	var records [][]byte
+	workflows := map[string]Workflow{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		// The ID is the last attribute of every index entry
+		transferRequestAsBytes, err := ctx.GetStub().GetState("transferRequest-" + attributes[len(attributes)-1])
+		if err != nil {
+			return nil, err
+		}
+		transferRequestToRead := TransferRequest{}
+		err = json.Unmarshal(transferRequestAsBytes, &transferRequestToRead)
+		if err != nil {
+			return nil, err
+		}
+		workflow, ok := workflows[transferRequestToRead.Workflow]
+		if !ok {
+			workflow, err = getWorkflow(ctx.GetStub(), transferRequestToRead.Workflow)
+			if err != nil {
+				return nil, err
+			}
+			workflows[transferRequestToRead.Workflow] = workflow
+		}
+		if canRead(ctx, transferRequestToRead, workflow) {
+			records = append(records, transferRequestAsBytes)
+		}
+	}
+
+	// Returned on successful execution of the function
+	return query.Page(records, metadata.Bookmark)
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Check the Tx Creator is a party to TransferRequest t, or the citizen the Land is transferred to
+func canRead(ctx *contract.TransactionContext, t TransferRequest, w Workflow) bool {
+	if ctx.HasRole("citizen") && t.To == ctx.Creator {
+		return true
+	}
+	return isParty(ctx.GetStub(), t, w, ctx.MSP, ctx.CA, ctx.Creator)
+}
+
+// Object types of the composite keys the chaincode stores: Comments, and the index entries of TransferRequests
+func compositeKeys() []string {
+	objectTypes := []string{commentIndex}
+	for _, objectType := range transferRequestIndexes {
+		objectTypes = append(objectTypes, objectType)
+	}
+	sort.Strings(objectTypes[1:])
+	return objectTypes
+}
+
+// Index entries a TransferRequest should be listed under
+func indexEntries(t TransferRequest) []IndexEntry {
+	values := map[string]string{
+		"stage":           t.Stage,
+		"lawyer":          t.Lawyer,
+		"registryOfficer": t.RegistryOfficer,
+		"blro":            t.BLRO,
+		"requester":       t.Requester,
+		"to":              t.To,
+		"land":            t.LandID,
+	}
+
+	// Sort the attributes so every peer writes the entries in the same order
+	var names []string
+	for by := range transferRequestIndexes {
+		names = append(names, by)
+	}
+	sort.Strings(names)
+
+	var entries []IndexEntry
+	for _, by := range names {
+		if values[by] == "" {
+			continue
+		}
+		entries = append(entries, IndexEntry{transferRequestIndexes[by], []string{values[by], strconv.FormatBool(t.Complete), t.ID}})
+	}
+	return entries
+}
+
+// Bring the index entries of a TransferRequest in line with its new State.
+// previous is nil when the TransferRequest is being created.
+func updateIndexes(stub shim.ChaincodeStubInterface, previous *TransferRequest, t TransferRequest) error {
+	currentKeys, err := indexKeys(stub, t)
+	if err != nil {
+		return err
+	}
+
+	// Drop the entries the TransferRequest no longer matches
+	if previous != nil {
+		previousKeys, err := indexKeys(stub, *previous)
+		if err != nil {
+			return err
+		}
+		for _, key := range previousKeys {
+			if !containsKey(currentKeys, key) {
+				err = stub.DelState(key)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Entries are rewritten every time, so requests stored before indexing get listed on their next update.
+	// Only the key matters, but Fabric does not store empty values.
+	for _, key := range currentKeys {
+		err = stub.PutState(key, []byte{0x00})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Composite keys of the index entries of a TransferRequest
+func indexKeys(stub shim.ChaincodeStubInterface, t TransferRequest) ([]string, error) {
+	var keys []string
+	for _, e := range indexEntries(t) {
+		key, err := stub.CreateCompositeKey(e.ObjectType, e.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Check if key is one of keys
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package transfer
+
+import (
+	"encoding/json"
+
+	"example.org/lib/contract"
+	"example.org/lib/envelope"
+	"example.org/lib/events"
+	"example.org/lib/identity"
+	"example.org/lib/registry"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Definition of a stage of a Workflow
+// Role is the role attribute the certificates of the stage's actors must carry.
+type WorkflowStage struct {
+	Name      string   `json:"Name" validate:"required,key"`
+	Title     string   `json:"Title" validate:"required"`
+	MSP       string   `json:"MSP" validate:"required"`
+	CA        string   `json:"CA" validate:"required"`
+	Role      string   `json:"Role" validate:"required"`
+	Chaincode string   `json:"Chaincode"`
+	Artefacts []string `json:"Artefacts"`
+}
+
+// Definition of the Workflow structure
+type Workflow struct {
+	ID          string          `json:"ID"`
+	Description string          `json:"Description"`
+	Stages      []WorkflowStage `json:"Stages"`
+	Type        string          `json:"Type"`
+}
+
+// Payload of createWorkflow
+type CreateWorkflowArgs struct {
+	ID          string          `json:"ID" validate:"required,key"`
+	Description string          `json:"Description"`
+	Stages      []WorkflowStage `json:"Stages" validate:"required"`
+}
+
+// ID of the Workflow used when a TransferRequest does not select one
+const defaultWorkflowID = "default"
+
+// Workflow every TransferRequest followed before workflows were configurable
+var defaultWorkflow = Workflow{
+	ID:          defaultWorkflowID,
+	Description: "Lawyer, then Registry Officer, then BLRO.",
+	Stages: []WorkflowStage{
+		{"lawyer", "Lawyer", "LawyerMSP", "ca.lawyer.lran.com", "lawyer", "lawyer_cc", nil},
+		{"registry", "Registry Officer", "RegistryOfficeMSP", "ca.registryoffice.lran.com", "registryofficer", "registryoffice_cc", nil},
+		{"blro", "BLRO", "BLROMSP", "ca.blro.lran.com", "blro", "blro_cc", nil},
+	},
+	Type: "WORKFLOW",
+}
+
+// Chaincodes a stage may track its professional's cases in
+var caseChaincodes = map[string]bool{
+	"":                  true,
+	"lawyer_cc":         true,
+	"registryoffice_cc": true,
+	"blro_cc":           true,
+}
+
+// Role attribute of stages stored before Workflows named one, by the stage's MSP
+var mspRoles = map[string]string{
+	"CitizenMSP":        "citizen",
+	"LawyerMSP":         "lawyer",
+	"RegistryOfficeMSP": "registryofficer",
+	"BLROMSP":           "blro",
+}
+
+// Function to create new workflow (C of CRUD)
+func (cc *Chaincode) CreateWorkflow(ctx *contract.TransactionContext, args CreateWorkflowArgs) error {
+	key := "workflow-" + args.ID
+
+	// Check if Workflow exists with Key => key
+	// Workflows are never updated, so requests already following one keep their stages
+	workflowAsBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return envelope.Internal("Failed to check if Workflow exists!")
+	} else if workflowAsBytes != nil || args.ID == defaultWorkflowID {
+		return envelope.Conflict("Workflow Already Exists!", "ID", args.ID)
+	}
+
+	// Generate Workflow from params provided
+	workflow := &Workflow{args.ID, args.Description, args.Stages, "WORKFLOW"}
+	err = validateWorkflow(workflow)
+	if err != nil {
+		return err
+	}
+	workflowJSONasBytes, err := json.Marshal(workflow)
+	if err != nil {
+		return err
+	}
+
+	// Put State of newly generated Workflow with Key => key
+	err = ctx.GetStub().PutState(key, workflowJSONasBytes)
+	if err != nil {
+		return err
+	}
+
+	// CreateWorkflowArgs has no Date, so the Workflow is dated by its transaction
+	Date, err := registry.TxTime(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	var stages []string
+	for _, s := range args.Stages {
+		stages = append(stages, s.Name)
+	}
+	return ctx.Emit(events.WorkflowCreated{WorkflowID: args.ID, Stages: stages, Creator: ctx.Creator, Date: Date})
+}
+
+// Function to read a workflow (R of CRUD)
+func (cc *Chaincode) ReadWorkflow(ctx *contract.TransactionContext, args contract.IDArgs) (Workflow, error) {
+	return getWorkflow(ctx.GetStub(), args.ID)
+}
+
+// ---------------------------------------------
+// Helper Functions
+// ---------------------------------------------
+
+// Get Workflow with ID, falling back to the built-in default workflow
+func getWorkflow(stub shim.ChaincodeStubInterface, ID string) (Workflow, error) {
+	workflowToRead := Workflow{}
+
+	// Get State of Workflow with Key => workflow-ID
+	workflowAsBytes, err := stub.GetState("workflow-" + ID)
+	if err != nil {
+		return workflowToRead, envelope.Internal("Failed to get state for " + ID)
+	} else if workflowAsBytes == nil {
+		if ID == defaultWorkflowID {
+			return defaultWorkflow, nil
+		}
+		return workflowToRead, envelope.NotFound("Workflow does not exist!", "ID", ID)
+	}
+
+	err = json.Unmarshal(workflowAsBytes, &workflowToRead) //unmarshal it aka JSON.parse()
+	if err != nil {
+		return workflowToRead, err
+	}
+
+	for i, s := range workflowToRead.Stages {
+		if s.Role == "" {
+			workflowToRead.Stages[i].Role = mspRoles[s.MSP]
+		}
+	}
+	return workflowToRead, nil
+}
+
+// Check a Workflow is usable before it is stored
+func validateWorkflow(w *Workflow) error {
+	if len(w.Stages) == 0 {
+		return contract.InvalidArguments("Stages", "must have at least one stage")
+	}
+
+	names := map[string]bool{}
+	for _, s := range w.Stages {
+		if s.Name == "" || s.Title == "" || s.MSP == "" || s.CA == "" || s.Role == "" {
+			return contract.InvalidArguments("Stages", "must give every stage a Name, Title, MSP, CA and Role")
+		} else if names[s.Name] {
+			return contract.InvalidArguments("Stages", "defines stage "+s.Name+" twice")
+		} else if !caseChaincodes[s.Chaincode] {
+			return contract.InvalidArguments("Stages", "uses an unknown chaincode "+s.Chaincode+" in stage "+s.Name)
+		}
+		names[s.Name] = true
+	}
+
+	// Only BLRO may record the change of ownership in land_cc
+	if w.Stages[len(w.Stages)-1].Role != "blro" {
+		return contract.InvalidArguments("Stages", "must end with a stage acted on by the blro role")
+	}
+	return nil
+}
+
+// Index of the stage named name in the Workflow, -1 if missing
+func stageIndex(w Workflow, name string) int {
+	for i, s := range w.Stages {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Authenticate => Workflow Stage
+// Roles of the identity mapping follow it, other roles keep the MSP and CA named by the stage.
+func authenticateStage(stub shim.ChaincodeStubInterface, s WorkflowStage, mspID string, certCN string) bool {
+	mapping, err := identity.GetMapping(stub)
+	if err != nil {
+		return false
+	} else if _, ok := mapping[s.Role]; ok {
+		return identity.Authenticate(stub, s.Role, mspID, certCN)
+	}
+	return (mspID == s.MSP) && (certCN == s.CA)
+}
+
+// Authorize => the Assignee of stage s of the TransferRequest, with the certificate bound to its active Profile,
+// so professionals only act on their own cases while licensed
+func authorizeAssignee(stub shim.ChaincodeStubInterface, t TransferRequest, s WorkflowStage) bool {
+	return identity.AuthorizeRole(stub, s.Role) && identity.AuthorizeProfile(stub, t.Assignees[s.Name]) && authorizeProfessional(stub, s.Chaincode, t.Assignees[s.Name])
+}
@@ -22,7 +22,10 @@ txhandler = async (user, payload) => {
     const contract = network.getContract("blro_cc");
 
     // Evaluate the specified transaction.
-    await contract.submitTransaction("createBLRO", payload.ID, payload.Name, payload.Description);
+    await contract.submitTransaction(
+        "createBLRO",
+        JSON.stringify({ ID: payload.ID, Name: payload.Name, Description: payload.Description })
+    );
 };
 
 module.exports = txhandler;
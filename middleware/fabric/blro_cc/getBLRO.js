@@ -22,7 +22,7 @@ txhandler = async (user, ID) => {
     const contract = network.getContract("blro_cc");
 
     // Evaluate the specified transaction.
-    const result = await contract.evaluateTransaction("readBLRO", ID);
+    const result = await contract.evaluateTransaction("readBLRO", JSON.stringify({ ID }));
 
     return JSON.parse(result.toString());
 };
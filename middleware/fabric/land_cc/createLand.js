@@ -22,7 +22,18 @@ txhandler = async (user, payload) => {
     const contract = network.getContract("land_cc");
 
     // Evaluate the specified transaction.
-    await contract.submitTransaction("createLand", payload.ID, payload.Address, payload.Owner, payload.Date);
+    await contract.submitTransaction(
+        "createLand",
+        JSON.stringify({
+            ID: payload.ID,
+            Address: payload.Address,
+            Owner: payload.Owner,
+            Date: Number(payload.Date),
+            State: payload.State,
+            District: payload.District,
+            OfficeID: payload.OfficeID,
+        })
+    );
 };
 
 module.exports = txhandler;
@@ -22,7 +22,7 @@ txhandler = async (user, ID) => {
     const contract = network.getContract("land_cc");
 
     // Evaluate the specified transaction.
-    const result = await contract.evaluateTransaction("readLand", ID);
+    const result = await contract.evaluateTransaction("readLand", JSON.stringify({ ID }));
 
     return JSON.parse(result.toString());
 };
@@ -22,7 +22,10 @@ txhandler = async (user, payload) => {
     const contract = network.getContract("lawyer_cc");
 
     // Evaluate the specified transaction.
-    await contract.submitTransaction("createLawyer", payload.ID, payload.Name, payload.CitizenID);
+    await contract.submitTransaction(
+        "createLawyer",
+        JSON.stringify({ ID: payload.ID, Name: payload.Name, CitizenID: payload.CitizenID })
+    );
 };
 
 module.exports = txhandler;
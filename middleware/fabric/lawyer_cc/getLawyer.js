@@ -22,7 +22,7 @@ txhandler = async (user, ID) => {
     const contract = network.getContract("lawyer_cc");
 
     // Evaluate the specified transaction.
-    const result = await contract.evaluateTransaction("readLawyer", ID);
+    const result = await contract.evaluateTransaction("readLawyer", JSON.stringify({ ID }));
 
     return JSON.parse(result.toString());
 };
@@ -18,8 +18,19 @@ RegisterUser = async (user) => {
     const adminIdentity = gateway.getCurrentIdentity();
 
     // Register the user, enroll the user, and import the new identity into the wallet.
+    // The attributes are embedded in the certificate and checked by the chaincodes.
     const secret = await ca.register(
-        { affiliation: "org1.department1", enrollmentID: user.username, role: "client" },
+        {
+            affiliation: "org1.department1",
+            enrollmentID: user.username,
+            role: "client",
+            attrs: [
+                { name: "role", value: "blro", ecert: true },
+                { name: "profileID", value: user.profileID, ecert: true },
+                { name: "state", value: user.state, ecert: true },
+                { name: "district", value: user.district, ecert: true },
+            ],
+        },
         adminIdentity
     );
     const enrollment = await ca.enroll({ enrollmentID: user.username, enrollmentSecret: secret });
@@ -22,7 +22,10 @@ txhandler = async (user, payload) => {
     const contract = network.getContract("registryoffice_cc");
 
     // Evaluate the specified transaction.
-    await contract.submitTransaction("createRegistryOfficer", payload.ID, payload.Name, payload.CitizenID);
+    await contract.submitTransaction(
+        "createRegistryOfficer",
+        JSON.stringify({ ID: payload.ID, Name: payload.Name, CitizenID: payload.CitizenID })
+    );
 };
 
 module.exports = txhandler;
@@ -22,7 +22,7 @@ txhandler = async (user, ID) => {
     const contract = network.getContract("registryoffice_cc");
 
     // Evaluate the specified transaction.
-    const result = await contract.evaluateTransaction("readRegistryOfficer", ID);
+    const result = await contract.evaluateTransaction("readRegistryOfficer", JSON.stringify({ ID }));
 
     return JSON.parse(result.toString());
 };
@@ -22,7 +22,10 @@ txhandler = async (user, payload) => {
     const contract = network.getContract("transfer_cc");
 
     // Evaluate the specified transaction.
-    await contract.submitTransaction("approveTransferRequest", payload.ID, payload.Date);
+    await contract.submitTransaction(
+        "approveTransferRequest",
+        JSON.stringify({ ID: payload.ID, Date: Number(payload.Date) })
+    );
 };
 
 module.exports = txhandler;
@@ -24,11 +24,13 @@ txhandler = async (user, payload) => {
     // Evaluate the specified transaction.
     await contract.submitTransaction(
         "createTransferRequest",
-        payload.ID,
-        payload.To,
-        payload.LandID,
-        payload.Lawyer,
-        payload.Date
+        JSON.stringify({
+            ID: payload.ID,
+            To: payload.To,
+            LandID: payload.LandID,
+            Assignee: payload.Lawyer,
+            Date: Number(payload.Date),
+        })
     );
 };
 
@@ -22,7 +22,7 @@ txhandler = async (user, ID) => {
     const contract = network.getContract("transfer_cc");
 
     // Evaluate the specified transaction.
-    const result = await contract.evaluateTransaction("readTransferRequest", ID);
+    const result = await contract.evaluateTransaction("readTransferRequest", JSON.stringify({ ID }));
 
     return JSON.parse(result.toString());
 };
@@ -22,7 +22,10 @@ txhandler = async (user, payload) => {
     const contract = network.getContract("transfer_cc");
 
     // Evaluate the specified transaction.
-    await contract.submitTransaction("transfer2BLRO", payload.ID, payload.BLRO, payload.Date);
+    await contract.submitTransaction(
+        "transfer2BLRO",
+        JSON.stringify({ ID: payload.ID, BLRO: payload.BLRO, Date: Number(payload.Date) })
+    );
 };
 
 module.exports = txhandler;
@@ -22,7 +22,10 @@ txhandler = async (user, payload) => {
     const contract = network.getContract("transfer_cc");
 
     // Evaluate the specified transaction.
-    await contract.submitTransaction("transfer2RegistryOfficer", payload.ID, payload.RegistryOfficer, payload.Date);
+    await contract.submitTransaction(
+        "transfer2RegistryOfficer",
+        JSON.stringify({ ID: payload.ID, RegistryOfficer: payload.RegistryOfficer, Date: Number(payload.Date) })
+    );
 };
 
 module.exports = txhandler;
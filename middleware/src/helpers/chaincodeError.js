@@ -0,0 +1,53 @@
+// Chaincodes reject transactions with an envelope {code, message, details},
+// which fabric-network wraps in the message of the error it throws.
+const statuses = {
+    NOT_FOUND: 404,
+    ACCESS_DENIED: 403,
+    INVALID_ARGUMENT: 400,
+    CONFLICT: 409,
+    INVALID_STATE: 409,
+    INTERNAL: 500,
+};
+
+// Find the envelope in the message of an error thrown by fabric-network, null if there is none
+const parse = (error) => {
+    const message = (error && error.message) || "";
+    const start = message.indexOf('{"code":');
+    if (start < 0) return null;
+
+    // The envelope is the first balanced JSON object, as peers may append more to the message
+    let depth = 0;
+    let inString = false;
+    for (let i = start; i < message.length; i++) {
+        const c = message[i];
+        if (inString) {
+            if (c === "\\") i++;
+            else if (c === '"') inString = false;
+        } else if (c === '"') {
+            inString = true;
+        } else if (c === "{") {
+            depth++;
+        } else if (c === "}" && --depth === 0) {
+            try {
+                return JSON.parse(message.slice(start, i + 1));
+            } catch (e) {
+                return null;
+            }
+        }
+    }
+    return null;
+};
+
+// Send the envelope of error with the HTTP status of its code,
+// or else status and message for errors not raised by a chaincode
+const send = (res, error, status, message) => {
+    const envelope = parse(error);
+    if (envelope == null || statuses[envelope.code] == null) {
+        return res.status(status).send({ message });
+    }
+    return res.status(statuses[envelope.code]).send(envelope);
+};
+
+module.exports = send;
+module.exports.parse = parse;
+module.exports.statuses = statuses;
@@ -57,8 +57,15 @@ router.post("/api/auth/signup", async (req, res) => {
         };
 
         // Create Wallet Identity for the Username
+        // profileID and the jurisdiction are only embedded in the certificate
         const regUser = require(`../../fabric/reg_user/reg-${newUser.group}`);
-        await regUser(newUser);
+        await regUser({
+            ...newUser,
+            profileID: req.body.profileID,
+            state: req.body.state,
+            district: req.body.district,
+            officeID: req.body.officeID,
+        });
 
         // Add username & passhash to the MongoDB Auth Database
         User.create(newUser, function (err, doc) {
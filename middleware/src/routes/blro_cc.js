@@ -1,6 +1,7 @@
 const express = require("express");
 const md5 = require("md5");
 const JWTmiddleware = require("../helpers/jwtVerifyMiddleware");
+const sendChaincodeError = require("../helpers/chaincodeError");
 const BLROCC = require("../../fabric/blro_cc");
 
 const router = new express.Router();
@@ -14,7 +15,7 @@ router.get("/api/main/blro/get/:id", JWTmiddleware, async (req, res) => {
         res.status(200).send(data);
     } catch (error) {
         console.log(error);
-        res.status(404).send({ message: "BLRO NOT found!" });
+        sendChaincodeError(res, error, 404, "BLRO NOT found!");
     }
 });
 
@@ -30,7 +31,7 @@ router.post("/api/main/blro/createBLRO", JWTmiddleware, async (req, res) => {
         });
     } catch (error) {
         console.log(error);
-        res.status(500).send({ message: "Error! BLRO NOT Added!" });
+        sendChaincodeError(res, error, 500, "Error! BLRO NOT Added!");
     }
 });
 
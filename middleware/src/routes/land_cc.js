@@ -1,6 +1,7 @@
 const express = require("express");
 const md5 = require("md5");
 const JWTmiddleware = require("../helpers/jwtVerifyMiddleware");
+const sendChaincodeError = require("../helpers/chaincodeError");
 const LandCC = require("../../fabric/land_cc");
 
 const router = new express.Router();
@@ -14,7 +15,7 @@ router.get("/api/main/land/get/:id", JWTmiddleware, async (req, res) => {
         res.status(200).send(data);
     } catch (error) {
         console.log(error);
-        res.status(404).send({ message: "Land NOT found!" });
+        sendChaincodeError(res, error, 404, "Land NOT found!");
     }
 });
 
@@ -27,7 +28,7 @@ router.get("/api/main/land/queryOwner/:owner", JWTmiddleware, async (req, res) =
         res.status(200).send(data);
     } catch (error) {
         console.log(error);
-        res.status(404).send({ message: "Query Error!" });
+        sendChaincodeError(res, error, 404, "Query Error!");
     }
 });
 
@@ -43,7 +44,7 @@ router.post("/api/main/land/createLand", JWTmiddleware, async (req, res) => {
         });
     } catch (error) {
         console.log(error);
-        res.status(500).send({ message: "Error! Land NOT Added!" });
+        sendChaincodeError(res, error, 500, "Error! Land NOT Added!");
     }
 });
 
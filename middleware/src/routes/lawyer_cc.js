@@ -1,6 +1,7 @@
 const express = require("express");
 const md5 = require("md5");
 const JWTmiddleware = require("../helpers/jwtVerifyMiddleware");
+const sendChaincodeError = require("../helpers/chaincodeError");
 const LawyerCC = require("../../fabric/lawyer_cc");
 
 const router = new express.Router();
@@ -14,7 +15,7 @@ router.get("/api/main/lawyer/get/:id", JWTmiddleware, async (req, res) => {
         res.status(200).send(data);
     } catch (error) {
         console.log(error);
-        res.status(404).send({ message: "Lawyer NOT found!" });
+        sendChaincodeError(res, error, 404, "Lawyer NOT found!");
     }
 });
 
@@ -30,7 +31,7 @@ router.post("/api/main/lawyer/createLawyer", JWTmiddleware, async (req, res) =>
         });
     } catch (error) {
         console.log(error);
-        res.status(500).send({ message: "Error! Lawyer NOT Added!" });
+        sendChaincodeError(res, error, 500, "Error! Lawyer NOT Added!");
     }
 });
 
@@ -1,6 +1,7 @@
 const express = require("express");
 const md5 = require("md5");
 const JWTmiddleware = require("../helpers/jwtVerifyMiddleware");
+const sendChaincodeError = require("../helpers/chaincodeError");
 const RegistryOfficeCC = require("../../fabric/registryoffice_cc");
 
 const router = new express.Router();
@@ -14,7 +15,7 @@ router.get("/api/main/registryoffice/get/:id", JWTmiddleware, async (req, res) =
         res.status(200).send(data);
     } catch (error) {
         console.log(error);
-        res.status(404).send({ message: "RegistryOfficer NOT found!" });
+        sendChaincodeError(res, error, 404, "RegistryOfficer NOT found!");
     }
 });
 
@@ -30,7 +31,7 @@ router.post("/api/main/registryoffice/createRegistryOfficer", JWTmiddleware, asy
         });
     } catch (error) {
         console.log(error);
-        res.status(500).send({ message: "Error! RegistryOfficer NOT Added!" });
+        sendChaincodeError(res, error, 500, "Error! RegistryOfficer NOT Added!");
     }
 });
 
@@ -1,6 +1,7 @@
 const express = require("express");
 const md5 = require("md5");
 const JWTmiddleware = require("../helpers/jwtVerifyMiddleware");
+const sendChaincodeError = require("../helpers/chaincodeError");
 const TransferRequestCC = require("../../fabric/transfer_cc");
 
 const router = new express.Router();
@@ -14,7 +15,7 @@ router.get("/api/main/transfer/get/:id", JWTmiddleware, async (req, res) => {
         res.status(200).send(data);
     } catch (error) {
         console.log(error);
-        res.status(404).send({ message: "TransferRequest NOT found!" });
+        sendChaincodeError(res, error, 404, "TransferRequest NOT found!");
     }
 });
 
@@ -30,7 +31,7 @@ router.post("/api/main/transfer/createTransferRequest", JWTmiddleware, async (re
         });
     } catch (error) {
         console.log(error);
-        res.status(500).send({ message: "Error! TransferRequest NOT Added!" });
+        sendChaincodeError(res, error, 500, "Error! TransferRequest NOT Added!");
     }
 });
 
@@ -46,7 +47,7 @@ router.post("/api/main/transfer/transfer2RegistryOfficer", JWTmiddleware, async
         });
     } catch (error) {
         console.log(error);
-        res.status(500).send({ message: "Error! TransferRequest NOT Transfered!" });
+        sendChaincodeError(res, error, 500, "Error! TransferRequest NOT Transfered!");
     }
 });
 
@@ -62,7 +63,7 @@ router.post("/api/main/transfer/transfer2BLRO", JWTmiddleware, async (req, res)
         });
     } catch (error) {
         console.log(error);
-        res.status(500).send({ message: "Error! TransferRequest NOT Transfered to BLRO!" });
+        sendChaincodeError(res, error, 500, "Error! TransferRequest NOT Transfered to BLRO!");
     }
 });
 
@@ -78,7 +79,7 @@ router.post("/api/main/transfer/approveTransferRequest", JWTmiddleware, async (r
         });
     } catch (error) {
         console.log(error);
-        res.status(500).send({ message: "Error! TransferRequest NOT Approved!" });
+        sendChaincodeError(res, error, 500, "Error! TransferRequest NOT Approved!");
     }
 });
 